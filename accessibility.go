@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// auditAccessibility finds images without alt text, skipped heading levels and links without text
+func auditAccessibility(fileName string, contents []byte) []HTMLIssue {
+	var issues []HTMLIssue
+
+	report := func(line int, format string, args ...interface{}) {
+		issues = append(issues, HTMLIssue{File: fileName, Line: line, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	headingLevel := 0
+	linkLine := 0
+	linkText := false
+	line := 1
+	tokenizer := html.NewTokenizer(bytes.NewReader(contents))
+
+	for {
+		tokenType := tokenizer.Next()
+		tokenLine := line
+		line += bytes.Count(tokenizer.Raw(), []byte("\n"))
+
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		token := tokenizer.Token()
+
+		switch tokenType {
+		case html.TextToken:
+			if linkLine > 0 && strings.TrimSpace(token.Data) != "" {
+				linkText = true
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch name := token.Data; {
+			case name == "img":
+				alt, hasAlt := attributeValue(token, "alt")
+
+				if !hasAlt {
+					src, _ := attributeValue(token, "src")
+					report(tokenLine, "image %s has no alt text", src)
+				}
+
+				if linkLine > 0 && strings.TrimSpace(alt) != "" {
+					linkText = true
+				}
+			case name == "a":
+				if _, isLink := attributeValue(token, "href"); !isLink {
+					continue
+				}
+
+				label, _ := attributeValue(token, "aria-label")
+				title, _ := attributeValue(token, "title")
+				linkLine = tokenLine
+				linkText = strings.TrimSpace(label) != "" || strings.TrimSpace(title) != ""
+			case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+				level := int(name[1] - '0')
+
+				if headingLevel > 0 && level > headingLevel+1 {
+					report(tokenLine, "heading <%s> skips levels after <h%d>", name, headingLevel)
+				}
+
+				headingLevel = level
+			}
+		case html.EndTagToken:
+			if token.Data != "a" || linkLine == 0 {
+				continue
+			}
+
+			if !linkText {
+				report(linkLine, "link has no text")
+			}
+
+			linkLine = 0
+		}
+	}
+
+	return issues
+}
+
+// attributeValue returns the value of a tag attribute and whether the tag has it
+func attributeValue(token html.Token, key string) (string, bool) {
+	for _, attribute := range token.Attr {
+		if attribute.Key == key {
+			return attribute.Val, true
+		}
+	}
+
+	return "", false
+}
+
+// checkAccessibility audits all HTML pages of the destination directory, grouping problems by
+// the source file of the page, or the page itself for pages of the whole site
+func checkAccessibility(destinationDir string) map[string][]HTMLIssue {
+	sources := outputSources(destinationDir)
+	report := map[string][]HTMLIssue{}
+
+	filepath.Walk(destinationDir, func(fileName string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		ext := path.Ext(fileName)
+
+		if ext != ".html" && ext != ".htm" && ext != *destinationExt {
+			return nil
+		}
+
+		contents, readErr := ioutil.ReadFile(fileName)
+
+		if readErr != nil {
+			log.Printf("Skipping %v due to error: %v", fileName, readErr)
+			return nil
+		}
+
+		relative, _ := filepath.Rel(destinationDir, fileName)
+		source := sources[filepath.ToSlash(relative)]
+
+		if source == "" {
+			source = fileName
+		}
+
+		report[source] = append(report[source], auditAccessibility(fileName, contents)...)
+
+		if len(report[source]) == 0 {
+			delete(report, source)
+		}
+
+		return nil
+	})
+
+	return report
+}
+
+// printAccessibilityReport lists accessibility problems under each post, returning their number
+func printAccessibilityReport(report map[string][]HTMLIssue) int {
+	var sources []string
+
+	for source := range report {
+		sources = append(sources, source)
+	}
+
+	sort.Strings(sources)
+
+	count := 0
+
+	for _, source := range sources {
+		fmt.Println(source)
+
+		for _, issue := range report[source] {
+			fmt.Printf("  %s:%d: %s\n", issue.File, issue.Line, issue.Problem)
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+// ampFormatName is the output format whose pages are linked from articles as their AMP version
+const ampFormatName = "amp"
+
+// strippedElementPatterns match elements left out of print and AMP versions of articles
+var strippedElementPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script>`),
+	regexp.MustCompile(`(?is)<style\b[^>]*>.*?</style>`),
+	regexp.MustCompile(`(?is)<iframe\b[^>]*>.*?</iframe>`),
+	regexp.MustCompile(`(?is)<form\b[^>]*>.*?</form>`),
+	regexp.MustCompile(`(?is)<object\b[^>]*>.*?</object>`),
+	regexp.MustCompile(`(?is)<embed\b[^>]*>`),
+}
+
+var inlineAttributePattern = regexp.MustCompile(`(?i)\s(?:style|on[a-z]+)\s*=\s*("[^"]*"|'[^']*')`)
+var ampImagePattern = regexp.MustCompile(`(?i)<img\b([^>]*?)\s*/?>`)
+var imageSizePattern = regexp.MustCompile(`(?i)\s(?:width|height)\s*=`)
+
+// strippedContent is the content of an article without scripts, styles, embedded frames, forms
+// and event handlers, for print versions
+func strippedContent(article *post.Article) string {
+	content := article.Content
+
+	for _, pattern := range strippedElementPatterns {
+		content = pattern.ReplaceAllString(content, "")
+	}
+
+	return inlineAttributePattern.ReplaceAllString(content, "")
+}
+
+// ampContent is the stripped content of an article with images turned into amp-img elements, which
+// are sized by their width and height or laid out 16:9 when they have none
+func ampContent(article *post.Article) string {
+	return ampImagePattern.ReplaceAllStringFunc(strippedContent(article), func(image string) string {
+		attributes := ampImagePattern.FindStringSubmatch(image)[1]
+
+		if len(imageSizePattern.FindAllString(attributes, -1)) < 2 {
+			attributes += ` width="16" height="9"`
+		}
+
+		return `<amp-img` + attributes + ` layout="responsive"></amp-img>`
+	})
+}
+
+// ampLink returns the link to the AMP version of an article for its page head, when one is written
+func ampLink(formats []OutputFormat, article *post.Article) string {
+	for _, format := range formats {
+		if format.Name == ampFormatName && format.Applies(article) {
+			return `<link rel="amphtml" href="` + html.EscapeString(absURL(format.Path(article))) + `">`
+		}
+	}
+
+	return ""
+}
+
+// isDirectoryFormat tells whether a format is written as an index page of a directory under the
+// article, like /amp/, rather than a file next to it
+func isDirectoryFormat(extension string) bool {
+	return strings.HasPrefix(extension, "/")
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"macbirdie.net/blogger/post"
+)
+
+var apiAddress = flag.String("api", "", "Keep running and serve a read-only JSON content API of the published articles on host:port")
+
+// contentModel holds the published articles of the latest build for the content API. With a
+// workspace, it holds the last site built.
+var contentModel struct {
+	sync.Mutex
+	articles post.Articles
+}
+
+// ArticleContent is an article served by the content API with its rendered content
+type ArticleContent struct {
+	ArticleRecord
+	Content string `json:"content"`
+}
+
+// APITag is a tag listed by the content API
+type APITag struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+// updateContentModel keeps the published articles of a build, newest first
+func updateContentModel(articles post.Articles) {
+	var published post.Articles
+
+	for _, article := range articles {
+		if article.Draft || article.Unlisted || expired(article) || article.Password != "" {
+			continue
+		}
+
+		published = append(published, article)
+	}
+
+	contentModel.Lock()
+	contentModel.articles = published
+	contentModel.Unlock()
+}
+
+// publishedArticles returns the articles served by the content API
+func publishedArticles() post.Articles {
+	contentModel.Lock()
+	defer contentModel.Unlock()
+
+	return contentModel.articles
+}
+
+// articleRecords describes articles, optionally only those of a type
+func articleRecords(articles post.Articles, articleType string) []ArticleRecord {
+	records := []ArticleRecord{}
+
+	for _, article := range articles {
+		if articleType != "" && !strings.EqualFold(string(article.Type), articleType) {
+			continue
+		}
+
+		records = append(records, articleRecord(article))
+	}
+
+	return records
+}
+
+// apiHandler routes content API requests:
+//
+//	GET /api/posts[?type=post]   published articles, newest first
+//	GET /api/posts/<identifier>  an article with its content
+//	GET /api/tags                tags with their article counts
+//	GET /api/tags/<tag>          articles with a tag
+func apiHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	readOnly := func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("Access-Control-Allow-Origin", "*")
+
+			if request.Method != http.MethodGet && request.Method != http.MethodHead {
+				writeJSON(writer, http.StatusMethodNotAllowed, map[string]string{"error": "use GET"})
+				return
+			}
+
+			handler(writer, request)
+		}
+	}
+
+	mux.HandleFunc("/api/posts", readOnly(func(writer http.ResponseWriter, request *http.Request) {
+		writeJSON(writer, http.StatusOK, articleRecords(publishedArticles(), request.FormValue("type")))
+	}))
+
+	mux.HandleFunc("/api/posts/", readOnly(func(writer http.ResponseWriter, request *http.Request) {
+		identifier := strings.TrimPrefix(request.URL.Path, "/api/posts/")
+
+		for _, article := range publishedArticles() {
+			if article.Identifier == identifier {
+				writeJSON(writer, http.StatusOK, ArticleContent{ArticleRecord: articleRecord(article), Content: article.Content})
+				return
+			}
+		}
+
+		writeJSON(writer, http.StatusNotFound, map[string]string{"error": "no such post"})
+	}))
+
+	mux.HandleFunc("/api/tags", readOnly(func(writer http.ResponseWriter, request *http.Request) {
+		tags := []APITag{}
+
+		for _, count := range tagCloud(publishedArticles()) {
+			tags = append(tags, APITag{Name: count.Tag.Name, Path: sitePath(tagIndexName(count.Tag)), Count: count.Count})
+		}
+
+		writeJSON(writer, http.StatusOK, tags)
+	}))
+
+	mux.HandleFunc("/api/tags/", readOnly(func(writer http.ResponseWriter, request *http.Request) {
+		tag := post.MakeTag(strings.TrimPrefix(request.URL.Path, "/api/tags/"))
+		var tagged post.Articles
+
+		for _, article := range publishedArticles() {
+			if article.HasTag(tag.Name) {
+				tagged = append(tagged, article)
+			}
+		}
+
+		if len(tagged) == 0 {
+			writeJSON(writer, http.StatusNotFound, map[string]string{"error": "no such tag"})
+			return
+		}
+
+		writeJSON(writer, http.StatusOK, articleRecords(tagged, ""))
+	}))
+
+	return mux
+}
+
+// serveAPI serves the content API until the process ends
+func serveAPI() {
+	listener, err := net.Listen("tcp", *apiAddress)
+
+	if err != nil {
+		log.Fatalf("Could not serve the content API on %v: %v", *apiAddress, err)
+	}
+
+	log.Printf("Serving the content API on %v", *apiAddress)
+	signalReady()
+
+	if err := http.Serve(listener, apiHandler()); err != nil {
+		log.Fatalf("Content API stopped: %v", err)
+	}
+}
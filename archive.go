@@ -0,0 +1,179 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"time"
+
+	"macbirdie.net/blogger/post"
+)
+
+const archiveSidecarFileName = ".blogger-archive.json"
+
+const localArchiveDir = "archive"
+
+// ArchivedLink records where a snapshot of an outbound link is kept
+type ArchivedLink struct {
+	URL        string    `json:"url"`
+	ArchiveURL string    `json:"archive"`
+	Date       time.Time `json:"date"`
+}
+
+// archivedLinks are the snapshots recorded in the sidecar file, keyed by the original URL
+var archivedLinks = map[string]ArchivedLink{}
+
+// archived returns the snapshot address of a link, or an empty string when there is none
+func archived(link string) string {
+	return archivedLinks[link].ArchiveURL
+}
+
+// outboundLinks lists external links of rendered content
+func outboundLinks(content string) []string {
+	var links []string
+
+	for _, tag := range anchorTagPattern.FindAllString(content, -1) {
+		if match := hrefPattern.FindStringSubmatch(tag); match != nil && isExternalLink(match[1]) {
+			links = append(links, match[1])
+		}
+	}
+
+	return links
+}
+
+// waybackSnapshot returns the closest Wayback Machine snapshot of a link, asking the
+// Wayback Machine to save one when there is none yet
+func waybackSnapshot(link string) (string, error) {
+	var availability struct {
+		ArchivedSnapshots struct {
+			Closest struct {
+				Available bool   `json:"available"`
+				URL       string `json:"url"`
+			} `json:"closest"`
+		} `json:"archived_snapshots"`
+	}
+
+	response, err := linkClient.Get("https://archive.org/wayback/available?url=" + url.QueryEscape(link))
+
+	if err != nil {
+		return "", err
+	}
+
+	err = json.NewDecoder(response.Body).Decode(&availability)
+	response.Body.Close()
+
+	if err == nil && availability.ArchivedSnapshots.Closest.Available {
+		return availability.ArchivedSnapshots.Closest.URL, nil
+	}
+
+	response, err = linkClient.Get("https://web.archive.org/save/" + link)
+
+	if err != nil {
+		return "", err
+	}
+
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("saving %s: %s", link, response.Status)
+	}
+
+	if location := response.Header.Get("Content-Location"); location != "" {
+		return "https://web.archive.org" + location, nil
+	}
+
+	return response.Request.URL.String(), nil
+}
+
+// localSnapshot saves a copy of a linked page in the archive directory of the site. The page is
+// served from the site's own origin, so its scripts, styles and event handlers are sanitized away.
+func localSnapshot(destinationDir, link string) (string, error) {
+	page, err := fetchPage(link)
+
+	if err != nil {
+		return "", err
+	}
+
+	page = sanitizePolicies["ugc"].SanitizeBytes(page)
+
+	hash := sha256.Sum256([]byte(link))
+	snapshotPath := path.Join(localArchiveDir, hex.EncodeToString(hash[:8])+".html")
+
+	os.MkdirAll(path.Join(destinationDir, localArchiveDir), os.ModePerm)
+
+	if err := writeFile(path.Join(destinationDir, snapshotPath), page); err != nil {
+		return "", err
+	}
+
+	return sitePath(snapshotPath), nil
+}
+
+// archiveLinks snapshots outbound links of published articles that have no snapshot yet, using
+// the Wayback Machine or local copies, and records them in a sidecar file kept between runs.
+// Password-protected articles are left out.
+func archiveLinks(destinationDir string, articles post.Articles) {
+	if cached, err := ioutil.ReadFile(archiveSidecarFileName); err == nil {
+		if err := json.Unmarshal(cached, &archivedLinks); err != nil {
+			log.Printf("Ignoring link archive records: %v", err)
+		}
+	}
+
+	if *linkArchive == "" {
+		return
+	}
+
+	archivedCount := 0
+
+	for _, article := range articles {
+		// links of protected articles would reveal their content to the archive
+		if article.Draft || article.Password != "" {
+			continue
+		}
+
+		for _, link := range outboundLinks(article.Content) {
+			if _, found := archivedLinks[link]; found {
+				continue
+			}
+
+			var snapshot string
+			var err error
+
+			switch *linkArchive {
+			case "wayback":
+				snapshot, err = waybackSnapshot(link)
+			case "local":
+				snapshot, err = localSnapshot(destinationDir, link)
+			default:
+				log.Printf("Unknown link archive %q, expected wayback or local", *linkArchive)
+				return
+			}
+
+			if err != nil {
+				log.Printf("Could not archive %v: %v", link, err)
+				continue
+			}
+
+			archivedLinks[link] = ArchivedLink{URL: link, ArchiveURL: snapshot, Date: time.Now().In(post.Location)}
+			archivedCount++
+		}
+	}
+
+	if archivedCount == 0 {
+		return
+	}
+
+	log.Printf("Archived %d links", archivedCount)
+
+	if records, err := json.MarshalIndent(archivedLinks, "", "  "); err == nil {
+		if err := ioutil.WriteFile(archiveSidecarFileName, records, 0644); err != nil {
+			log.Printf("Could not store link archive records: %v", err)
+		}
+	}
+}
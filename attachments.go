@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+// attachmentPath is where an attachment is published, relative to the site root, keeping its
+// path relative to the article so attachments with the same file name don't overwrite each other
+func attachmentPath(article *post.Article, attachment post.Attachment) string {
+	return path.Join(bundleAssetsPath(article), path.Clean(attachment.Name))
+}
+
+// resolveAttachments finds attachment files next to the article source and reads their size and
+// checksum. Attachments are published with bundle assets; missing files are left out.
+func resolveAttachments(article *post.Article, articleDir string) {
+	var attachments []post.Attachment
+
+	for _, attachment := range article.Attachments {
+		if name := path.Clean(attachment.Name); path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			log.Printf("Skipping attachment %v of %v, which is outside the article directory", attachment.Name, article.Identifier)
+			continue
+		}
+
+		attachment.Source = path.Join(articleDir, attachment.Name)
+
+		file, err := os.Open(attachment.Source)
+
+		if err != nil {
+			log.Printf("Skipping attachment %v of %v: %v", attachment.Name, article.Identifier, err)
+			continue
+		}
+
+		var content io.Reader = file
+
+		// published images are stripped of their metadata
+		if scrubbed, ok, err := scrubbedImage(attachment.Source); err != nil {
+			file.Close()
+			log.Printf("Skipping attachment %v of %v: %v", attachment.Name, article.Identifier, err)
+			continue
+		} else if ok {
+			content = bytes.NewReader(scrubbed)
+		}
+
+		hash := sha256.New()
+		size, err := io.Copy(hash, content)
+		file.Close()
+
+		if err != nil {
+			log.Printf("Skipping attachment %v of %v: %v", attachment.Name, article.Identifier, err)
+			continue
+		}
+
+		attachment.Size = size
+		attachment.SHA256 = hex.EncodeToString(hash.Sum(nil))
+		attachment.URL = sitePath(attachmentPath(article, attachment))
+
+		attachments = append(attachments, attachment)
+	}
+
+	article.Attachments = attachments
+}
+
+// copyAttachments copies attachment files of an article into the destination
+func copyAttachments(destinationDir string, article *post.Article) {
+	for _, attachment := range article.Attachments {
+		destination := path.Join(destinationDir, attachmentPath(article, attachment))
+
+		os.MkdirAll(path.Dir(destination), os.ModePerm)
+
+		if err := copyFile(attachment.Source, destination); err != nil {
+			log.Printf("Could not copy attachment %v: %v", attachment.Source, err)
+		}
+	}
+}
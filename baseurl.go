@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var baseURL = flag.String("baseurl", os.Getenv("BLOGGER_BASEURL"), "Site root replacing the configured one, to build the same site for staging and production, defaults to $BLOGGER_BASEURL")
+var relativeURLs = flag.Bool("relative-urls", false, "Rewrite root-relative links in pages to links relative to each page, so the site works from any directory")
+
+// configureBaseURL replaces the configured site root with -baseurl
+func configureBaseURL() {
+	if *baseURL != "" {
+		*siteRoot = *baseURL
+	}
+}
+
+// isPageFile tells whether an output file is an HTML page
+func isPageFile(fileName string) bool {
+	ext := path.Ext(fileName)
+
+	return ext == ".html" || ext == ".htm" || ext == *destinationExt
+}
+
+// relativizeLinks rewrites links to the site root in a page to links relative to the page, when
+// -relative-urls is set. Error pages and pages outside the destination, like drafts published
+// elsewhere, are kept.
+func relativizeLinks(fileName string, content []byte) []byte {
+	if !*relativeURLs || !isPageFile(fileName) {
+		return content
+	}
+
+	outputs.Lock()
+	root := outputs.root
+	outputs.Unlock()
+
+	relative, err := filepath.Rel(root, fileName)
+
+	// error pages are served at any path
+	if err != nil || strings.HasPrefix(relative, "..") || isErrorPage(filepath.ToSlash(relative)) {
+		return content
+	}
+
+	up := strings.Repeat("../", strings.Count(filepath.ToSlash(relative), "/"))
+
+	if up == "" {
+		up = "./"
+	}
+
+	base := strings.TrimSuffix(rootPath(), "/")
+
+	return linkAttributePattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		groups := linkAttributePattern.FindSubmatch(match)
+		link := string(groups[2])
+
+		if strings.HasPrefix(link, "//") {
+			return match
+		}
+
+		var rest string
+
+		switch {
+		case link == base:
+		case strings.HasPrefix(link, base+"/"):
+			rest = strings.TrimPrefix(link, base+"/")
+		default:
+			return match
+		}
+
+		return []byte(string(groups[1]) + up + rest + string(groups[3]))
+	})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io/fs"
+	"text/template"
+)
+
+// baseTemplateFileName is the shared layout of the templates of page kinds. Each kind's
+// template, like article.html, defines the blocks it overrides; kinds without one use the
+// layout's own blocks.
+const baseTemplateFileName = "base.html"
+
+// pageKinds are the kinds of HTML pages with templates of their own in a base layout
+var pageKinds = []string{"index", "article", "tag", "term", "blogroll"}
+
+// pageTemplates are the main templates of each kind of page
+type pageTemplates map[string]*template.Template
+
+// kind returns the template of a kind of page
+func (p pageTemplates) kind(kind string) *template.Template {
+	return p[kind]
+}
+
+// hasFile tells whether a template file exists
+func hasFile(files fs.FS, name string) bool {
+	info, err := fs.Stat(files, name)
+
+	return err == nil && !info.IsDir()
+}
+
+// parsePageTemplates parses the page templates of a templates directory. A directory with a
+// base layout gets the layout with the blocks of <kind>.html for every kind, otherwise all
+// kinds are rendered with the main template. It returns nil when the directory has neither.
+func parsePageTemplates(files fs.FS, funcMap template.FuncMap) (pageTemplates, error) {
+	templates := pageTemplates{}
+
+	if !hasFile(files, baseTemplateFileName) {
+		if !hasFile(files, templateFileName) {
+			return nil, nil
+		}
+
+		mainTemplate, err := template.New(templateFileName).Funcs(funcMap).ParseFS(files, templateFileName)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, kind := range pageKinds {
+			templates[kind] = mainTemplate
+		}
+
+		return templates, nil
+	}
+
+	base, err := template.New(baseTemplateFileName).Funcs(funcMap).ParseFS(files, baseTemplateFileName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, kind := range pageKinds {
+		kindTemplate := template.Must(base.Clone())
+
+		if kindFileName := kind + ".html"; hasFile(files, kindFileName) {
+			if kindTemplate, err = kindTemplate.ParseFS(files, kindFileName); err != nil {
+				return nil, err
+			}
+		}
+
+		templates[kind] = kindTemplate
+	}
+
+	return templates, nil
+}
+
+// loadPageTemplates parses the page templates of the templates directory or the default theme,
+// failing like the main template did when there are none
+func loadPageTemplates(funcMap template.FuncMap) pageTemplates {
+	templates, err := parsePageTemplates(templateFiles(), funcMap)
+
+	if err == nil && templates == nil {
+		_, err = parseTemplate(templateFileName, funcMap)
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	return templates
+}
@@ -4,6 +4,9 @@ import (
 	"bufio"
 	"bytes"
 	"flag"
+	"fmt"
+	"image"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -15,6 +18,8 @@ import (
 	"text/template"
 	"time"
 
+	"macbirdie.net/blogger/funcs"
+	"macbirdie.net/blogger/hooks"
 	"macbirdie.net/blogger/post"
 
 	"github.com/russross/blackfriday"
@@ -28,16 +33,52 @@ var postsPath = flag.String("posts", "posts", "Posts directory, comma separated
 var templatesPath = flag.String("templates", "templates", "Templates directory")
 var destinationPath = flag.String("destination", "destination", "Destination directory")
 var siteRoot = flag.String("root", "/", "Site root path")
-var templatePrint = flag.String("print", "", "Print out a template for a snippet, blog post or a page")
+var templatePrint = flag.String("print", "", "Print out a template for a snippet, blog post, link or a page")
 var templateAuthor = flag.String("author", "", "Set a default post author")
 var listen = flag.Bool("listen", false, "Listen to changes in post directories and regenerate")
 var tagfeeds = flag.String("tagfeeds", "", "Generate RSS feeds for specified tags (comma-separated)")
+var dataPath = flag.String("data", "data", "Data directory with yaml, json and toml files exposed to templates")
+var redirectMaps = flag.String("redirects", "", "Also write alias redirects for servers: netlify, nginx (comma-separated)")
+var robots = flag.Bool("robots", false, "Generate robots.txt")
+var robotsDisallow = flag.String("robots-disallow", "/drafts/", "Paths disallowed in robots.txt, relative to site root (comma-separated)")
+var sitemapURL = flag.String("sitemap", "", "Sitemap URL referenced in robots.txt, defaults to sitemap.xml when root is an absolute URL")
+var draftsDestination = flag.String("drafts-destination", "", "Separate destination directory for drafts")
+var draftsToken = flag.String("drafts-token", "", "Hide drafts in a drafts-<token> directory, \"random\" generates and keeps a token")
+var commentsSource = flag.String("comments", "", "Comment source as dir:<path> (Staticman or JSON files) or github:<owner/repo> (issues)")
+var timezone = flag.String("timezone", "", "Time zone for dates without an offset and for dates in templates, e.g. Europe/Warsaw (default local)")
+var locale = flag.String("locale", "en", "Language of month and day names in localDate, e.g. pl or de-AT")
+var dateFormats = flag.String("date-formats", "", "Additional Go date layouts accepted in front matter, separated with |")
+var sanitize = flag.String("sanitize", "", "Sanitize HTML of articles from post directories as directory=policy, policies: strict, ugc, none (comma-separated)")
+var emojiMode = flag.String("emoji", "", "Convert :emoji: shortcodes to unicode, or to images with image:<url pattern> where %s is the emoji name")
+var mathMode = flag.String("math", "", "Protect $inline$ and $$display$$ math: client for KaTeX or MathJax delimiters, command:<command> to render at build time")
+var diagrams = flag.Bool("diagrams", false, "Render mermaid and dot code blocks as diagrams")
+var dotCommand = flag.String("dot-command", "", "Command rendering dot diagrams to SVG at build time, e.g. \"dot -Tsvg\"")
+var mermaidCommand = flag.String("mermaid-command", "", "Command rendering mermaid diagrams to SVG at build time")
+var figures = flag.Bool("figures", false, "Render images on their own line with a title as captioned figures and read width, height and align hints from image link queries")
+var externalLinks = flag.String("external-links", "", "Decorate outbound links with comma-separated options: class, noopener, noreferrer, nofollow, external, blank; also prefixes root-relative links with -root")
+var slugScheme = flag.String("slugs", "", "Article file name scheme: empty keeps source names, unicode (lowercase, hyphen-separated), ascii (transliterated, for ASCII-only URLs)")
+var headingIDs = flag.String("heading-ids", "none", "Heading identifier scheme: none, unicode, ascii (transliterated)")
+var titleFromHeading = flag.Bool("title-from-heading", false, "Take titles of articles without one from their first level one heading, removed from the content")
+var headingAnchors = flag.String("heading-anchors", "", "Text of anchor links added to headings with identifiers, none when empty")
+var lazyImages = flag.Bool("lazy-images", true, "Add lazy loading hints and dimensions read from image files to images")
+var podcastFeed = flag.String("podcast", "", "File name of a separate feed of articles with enclosures, e.g. podcast.xml")
+var linkTitles = flag.Bool("fetch-link-titles", false, "Fetch titles of pages untitled link posts point at, cached between runs")
+var responseContexts = flag.Bool("fetch-contexts", false, "Fetch titles and summaries of pages replies, likes, reposts and bookmarks point at, cached between runs")
+var taxonomies = flag.String("taxonomies", "", "Front matter keys classifying articles like tags, e.g. categories,projects (comma-separated), each with its own index pages and feeds")
+var tagNormalization = flag.String("tag-normalize", "", "Normalize tag names: ascii (transliterated, hyphen-separated), slug (hyphen-separated)")
+var tagPathPattern = flag.String("tag-path", "tag-{name}{ext}", "File of tag pages relative to the destination, {name} being the tag and {ext} the -extension, e.g. tags/{name}/index.html")
+var tagAliases = flag.String("tag-aliases", "", "Tags merged into other tags as alias=tag, e.g. golang=go (comma-separated)")
+var ignorePatterns = flag.String("ignore", "", "Glob patterns of files and directories in post directories to skip, in addition to .bloggerignore files (comma-separated)")
+var linkArchive = flag.String("archive-links", "", "Snapshot outbound links of published articles: wayback (Wayback Machine) or local (copies in the archive directory), recorded in .blogger-archive.json")
+var profile = flag.String("profile", "", "Write CPU and heap profiles of generation to <prefix>.cpu and <prefix>.heap and log time spent in each phase")
+var staging = flag.Bool("staging", false, "Generate into a staging directory next to the destination and swap it in when done")
+var configPath = flag.String("config", "", "Configuration file (toml, yaml or json) with flag names as keys, blogger.toml or blogger.yaml in the working directory by default")
+var staticPaths = flag.String("static", "", "Directories with static files copied into the destination (comma-separated)")
+var outputFormats = flag.String("formats", "", "Additional output formats as name:extension[:Type|Type] (comma-separated), rendered with format-<name>.html")
 
 const templateFileName = "template.html"
 const rssTemplateFileName = "rsstemplate.html"
 
-var postExtensions = []string{".md", ".markdown", ".txt"}
-
 func containsString(haystack []string, needle string) bool {
 	for _, hay := range haystack {
 		if hay == needle {
@@ -48,44 +89,75 @@ func containsString(haystack []string, needle string) bool {
 	return false
 }
 
-func generate() {
-
-	log.Printf("Generating blog: %s", *blogTitle)
-
-	destinationDir, destinationDirErr := os.Open(*destinationPath)
-
-	if destinationDirErr != nil {
-		log.Fatal("Destination directory could not be opened: ", destinationDirErr)
-	}
+// PostFile is a source file found in one of the post directories
+type PostFile struct {
+	Name      string
+	Extension string
+	Path      string
+	Dir       string
+}
 
-	defer destinationDir.Close()
+// dateFormatter returns a template function formatting a date with a layout, in the configured time zone
+func dateFormatter(layout string) func(args ...interface{}) string {
+	return func(args ...interface{}) string { return args[0].(*time.Time).In(post.Location).Format(layout) }
+}
 
-	funcMap := template.FuncMap{
-		"longDate":     func(args ...interface{}) string { return args[0].(*time.Time).Format("Monday, _2 January 2006, 15:04") },
-		"snippetDate":  func(args ...interface{}) string { return args[0].(*time.Time).Format("Jan _2 2006, 15:04") },
-		"shortDate":    func(args ...interface{}) string { return args[0].(*time.Time).Format("Jan _2, 2006") },
-		"atomDate":     func(args ...interface{}) string { return args[0].(*time.Time).Format("2006-01-02T15:04:05Z07:00") },
-		"Snippet":      func(args ...interface{}) bool { return args[0].(*post.Article).Type == post.Snippet },
-		"Post":         func(args ...interface{}) bool { return args[0].(*post.Article).Type == post.Post },
-		"Page":         func(args ...interface{}) bool { return args[0].(*post.Article).Type == post.Page },
-		"last":         func(index, count int) bool { return index == count-1 },
-		"tagIndexName": func(tag string) string { return "tag-" + tag + *destinationExt },
+// templateFuncs returns functions available in templates, including ones registered in the funcs package
+func templateFuncs() template.FuncMap {
+	return funcs.Map(template.FuncMap{
+		"longDate":      dateFormatter("Monday, _2 January 2006, 15:04"),
+		"snippetDate":   dateFormatter("Jan _2 2006, 15:04"),
+		"shortDate":     dateFormatter("Jan _2, 2006"),
+		"atomDate":      dateFormatter("2006-01-02T15:04:05Z07:00"),
+		"rssDate":       dateFormatter(time.RFC1123Z),
+		"Snippet":       func(args ...interface{}) bool { return args[0].(*post.Article).Type == post.Snippet },
+		"Post":          func(args ...interface{}) bool { return args[0].(*post.Article).Type == post.Post },
+		"Page":          func(args ...interface{}) bool { return args[0].(*post.Article).Type == post.Page },
+		"Link":          func(args ...interface{}) bool { return args[0].(*post.Article).Type == post.Link },
+		"permalink":     permalink,
+		"pageHead":      pageHead,
+		"last":          func(index, count int) bool { return index == count-1 },
+		"tagIndexName":  tagIndexName,
+		"termIndexName": termIndexName,
+		"termFeedName":  termFeedName,
 		"path": func(article post.Article) string {
 			return article.FullPath()
 		},
-	}
-
-	mainTemplate := template.Must(template.New("template.html").Funcs(funcMap).ParseFiles(path.Join(*templatesPath, templateFileName)))
-	mainRssTemplate := template.Must(template.New("rsstemplate.html").Funcs(funcMap).ParseFiles(path.Join(*templatesPath, rssTemplateFileName)))
-
-	now := time.Now()
-
-	type PostFile struct {
-		Name      string
-		Extension string
-		Path      string
-	}
+		"canonical": func(article post.Article) string {
+			if article.Canonical != "" {
+				return article.Canonical
+			}
+			return strings.TrimSuffix(*siteRoot, "/") + "/" + article.FullPath()
+		},
+		"absURL":       absURL,
+		"enclosureTag": enclosureTag,
+		"itunesTags":   itunesTags,
+		"relURL":       sitePath,
+		"pName":        pName,
+		"dtPublished":  dtPublished,
+		"uURL":         uURL,
+		"eContent":     eContent,
+		"responseLink": responseLink,
+		"hCite":        hCite,
+		"feedLinks":    feedLinks,
+		"cardImage":    cardImage,
+		"cardMeta":     cardMeta,
+		"stripped":     strippedContent,
+		"ampContent":   ampContent,
+		"hFeedName":    hFeedName,
+		"hEntry":       hEntry,
+		"pAuthor":      pAuthor,
+		"pCategories":  pCategories,
+		"archived":     archived,
+		"feedUpdated":  feedUpdated,
+		"gitCommit":    gitCommit,
+		"gitPath":      gitPath,
+		"sourceLink":   sourceLink,
+	})
+}
 
+// findSourceFiles walks all post directories and returns files with known post extensions
+func findSourceFiles() []PostFile {
 	sourceFiles := []PostFile{}
 
 	user, _ := user.Current()
@@ -97,9 +169,19 @@ func generate() {
 			postDir = strings.Replace(postDir, "~", homedir, 1)
 		}
 
-		walkFunc := func(filepath string, info os.FileInfo, err error) error {
+		rules := loadIgnoreRules(postDir)
+
+		walkFunc := func(sourcePath string, info os.FileInfo, err error) error {
 			if err != nil {
-				log.Fatalf("Post directory %q not found", filepath)
+				log.Fatalf("Post directory %q not found", sourcePath)
+			}
+
+			if relative, _ := filepath.Rel(postDir, sourcePath); relative != "." && rules.ignored(filepath.ToSlash(relative), info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+
+				return nil
 			}
 
 			if info.IsDir() {
@@ -109,7 +191,7 @@ func generate() {
 			filename := path.Base(info.Name())
 			ext := path.Ext(filename)
 
-			if !containsString(postExtensions, ext) {
+			if !isSourceExtension(ext) {
 				return nil
 			}
 
@@ -117,12 +199,12 @@ func generate() {
 				filename = strings.TrimSuffix(filename, ext)
 				ext = path.Ext(filename)
 
-				if !containsString(postExtensions, ext) {
+				if !isSourceExtension(ext) {
 					break
 				}
 			}
 
-			sourceFiles = append(sourceFiles, PostFile{Name: filename, Extension: ext, Path: filepath})
+			sourceFiles = append(sourceFiles, PostFile{Name: filename, Extension: ext, Path: sourcePath, Dir: postDir})
 
 			return nil
 		}
@@ -130,12 +212,13 @@ func generate() {
 		filepath.Walk(postDir, walkFunc)
 	}
 
-	var articles, indexArticles, feedArticles, snippetArticles post.Articles
+	return sourceFiles
+}
 
-	htmlFlags := 0
-	htmlFlags |= blackfriday.HTML_USE_SMARTYPANTS
-	htmlFlags |= blackfriday.HTML_SMARTYPANTS_FRACTIONS
-	htmlFlags |= blackfriday.HTML_SMARTYPANTS_LATEX_DASHES
+// markdownRenderer returns the HTML renderer used for articles, with additional blackfriday HTML flags
+func markdownRenderer(extraFlags int) blackfriday.Renderer {
+	htmlFlags := extraFlags
+	htmlFlags |= typographyFlags()
 
 	var rendererParameters blackfriday.HtmlRendererParameters
 
@@ -143,8 +226,10 @@ func generate() {
 	htmlPrefix = strings.TrimSuffix(htmlPrefix, "/")
 	rendererParameters.AbsolutePrefix = htmlPrefix
 
-	log.Println("Using prefix", htmlPrefix)
-	renderer := blackfriday.HtmlRendererWithParameters(htmlFlags, "", "", rendererParameters)
+	return &articleRenderer{Renderer: blackfriday.HtmlRendererWithParameters(htmlFlags, "", "", rendererParameters)}
+}
+
+func markdownExtensions() int {
 	extensions := 0
 	extensions |= blackfriday.EXTENSION_NO_INTRA_EMPHASIS
 	extensions |= blackfriday.EXTENSION_TABLES
@@ -155,142 +240,440 @@ func generate() {
 	extensions |= blackfriday.EXTENSION_HEADER_IDS
 	extensions |= blackfriday.EXTENSION_FOOTNOTES
 
+	return extensions
+}
+
+// readArticles parses and renders all source files, skipping the ones that can't be read
+func readArticles(sourceFiles []PostFile, shortcodes map[string]*template.Template, renderer blackfriday.Renderer) post.Articles {
+	var articles post.Articles
+
+	extensions := markdownExtensions()
+	sanitizeSources := parseSanitizeSources(*sanitize)
+	directoryDefaults := map[string]map[string]string{}
+
 	for _, sourceFile := range sourceFiles {
 
+		stopParse := phases.measure("parse")
+
 		file, fileError := os.Open(sourceFile.Path)
 
 		if fileError != nil {
-			log.Printf("Skipping %v due to error: %v", sourceFile.Path, fileError)
+			stopParse()
+			reportError(sourceError(sourceFile.Path, fileError))
 			continue
 		}
 
-		defer file.Close()
+		var reader io.Reader = file
+
+		sourceKind := sourceRenderer(path.Ext(sourceFile.Path))
 
-		article, readErr := post.ReadArticle(bufio.NewReader(file))
+		if sourceKind == "html" {
+			source, sourceErr := ioutil.ReadAll(file)
+
+			if sourceErr != nil {
+				file.Close()
+				stopParse()
+				reportError(sourceError(sourceFile.Path, sourceErr))
+				continue
+			}
+
+			reader = bytes.NewReader(htmlFrontMatter(source))
+		}
+
+		article, readErr := post.ReadArticleWithDefaults(bufio.NewReader(reader), cascadedDefaults(sourceFile, directoryDefaults))
+		file.Close()
+		stopParse()
 
 		if readErr != nil {
-			log.Printf("Skipping file %v due to parse error: %v", sourceFile.Path, readErr)
+			reportError(sourceError(sourceFile.Path, readErr))
 			continue
 		}
 
-		md := blackfriday.Markdown(article.RawContent, renderer, extensions)
+		article.Source = sourceFile.Path
+		article.Section = sectionName(sourceFile.Dir)
+		sourceName := sourceFile.Name
+
+		if bundle := bundleName(sourceFile); bundle != "" {
+			sourceName = bundle
+			article.Bundle = path.Dir(sourceFile.Path)
+		}
+
+		name, nameDate := post.SplitDatedName(sourceName)
+		article.Identifier = name
+
+		if hookErr := runHooks(hooks.AfterParse, &article, ""); hookErr != nil {
+			reportError(sourceError(sourceFile.Path, hookErr))
+			continue
+		}
+
+		if custom, ok := renderer.(*articleRenderer); ok {
+			custom.startArticle(name, path.Dir(sourceFile.Path))
+		}
+
+		if hookErr := runHooks(hooks.BeforeRender, &article, ""); hookErr != nil {
+			reportError(sourceError(sourceFile.Path, hookErr))
+			continue
+		}
+
+		stopRender := phases.measure("render")
+
+		switch sourceKind {
+		case "html":
+			article.Content = string(article.RawContent)
+		case "command":
+			content, convertErr := convertContent(path.Ext(sourceFile.Path), article.RawContent)
+
+			if convertErr != nil {
+				stopRender()
+				reportError(sourceError(sourceFile.Path, convertErr))
+				continue
+			}
+
+			article.Content = content
+		default:
+			rawContent := article.RawContent
+
+			if translate, translated := markupTranslators[sourceKind]; translated {
+				rawContent = translate(rawContent)
+			}
+
+			if *titleFromHeading && article.Title == "" {
+				article.Title, rawContent = extractTitle(rawContent)
+			}
+
+			rawContent, renderedShortcodes := expandShortcodes(rawContent, shortcodes)
+			rawContent, renderedMath := protectMath(rawContent, *mathMode)
+
+			md := blackfriday.Markdown(rawContent, renderer, extensions)
+
+			article.Content = replacePlaceholders(replacePlaceholders(string(md), renderedShortcodes), renderedMath)
+		}
+
+		addBibliography(&article, renderer, extensions)
+		resolveEnclosure(&article, path.Dir(sourceFile.Path))
 
-		article.Content = string(md)
+		article.Content = decorateLinks(article.Content, *externalLinks)
 
-		article.Filename = sourceFile.Name + *destinationExt
+		article.Content = replaceEmoji(article.Content, *emojiMode)
+
+		sanitizeArticle(&article, sourceFile.Dir, sanitizeSources)
+
+		if nameDate != nil && article.Undated {
+			article.DateModified = nameDate
+			article.Undated = false
+		}
+
+		applyGitInfo(&article)
+
+		if *uglyURLs {
+			article.Filename = articleSlug(name) + *destinationExt
+		} else {
+			article.Filename = articleSlug(name)
+		}
+
+		if article.Bundle != "" {
+			rewriteBundleLinks(&article)
+		}
+
+		resolveAttachments(&article, path.Dir(sourceFile.Path))
+		validateArticle(&article)
+
+		stopRender()
 
 		if article.DateModified == nil {
 			article.DateModified = new(time.Time)
 		}
 
-		article.Identifier = sourceFile.Name
+		resolveImage(&article)
+
+		if hookErr := runHooks(hooks.AfterRender, &article, ""); hookErr != nil {
+			reportError(sourceError(sourceFile.Path, hookErr))
+			continue
+		}
 
 		articles = append(articles, &article)
+	}
+
+	return articles
+}
+
+// buildTime is the time generated pages are dated with
+var buildTime = time.Now
+
+func generate(destination string) {
+
+	log.Printf("Generating blog: %s", *blogTitle)
+
+	destinationDir, destinationDirErr := os.Open(destination)
+
+	if destinationDirErr != nil {
+		abortBuild(fmt.Errorf("Destination directory could not be opened: %v", destinationDirErr))
+	}
+
+	defer destinationDir.Close()
+
+	// outputs written by deferred steps, like the manifest, are dated too
+	var pinnedTime *time.Time
+
+	defer func() {
+		if pinnedTime != nil {
+			fixModificationTimes(destination, *pinnedTime)
+		}
+	}()
+
+	startManifest(destination)
+	defer finishManifest()
+	resetMediaCopies()
+
+	resetBuildErrors()
+	defer summarizeBuildErrors()
+
+	funcMap := templateFuncs()
+
+	pages := loadPageTemplates(funcMap)
+	mainRssTemplate := template.Must(parseTemplate(rssTemplateFileName, funcMap))
+	snippetTemplate := loadSnippetTemplate(funcMap)
+	sectionSets := loadSectionTemplates(funcMap, pages, mainRssTemplate)
+	shortcodes := loadShortcodes(funcMap)
+	data := loadData()
+	formats := parseOutputFormats(funcMap)
+
+	now := buildTime().In(post.Location)
+
+	log.Println("Using prefix", strings.TrimSuffix(*siteRoot, "/"))
+
+	copyStatic(destinationDir.Name())
+
+	stopWalk := phases.measure("walk")
+	sourceFiles := findSourceFiles()
+	stopWalk()
+
+	articles := checkFrontMatter(readArticles(sourceFiles, shortcodes, markdownRenderer(0)))
+
+	if *reproducible {
+		pinned, restoreClock := pinBuildTime(articles)
+		defer restoreClock()
+
+		now = pinned
+		pinnedTime = &pinned
+	}
+
+	articles = checkCollisions(destinationDir.Name(), articles, formats)
+	stopFetch := phases.measure("fetch")
+	fetchLinkTitles(articles)
+	fetchResponseContexts(articles)
+	archiveLinks(destinationDir.Name(), articles)
+	attachComments(articles, *commentsSource)
+	stopFetch()
+
+	var indexArticles, feedArticles, snippetArticles, listedArticles post.Articles
+	sectionIndexes, sectionFeeds := map[string]post.Articles{}, map[string]post.Articles{}
+
+	for _, article := range articles {
+
+		if article.Password != "" {
+			if encryptErr := encryptContent(article); encryptErr != nil {
+				log.Printf("Could not encrypt %v, leaving its content out: %v", article.Identifier, encryptErr)
+				article.Content = ""
+			}
+		}
+
+		addExpiredNotice(article)
 
 		if article.Type == post.Page {
 			continue
 		}
 
-		if article.Draft {
+		if article.Draft || article.Unlisted || expired(article) {
 			continue
 		}
 
-		if article.Type == post.Post {
-			feedArticles = append(feedArticles, &article)
+		listedArticles = append(listedArticles, article)
+
+		if article.Section != "" {
+			if (article.Type == post.Post || article.Type == post.Link) && article.Password == "" {
+				sectionFeeds[article.Section] = append(sectionFeeds[article.Section], article)
+			}
+
+			sectionIndexes[article.Section] = append(sectionIndexes[article.Section], article)
+			continue
 		}
 
-		if article.Type == post.Snippet {
-			snippetArticles = append(snippetArticles, &article)
+		// feed readers can't run the decryption script
+		if (article.Type == post.Post || article.Type == post.Link) && article.Password == "" {
+			feedArticles = append(feedArticles, article)
 		}
 
-		indexArticles = append(indexArticles, &article)
-	}
+		if article.Type == post.Snippet && article.Password == "" {
+			snippetArticles = append(snippetArticles, article)
+		}
 
-	tags := map[post.Tag]bool{}
+		indexArticles = append(indexArticles, article)
+	}
 
 	sort.Sort(articles)
 	sort.Sort(indexArticles)
 	sort.Sort(feedArticles)
+	sortByFeedUpdate(feedArticles)
 	sort.Sort(snippetArticles)
+	sort.Sort(listedArticles)
 
-	indexBuffer := bytes.NewBufferString("")
-	rssIndexBuffer := bytes.NewBufferString("")
-	snippetrssIndexBuffer := bytes.NewBufferString("")
+	// tags of listed articles get pages, keyed by file name, so differently capitalized spellings
+	// share a page
+	tags := map[string]post.Tag{}
 
-	mainTemplate.Execute(indexBuffer, map[string]interface{}{
-		"Title":       blogTitle,
-		"Home":        true,
-		"Root":        *siteRoot,
-		"Articles":    indexArticles,
-		"CreatedTime": now,
-	})
+	for _, article := range listedArticles {
+		for _, tag := range article.Tags {
+			if _, found := tags[tag.FileName()]; !found {
+				tags[tag.FileName()] = tag
+			}
+		}
+	}
 
-	mainRssTemplate.Execute(rssIndexBuffer, map[string]interface{}{
-		"Title":       blogTitle,
-		"Home":        true,
-		"Root":        *siteRoot,
-		"File":        "index.xml",
-		"Articles":    feedArticles,
-		"CreatedTime": &now,
-	})
+	updateContentModel(articles)
 
-	mainRssTemplate.Execute(snippetrssIndexBuffer, map[string]interface{}{
-		"Title":       blogTitle,
-		"Home":        true,
-		"Root":        *siteRoot,
-		"File":        "snippets.xml",
-		"Articles":    snippetArticles,
-		"CreatedTime": &now,
-	})
+	cloud := tagCloud(listedArticles)
+
+	stopTemplate := phases.measure("template")
+
+	indexContext := pageContext(data, now)
+	indexContext.Home = true
+	indexContext.Articles = indexArticles.Pinned()
+	indexContext.Tags = cloud
+	executeToFile(path.Join(destinationDir.Name(), "index.html"), pages.kind("index"), indexContext)
+
+	feedContext := pageContext(data, now)
+	feedContext.Home = true
+	feedContext.File = "index.xml"
+	feedContext.Articles = feedArticles
+	executeToFile(path.Join(destinationDir.Name(), "index.xml"), mainRssTemplate, feedContext)
+
+	snippetsContext := pageContext(data, now)
+	snippetsContext.Home = true
+	snippetsContext.File = "snippets.xml"
+	snippetsContext.Articles = snippetArticles
+	executeToFile(path.Join(destinationDir.Name(), "snippets.xml"), mainRssTemplate, snippetsContext)
+
+	writeSectionIndexes(destinationDir.Name(), sectionSets, sectionIndexes, sectionFeeds, pageContext(data, now), cloud)
+	writeMarkdownIndex(destinationDir.Name(), listedArticles)
+	writeLLMSText(destinationDir.Name(), articles)
+
+	stopTemplate()
+
+	var cardBackground image.Image
+
+	if *cards {
+		cardBackground = loadCardBackground()
+	}
 
 	for _, article := range articles {
+		if hookErr := runHooks(hooks.BeforeWrite, article, destinationDir.Name()); hookErr != nil {
+			reportError(sourceError(article.Source, hookErr))
+			continue
+		}
+
+		setOutputSource(article.Source)
+
+		if target := expiredRedirect(article); target != "" {
+			writeExpiredRedirect(destinationDir.Name(), article, target)
+			continue
+		}
 
-		destFileBuffer := bytes.NewBufferString("")
+		articleContext := pageContext(data, now)
+		articleContext.Article = article
+		articleContext.Title = article.Title + " – " + *blogTitle
 
-		mainTemplate.Execute(destFileBuffer, map[string]interface{}{
-			"BlogTitle": blogTitle,
-			"Article":   article,
-			"Title":     string(article.Title + " – " + *blogTitle),
-			"Home":      false,
-			"Root":      *siteRoot,
-		})
+		articleDestinationDir := articleDestination(destinationDir.Name(), article)
+		destinationFileName := path.Join(articleDestinationDir, article.OutputPath())
 
-		for _, tag := range article.Tags {
-			tags[tag] = true
+		os.MkdirAll(path.Dir(destinationFileName), os.ModePerm)
+
+		pageTemplate := pages.kind("article")
+
+		if article.Type == post.Snippet && snippetTemplate != nil {
+			pageTemplate = snippetTemplate
+		}
+
+		if sectionSet, found := sectionSets[article.Section]; found {
+			pageTemplate = sectionSet.pages.kind("article")
 		}
 
-		destinationFileName := path.Join(destinationDir.Name(), article.FullPath())
+		stopTemplate := phases.measure("template")
+
+		amphtml := ampLink(formats, article)
 
-		os.MkdirAll(path.Join(destinationDir.Name(), article.BasePath()), os.ModePerm)
+		if article.Draft || amphtml != "" {
+			// drafts are buffered to mark them noindex, articles with an AMP version to link it
+			destFileBuffer := bytes.NewBufferString("")
 
-		writeErr := ioutil.WriteFile(destinationFileName, destFileBuffer.Bytes(), os.ModePerm)
+			if executeErr := pageTemplate.Execute(destFileBuffer, articleContext); executeErr != nil {
+				log.Printf("Could not render %v due to error: %v", article.Identifier, executeErr)
+			} else {
+				page := destFileBuffer.Bytes()
 
-		if writeErr != nil {
-			log.Printf("Could not write file %v due to error: %v", destinationFileName, writeErr)
+				if article.Draft {
+					page = injectNoindex(page)
+				}
+
+				if amphtml != "" {
+					page = injectIntoHead(page, []byte(amphtml))
+				}
+
+				if writeErr := writeFile(destinationFileName, page); writeErr != nil {
+					log.Printf("Could not write file %v due to error: %v", destinationFileName, writeErr)
+				}
+			}
+		} else {
+			executeToFile(destinationFileName, pageTemplate, articleContext)
 		}
+
+		stopTemplate()
+
+		stopWrite := phases.measure("write")
+		writeOutputFormats(formats, articleDestinationDir, article, articleContext)
+		copyBundleAssets(articleDestinationDir, article)
+		copyAttachments(articleDestinationDir, article)
+		writeCard(articleDestinationDir, cardBackground, article)
+		writeMarkdownSource(articleDestinationDir, article)
+		stopWrite()
 	}
 
-	indexFileName := path.Join(destinationDir.Name(), "index.html")
-	rssIndexFileName := path.Join(destinationDir.Name(), "index.xml")
-	snippetIndexFileName := path.Join(destinationDir.Name(), "snippets.xml")
+	setOutputSource("")
+
+	if *podcastFeed != "" {
+		podcastContext := pageContext(data, now)
+		podcastContext.Home = true
+		writePodcastFeed(destinationDir.Name(), funcMap, mainRssTemplate, feedArticles, podcastContext)
+	}
 
-	ioutil.WriteFile(indexFileName, indexBuffer.Bytes(), os.ModePerm)
-	ioutil.WriteFile(rssIndexFileName, rssIndexBuffer.Bytes(), os.ModePerm)
-	ioutil.WriteFile(snippetIndexFileName, snippetrssIndexBuffer.Bytes(), os.ModePerm)
+	redirects := articleRedirects(articles)
+	writeRedirectStubs(destinationDir.Name(), redirects)
+	writeRedirectMaps(destinationDir.Name(), redirects, *redirectMaps)
 
-	tagFeedsEnabled := map[string]bool{}
+	if *robots {
+		writeRobots(destinationDir.Name())
+	}
 
-	for _, tagEnabled := range strings.Split(*tagfeeds, ",") {
-		tagFeedsEnabled[tagEnabled] = true
+	if *sitemapURL == "" {
+		writeSitemap(destinationDir.Name(), articles)
 	}
 
+	writeHumans(destinationDir.Name(), funcMap, articles, data, now)
+	writeBlogroll(destinationDir.Name(), funcMap, pages.kind("blogroll"), pageContext(data, now))
+	writeErrorPages(destinationDir.Name(), funcMap, pageContext(data, now))
+
+	stopTags := phases.measure("tags")
+	defer stopTags()
+
+	for _, taxonomy := range post.Taxonomies {
+		writeTaxonomyPages(destinationDir.Name(), taxonomy, pages.kind("term"), mainRssTemplate, listedArticles, data, now)
+	}
 
-	for tag := range tags {
+	for _, tag := range tags {
 
-		tagIndexBuffer := bytes.NewBufferString("")
 		var tagArticles post.Articles
 
-		for _, article := range indexArticles {
+		for _, article := range listedArticles {
 
 			if !article.HasTag(tag.Name) {
 				continue
@@ -299,33 +682,70 @@ func generate() {
 			tagArticles = append(tagArticles, article)
 		}
 
-		mainTemplate.Execute(tagIndexBuffer, map[string]interface{}{
-			"Articles": tagArticles,
-			"Title":    "Tag: " + tag.Name + " – " + *blogTitle,
-			"Home":     false,
-			"Root":     *siteRoot,
-		})
+		pageTag := tag
+
+		tagContext := pageContext(data, now)
+		tagContext.Title = "Tag: " + tag.Name + " – " + *blogTitle
+		tagContext.Tag = &pageTag
+		tagContext.Tags = cloud
+		writeTagPages(destinationDir.Name(), pages.kind("tag"), tag, tagArticles, tagContext)
+
+		if tagFeedEnabled(tag) {
+			tagFeedContext := pageContext(data, now)
+			tagFeedContext.Home = true
+			tagFeedContext.File = tagFeedName(tag)
+			tagFeedContext.Articles = tagArticles
+			tagFeedContext.Tag = &pageTag
+			executeToFile(path.Join(destinationDir.Name(), tagFeedContext.File), mainRssTemplate, tagFeedContext)
+		}
+	}
+}
+
+// watchKind tells which kind of input a changed file is, to rebuild only what depends on it, and
+// which workspace sites use it. Other files in the directory of the configuration file are of no
+// kind.
+func watchKind(fileName string, contentDirs, staticRoots map[string][]int) (string, []int) {
+	cleanName := filepath.Clean(fileName)
+
+	if config := configFileName(); config != "" && cleanName == filepath.Clean(config) {
+		return "config", nil
+	}
+
+	var staticSites []int
+
+	for dir, sites := range staticRoots {
+		if cleanName == dir || strings.HasPrefix(cleanName, dir+string(filepath.Separator)) {
+			staticSites = addSite(staticSites, sites...)
+		}
+	}
+
+	if len(staticSites) > 0 {
+		sort.Ints(staticSites)
+		return "static", staticSites
+	}
 
+	if sites, found := contentDirs[filepath.Dir(cleanName)]; found {
+		return "content", sites
+	}
 
-		if tagFeedsEnabled[tag.OriginalName] {
-			tagFeedBuffer := bytes.NewBufferString("")
+	return "", nil
+}
 
-			mainRssTemplate.Execute(tagFeedBuffer, map[string]interface{}{
-				"Title":       blogTitle,
-				"Home":        true,
-				"Root":        *siteRoot,
-				"File":        "index-tag-" + tag.FileName() + ".xml",
-				"Articles":    tagArticles,
-				"CreatedTime": &now,
-			})
+// addSite adds workspace sites to a list, each once
+func addSite(sites []int, added ...int) []int {
+	for _, site := range added {
+		found := false
 
-			tagFeedFileName := path.Join(destinationDir.Name(), "index-tag-"+tag.FileName()+".xml")
-			ioutil.WriteFile(tagFeedFileName, tagFeedBuffer.Bytes(), os.ModePerm)
+		for _, listed := range sites {
+			found = found || listed == site
 		}
 
-		tagIndexFileName := path.Join(destinationDir.Name(), "tag-"+tag.FileName()+*destinationExt)
-		ioutil.WriteFile(tagIndexFileName, tagIndexBuffer.Bytes(), os.ModePerm)
+		if !found {
+			sites = append(sites, site)
+		}
 	}
+
+	return sites
 }
 
 func watch() {
@@ -335,14 +755,49 @@ func watch() {
 	}
 	defer watcher.Close()
 
+	// pages written from now on are compared with what they replace
+	comparePageChanges()
+
 	watcherDone := make(chan bool)
+	contentDirs := map[string][]int{}
+	staticRoots := map[string][]int{}
+
+	// selects the site a change belongs to, unless there is a single one
+	switchSite := func(site int) {
+		if len(workspaceSites) > 0 {
+			selectSite(site)
+		}
+	}
+
 	go func() {
 		for {
 			select {
 			case event := <-watcher.Events:
 				if (event.Op&fsnotify.Write == fsnotify.Write) || (event.Op&fsnotify.Create == fsnotify.Create) {
+					kind, sites := watchKind(event.Name, contentDirs, staticRoots)
+
+					if kind == "" {
+						continue
+					}
+
 					log.Println("Modified file: ", event.Name)
-					generate()
+
+					lockedBuild(func() {
+						switch kind {
+						case "config":
+							reloadAndRebuild()
+						case "static":
+							for _, site := range sites {
+								switchSite(site)
+								copyStatic(*destinationPath)
+							}
+						case "content":
+							for _, site := range sites {
+								switchSite(site)
+								build()
+							}
+						}
+					})
 				}
 			case err := <-watcher.Errors:
 				log.Println("Got error:", err)
@@ -355,12 +810,9 @@ func watch() {
 	user, _ := user.Current()
 	homedir := user.HomeDir
 
-	for _, postDir := range strings.Split(*postsPath, ",") {
-
-		if postDir[:2] == "~/" {
-			postDir = strings.Replace(postDir, "~", homedir, 1)
-		}
+	site := 0
 
+	addTree := func(root string) {
 		walkFunc := func(filepath string, info os.FileInfo, err error) error {
 			if err != nil {
 				return nil
@@ -371,20 +823,46 @@ func watch() {
 			}
 
 			watchedDirs = append(watchedDirs, filepath)
+			contentDirs[path.Clean(filepath)] = addSite(contentDirs[path.Clean(filepath)], site)
 
 			return nil
 		}
 
-		filepath.Walk(postDir, walkFunc)
+		filepath.Walk(root, walkFunc)
 	}
 
-	watchedDirs = append(watchedDirs, *templatesPath)
+	forEachSite(func() {
+		for _, postDir := range strings.Split(*postsPath, ",") {
+
+			if postDir[:2] == "~/" {
+				postDir = strings.Replace(postDir, "~", homedir, 1)
+			}
+
+			addTree(postDir)
+		}
+
+		addTree(*templatesPath)
+		addTree(*dataPath)
+
+		for _, dir := range staticDirs() {
+			addTree(dir)
+			staticRoots[filepath.Clean(dir)] = addSite(staticRoots[filepath.Clean(dir)], site)
+		}
+
+		site++
+	})
+
+	// editors often replace files, so the configuration is watched through its directory
+	if config := configFileName(); config != "" {
+		watchedDirs = append(watchedDirs, filepath.Dir(config))
+	}
 
 	for _, watchedDir := range watchedDirs {
 		watcher.Add(watchedDir)
 	}
 
-	log.Printf("Listening to changes in directories: %s…", strings.Join(watchedDirs, ", "))
+	log.Printf("Listening to changes in: %s…", strings.Join(watchedDirs, ", "))
+	signalReady()
 
 	<-watcherDone
 }
@@ -392,9 +870,16 @@ func watch() {
 func main() {
 	flag.Parse()
 
+	if err := loadConfig(); err != nil {
+		log.Fatalf("Could not read configuration: %v", err)
+	}
+
+	loadPlugins()
+	configure()
+
 	if *templatePrint != "" {
 		var article post.Article
-		now := time.Now().Add(15 * time.Minute)
+		now := time.Now().In(post.Location).Add(15 * time.Minute)
 		article.DateModified = &now
 
 		article.Draft = true
@@ -415,8 +900,12 @@ func main() {
 		case "snippet":
 			article.Type = post.Snippet
 			break
+		case "link":
+			article.Type = post.Link
+			article.Link = "https://example.com/"
+			break
 		default:
-			log.Fatal("post, snippet and page are the only allowed parameters for -print")
+			log.Fatal("post, snippet, link and page are the only allowed parameters for -print")
 		}
 
 		article.Print()
@@ -424,9 +913,65 @@ func main() {
 		return
 	}
 
-	generate()
+	switch flag.Arg(0) {
+	case "init":
+		initCommand(flag.Args()[1:])
+		return
+	case "version":
+		versionCommand(flag.Args()[1:])
+		return
+	case "manage":
+		manage(flag.Args()[1:])
+		return
+	case "export":
+		export(flag.Args()[1:])
+		return
+	case "import":
+		importCommand(flag.Args()[1:])
+		return
+	case "check":
+		check(flag.Args()[1:])
+		return
+	case "stats":
+		stats(flag.Args()[1:])
+		return
+	case "lint":
+		lint(flag.Args()[1:])
+		return
+	case "microformats":
+		validateMicroformats(flag.Args()[1:])
+		return
+	case "verify":
+		configureDrafts()
+		verify(flag.Args()[1:])
+		return
+	}
+
+	configureDrafts()
+
+	if *profile != "" {
+		stopProfiling := startProfiling(*profile)
+		lockedBuild(func() { forEachSite(build) })
+		stopProfiling()
+	} else {
+		lockedBuild(func() { forEachSite(build) })
+	}
+
+	if *controlAddress != "" || *apiAddress != "" || *listen {
+		handleSignals()
+	}
+
+	if *apiAddress != "" {
+		go serveAPI()
+	}
+
+	if *controlAddress != "" {
+		go serveControl()
+	}
 
 	if *listen {
 		watch()
+	} else if *controlAddress != "" || *apiAddress != "" {
+		select {}
 	}
 }
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+const blogrollTemplateFileName = "blogrolltemplate.html"
+const feedTitlesCacheFileName = ".blogger-feed-titles.json"
+
+var blogrollData = flag.String("blogroll", "blogroll", "Data file listing blogs, written to blogroll.html and blogroll.opml, empty to skip")
+
+// BlogrollEntry is a blog listed in the blogroll. Feeds without a title get the title of the feed,
+// HTML-escaped as it comes from a third party.
+type BlogrollEntry struct {
+	Title    string
+	Feed     string
+	URL      string
+	Category string
+	// feedTitle is the fetched title of the feed, as plain text
+	feedTitle string
+}
+
+// fetchFeedTitle reads the title of an RSS, RDF or Atom feed
+func fetchFeedTitle(link string) (string, error) {
+	page, err := fetchPage(link)
+
+	if err != nil {
+		return "", err
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(page))
+	decoder.Strict = false
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	var parents []string
+
+	for {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return "", fmt.Errorf("%s has no feed title", link)
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			parent := ""
+
+			if len(parents) > 0 {
+				parent = parents[len(parents)-1]
+			}
+
+			if element.Name.Local == "title" && (parent == "channel" || parent == "feed") {
+				var title string
+
+				if err := decoder.DecodeElement(&title, &element); err != nil {
+					return "", err
+				}
+
+				if title = strings.Join(strings.Fields(title), " "); title == "" {
+					return "", fmt.Errorf("%s has an empty feed title", link)
+				}
+
+				return title, nil
+			}
+
+			parents = append(parents, element.Name.Local)
+		case xml.EndElement:
+			if len(parents) > 0 {
+				parents = parents[:len(parents)-1]
+			}
+		}
+	}
+}
+
+// blogrollEntries reads the blogroll from a data file holding a list of blogs, or a table with
+// a blogs list, each with a feed and optionally a title, url and category
+func blogrollEntries(data map[string]interface{}) []BlogrollEntry {
+	value := data[*blogrollData]
+
+	if table, ok := value.(map[string]interface{}); ok {
+		value = table["blogs"]
+	}
+
+	list, _ := value.([]interface{})
+
+	if maps, ok := value.([]map[string]interface{}); ok {
+		for _, item := range maps {
+			list = append(list, item)
+		}
+	}
+
+	var entries []BlogrollEntry
+
+	for _, item := range list {
+		fields, ok := item.(map[string]interface{})
+
+		if !ok {
+			continue
+		}
+
+		text := func(key string) string {
+			if value, found := fields[key]; found {
+				return fmt.Sprint(value)
+			}
+			return ""
+		}
+
+		entry := BlogrollEntry{Title: text("title"), Feed: text("feed"), URL: text("url"), Category: text("category")}
+
+		if entry.Feed == "" {
+			log.Printf("Skipping blogroll entry without a feed: %v", fields)
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// fetchFeedTitles gives untitled blogroll entries the title of their feed, falling back to its
+// address. Titles are kept in a cache file in the working directory.
+func fetchFeedTitles(entries []BlogrollEntry) {
+	titles := map[string]string{}
+
+	if cached, err := ioutil.ReadFile(feedTitlesCacheFileName); err == nil {
+		if err := json.Unmarshal(cached, &titles); err != nil {
+			log.Printf("Ignoring feed title cache: %v", err)
+		}
+	}
+
+	fetched := false
+
+	for index := range entries {
+		entry := &entries[index]
+
+		if entry.Title != "" {
+			continue
+		}
+
+		title, found := titles[entry.Feed]
+
+		if !found {
+			var err error
+
+			if title, err = fetchFeedTitle(entry.Feed); err != nil {
+				log.Printf("Could not fetch title of %v: %v", entry.Feed, err)
+				title = entry.Feed
+			} else {
+				titles[entry.Feed] = title
+				fetched = true
+			}
+		}
+
+		entry.feedTitle = title
+		entry.Title = html.EscapeString(title)
+	}
+
+	if !fetched {
+		return
+	}
+
+	if cache, err := json.MarshalIndent(titles, "", "  "); err == nil {
+		if err := ioutil.WriteFile(feedTitlesCacheFileName, cache, 0644); err != nil {
+			log.Printf("Could not store feed title cache: %v", err)
+		}
+	}
+}
+
+// writeOPML lists the blogroll feeds for feed readers
+func writeOPML(destinationDir string, entries []BlogrollEntry, now time.Time) {
+	var buffer bytes.Buffer
+
+	fmt.Fprintln(&buffer, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(&buffer, `<opml version="2.0">`)
+	fmt.Fprintf(&buffer, "<head><title>%s</title><dateCreated>%s</dateCreated></head>\n", xmlEscape(*blogTitle+" blogroll"), now.Format(time.RFC1123Z))
+	fmt.Fprintln(&buffer, `<body>`)
+
+	for _, entry := range entries {
+		title := entry.Title
+
+		if entry.feedTitle != "" {
+			title = entry.feedTitle
+		}
+
+		fmt.Fprintf(&buffer, `<outline type="rss" text="%s" title="%s" xmlUrl="%s"`, xmlEscape(title), xmlEscape(title), xmlEscape(entry.Feed))
+
+		if entry.URL != "" {
+			fmt.Fprintf(&buffer, ` htmlUrl="%s"`, xmlEscape(entry.URL))
+		}
+
+		if entry.Category != "" {
+			fmt.Fprintf(&buffer, ` category="%s"`, xmlEscape(entry.Category))
+		}
+
+		fmt.Fprintln(&buffer, `/>`)
+	}
+
+	fmt.Fprintln(&buffer, `</body>`)
+	fmt.Fprintln(&buffer, `</opml>`)
+
+	opmlFileName := path.Join(destinationDir, "blogroll.opml")
+
+	if writeErr := writeFile(opmlFileName, buffer.Bytes()); writeErr != nil {
+		log.Printf("Could not write file %v due to error: %v", opmlFileName, writeErr)
+	}
+}
+
+// writeBlogroll writes the blogroll page, with the blogroll template when there is one and the
+// main template otherwise, and its OPML file
+func writeBlogroll(destinationDir string, funcMap template.FuncMap, mainTemplate *template.Template, context PageContext) {
+	if *blogrollData == "" {
+		return
+	}
+
+	entries := blogrollEntries(context.Data)
+
+	if len(entries) == 0 {
+		return
+	}
+
+	fetchFeedTitles(entries)
+
+	pageTemplate := mainTemplate
+	fileName := path.Join(*templatesPath, blogrollTemplateFileName)
+
+	if _, err := os.Stat(fileName); err == nil {
+		blogrollTemplate, err := template.New(blogrollTemplateFileName).Funcs(funcMap).ParseFiles(fileName)
+
+		if err != nil {
+			log.Printf("Could not parse blogroll template, using the main template: %v", err)
+		} else {
+			pageTemplate = blogrollTemplate
+		}
+	}
+
+	context.Title = "Blogroll – " + *blogTitle
+	context.Blogroll = entries
+	executeToFile(path.Join(destinationDir, "blogroll.html"), pageTemplate, context)
+
+	writeOPML(destinationDir, entries, *context.CreatedTime)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+const bundleIndexName = "index"
+
+var linkAttributePattern = regexp.MustCompile(`(\s(?:src|href)=")([^"]+)(")`)
+
+// bundleName returns the name of a bundle-style post, kept in a directory of its own as
+// <name>/index.md, or an empty string for other post files
+func bundleName(sourceFile PostFile) string {
+	dir := path.Dir(sourceFile.Path)
+
+	if sourceFile.Name != bundleIndexName || path.Clean(dir) == path.Clean(sourceFile.Dir) {
+		return ""
+	}
+
+	return path.Base(dir)
+}
+
+// bundleAssetsPath is where assets of a bundle are published, relative to the site root
+func bundleAssetsPath(article *post.Article) string {
+	return path.Join(article.BasePath(), articleStem(article)+"-assets")
+}
+
+// isBundleAsset tells if a file in a bundle directory is published as an asset rather than read as a post
+func isBundleAsset(name string) bool {
+	return !strings.HasPrefix(path.Base(name), ".") && !isSourceExtension(path.Ext(name))
+}
+
+// rewriteBundleLinks points relative links to files of a bundle at their published location
+func rewriteBundleLinks(article *post.Article) {
+	article.Content = linkAttributePattern.ReplaceAllStringFunc(article.Content, func(match string) string {
+		groups := linkAttributePattern.FindStringSubmatch(match)
+		link := groups[2]
+
+		if strings.Contains(link, ":") || strings.HasPrefix(link, "/") || strings.HasPrefix(link, "#") {
+			return match
+		}
+
+		fileName := strings.SplitN(strings.SplitN(link, "#", 2)[0], "?", 2)[0]
+
+		if info, err := os.Stat(path.Join(article.Bundle, fileName)); err != nil || info.IsDir() || !isBundleAsset(fileName) {
+			return match
+		}
+
+		return groups[1] + sitePath(path.Join(bundleAssetsPath(article), link)) + groups[3]
+	})
+}
+
+// copyFile copies a file into the destination atomically, stripping image metadata with
+// -strip-exif. With -dedupe-media, files identical to one copied before in the build are hard
+// linked to it instead.
+func copyFile(source, destination string) error {
+	hash := ""
+
+	if *dedupeMedia {
+		if contentHash, err := fileHash(source); err == nil {
+			if linkDuplicate(source, destination, contentHash) {
+				return nil
+			}
+
+			hash = contentHash
+		}
+	}
+
+	var input io.Reader
+
+	if scrubbed, ok, err := scrubbedImage(source); err != nil {
+		return err
+	} else if ok {
+		input = bytes.NewReader(scrubbed)
+	} else {
+		file, err := os.Open(source)
+
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+		input = file
+	}
+
+	output, err := createAtomicFile(destination)
+
+	if err != nil {
+		return err
+	}
+
+	output.source = source
+
+	if _, err := io.Copy(output, input); err != nil {
+		output.Abort()
+		return err
+	}
+
+	if err := output.Commit(); err != nil {
+		return err
+	}
+
+	if hash != "" {
+		rememberCopy(destination, hash, hex.EncodeToString(output.hash.Sum(nil)))
+	}
+
+	return nil
+}
+
+// copyBundleAssets copies files kept next to a bundle-style post into the destination
+func copyBundleAssets(destinationDir string, article *post.Article) {
+	if article.Bundle == "" {
+		return
+	}
+
+	assetsDir := path.Join(destinationDir, bundleAssetsPath(article))
+
+	filepath.Walk(article.Bundle, func(sourcePath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !isBundleAsset(sourcePath) {
+			return nil
+		}
+
+		relative, _ := filepath.Rel(article.Bundle, sourcePath)
+		destination := path.Join(assetsDir, filepath.ToSlash(relative))
+
+		os.MkdirAll(path.Dir(destination), os.ModePerm)
+
+		if err := copyFile(sourcePath, destination); err != nil {
+			log.Printf("Could not copy %v: %v", sourcePath, err)
+		}
+
+		return nil
+	})
+}
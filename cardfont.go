@@ -0,0 +1,117 @@
+package main
+
+// cardFontHeight is the height of glyph bitmaps of the card font
+const cardFontHeight = 33
+
+// cardFontAscent is the distance from the top of a glyph bitmap to the baseline
+const cardFontAscent = 26
+
+// cardGlyph is a glyph of the card font: the distance to the next glyph, the offset and width of
+// its bitmap, and the bitmap rows, one bit per pixel, base64 encoded
+type cardGlyph struct {
+	Advance int
+	Offset  int
+	Width   int
+	Bits    string
+}
+
+// cardFont is DejaVu Sans Bold rasterized at 28 pixels per em, used to draw text on card images.
+// It covers printable ASCII, other text is transliterated first. DejaVu fonts are free to use and
+// redistribute, see https://dejavu-fonts.github.io/License.html.
+var cardFont = map[rune]cardGlyph{
+	' ':  {10, 0, 0, ""},
+	'!':  {13, 0, 13, "AAAAAAAAAAAHgD4B8A+AfAPgHwD4B8A+AfAPgHgDwAAAAAfAPgHwD4B8AAAAAAAAAAAAAAAA"},
+	'"':  {15, 0, 15, "AAAAAAAAAAAAA44HHA44HHA44HHA44HHAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'#':  {23, 0, 23, "AAAAAAAAAAAAAAAAAAAAAAAAHDgAOHAAccAB44ADhwB//8H//8P//4Bw4ADhwAHHAAeOAP//wf//g///APHAAcOAA4cABxwAHjgAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'$':  {19, 0, 19, "AAAAAAAAAAAAAAAAAMAAGAADAAf+Af/gf/wfMYPmAHzAD/gA/+Af/wD/8AP+ADfgBnxAz48b4f/8P/8A/4ABgAAwAAYAAMAAAAAAAAAAAA=="},
+	'%':  {28, 0, 28, "AAAAAAAAAAAAAAAAAAAAAAAA8AHAH8A4A/4HgHjgcAcPDgBw8OAHDxwAePPAB444AD/nAAH8cPAADj+AAef8ABxx4AOPDgA48OAHDw4A8PHgDgceAcB/wBwD+AGAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'&':  {24, 0, 24, "AAAAAAAAAAAAAAAAAAAAAHwAAf+AA/+AB/+AB8AAB8AAB8AAB+AAB/AAD/g8H/w8Pn48Pj98Ph/4Pg/4Pg/wPgfwP4/wH//4D//8B/x+AEAAAAAAAAAAAAAAAAAAAAAAAAAA"},
+	'\'': {9, 0, 9, "AAAAAAAA4HA4HA4HA4HAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'(':  {13, 0, 13, "AAAAAAAAAAAB4B8A8A+AeAPAPgHwDwB4B8A+AfAPgHwB4A+AfAPgDwB8AeAPgDwB4AAAAAAA"},
+	')':  {13, 0, 13, "AAAAAAAAAAAPAHgB4A8AfAHgDwB8A+AfAHgDwB8A8AeAfAPgHwDwB4B8A8A+AeAOAAAAAAAA"},
+	'*':  {15, 0, 15, "AAAAAAAAAAAAAGAA4AHAc5z3+H/APgD+B/8c7zHEA4AHAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'+':  {23, 0, 23, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAOAAAcAAA4AABwAADgAAHAAAOAA//+B//+D//8H//wAHAAAOAAAcAAA4AABwAADgAAHAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	',':  {11, 0, 11, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA+B8D4HwPA+B4DgHAAAAAAAA=="},
+	'-':  {12, 0, 12, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAf8f8f8f8AAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'.':  {11, 0, 11, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA+B8D4HwPgAAAAAAAAAAAAAA=="},
+	'/':  {10, 0, 11, "AAAAAAAAAAGAcA4DgHAOA4BwDgOAcA4DwHAOAcBwDgHAcA4BwHAGAAAAAAAAAA=="},
+	'0':  {19, 0, 19, "AAAAAAAAAAAAAAAAA+AB/wB/+B//A+Pw+D4fB+PgfPwPn4Hz8D5+B8/A+fgfHwPj4Px+Hwfn4P/4D/4Af4AAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'1':  {19, 0, 19, "AAAAAAAAAAAAAAAAAfAD/gD/wB/4A98AA+AAfAAPgAHwAD4AB8AA+AAfAAPgAHwAD4AB8Af/4P/+H//D//gAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'2':  {19, 0, 19, "AAAAAAAAAAAAAAAAB+AP/wH/+D//BwfwgH4AD8AB+AA/AAfAAfAAfAA/AA/AA/AA/AA/AA//4f/8P/+H//AAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'3':  {19, 0, 19, "AAAAAAAAAAAAAAAAD+AH/4D/+B//AgPwAH4AD8AB8AB+Af+AP/AH/wAH8AB+AAfAAPgAPw4P4f/4P/4H/4ACAAAAAAAAAAAAAAAAAAAAAA=="},
+	'4':  {19, 0, 19, "AAAAAAAAAAAAAAAAAHwAH4AH8AH+AD/AD/gD3wB74B58B4+A8fA8Pg8Hwf//P//n//z//4APgAHwAD4AB8AAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'5':  {19, 0, 19, "AAAAAAAAAAAAAAAAP/4H/8D/+B//A//geAAPAAH/AD/8B//A//wYH4AB8AA/AAfgAPxAHw8P4f/4P/4D/4ACAAAAAAAAAAAAAAAAAAAAAA=="},
+	'6':  {19, 0, 19, "AAAAAAAAAAAAAAAAAPgA/+A//A//g/AQfAAfAAPjAH/8D//B//w/D8fg+PwfHwPj8Hw+D4fD4H/8B/8Af4AAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'7':  {19, 0, 19, "AAAAAAAAAAAAAAAAf/8P/+H//D//h//wAHwAD4AD8AB8AB+AA+AA/AAfAAPgAPgAHwAHwAD4AD4AB8AB+AAAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'8':  {19, 0, 19, "AAAAAAAAAAAAAAAAA/AD/4D/+B//h+Hw+D4fB8Hw+D/+A/+AP/Af/wfh8Pg+HwPj4Hx8H4/D4f/8H/8A/8ABAAAAAAAAAAAAAAAAAAAAAA=="},
+	'9':  {19, 0, 19, "AAAAAAAAAAAAAAAAA+AD/wD/8D//B8Pw+D4/B8fg+Pwfj4Px+f4f/8H/+B/+AAfAAPgAPwYPwP/wH/wD/wAAAAAAAAAAAAAAAAAAAAAAAA=="},
+	':':  {11, 0, 11, "AAAAAAAAAAAAAAAAAAAAD4HwPgfA+AAAAAAAAAA+B8D4HwPgAAAAAAAAAAAAAA=="},
+	';':  {11, 0, 11, "AAAAAAAAAAAAAAAAAAAAD4HwPgfA+AAAAAAAAAA+B8D4HwPgeB4DwHAAAAAAAA=="},
+	'<':  {23, 0, 23, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAeAAH8AA/wAP+AD/gA/4AB+AAD4AAH+AAD/gAA/4AAf+AAH+AAB8AAAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'=':  {23, 0, 23, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAH//wP//wf//g//+AAAAAAAAAAAAP//wf//g///AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'>':  {23, 0, 23, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAgAABwAAD8AAH/AAD/wAA/4AAP+AAD+AAD8AA/4AH/AB/wAf8AB/AAD4AAGAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'?':  {16, 0, 16, "AAAAAAAAAAAAAAfAP/g//D/8MHwAfgB8AHwA/AH4A/AH4AfAB8AAAAAAB8AHwAfAB8AHwAAAAAAAAAAAAAAAAAAA"},
+	'@':  {28, 0, 28, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAA+AAAf/AAH//AA+A+AHgA8A4ABwHgQDgcP3GBh/8cOHnxw48PHDjwccOOBxw48HHDjw8YOHDzg4f/8Bw//gHA54AOAAAAcACAA8A8AB//wAD/8AAB+AAAAAAAAAAAA="},
+	'A':  {22, 0, 22, "AAAAAAAAAAAAAAAAAAAD8AAfwAB/gAH+AA/4AD/wAffAB8+AHz4A+PgD4fAPh8B8HwH//gf/+D//8P//x+AfHwB+fAD78APgAAAAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'B':  {21, 0, 21, "AAAAAAAAAAAAAAAAAA/wAP/8B//wP//B+H4Pwfh+D8PwfB//4P/+B//wP//B+D8PwPh+B8PwPh+B8P//h//4P/+B//AAAAAAAAAAAAAAAAAAAAAAAAAA"},
+	'C':  {21, 0, 21, "AAAAAAAAAAAAAAAAAAA/AA//AP/8D//g/gcH4Ah+AAPgAB8AAfgAD8AAfgAD8AAPgAB+AAPwAA/AMH//gf/8A//gD/wAAgAAAAAAAAAAAAAAAAAAAAAA"},
+	'D':  {23, 0, 23, "AAAAAAAAAAAAAAAAAAAD+AAP/8Af/+A//+B//+D8D+H4D8PwD4fgH4/APx+Afj8A/H4B+PwD8fgPw/Afh+D+D//8H//wP/+Af/gAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'E':  {19, 0, 19, "AAAAAAAAAAAAAAAAP/4P/+H//D//h//g/AAfgAPwAH/+D//B//g//wfgAPwAH4AD8AB+AA//4f/8P/+H//AAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'F':  {19, 0, 19, "AAAAAAAAAAAAAAAAP/4P/+H//D//h//g/AAfgAPwAH/+D//B//g//wfgAPwAH4AD8AB+AA/AAfgAPwAH4AAAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'G':  {23, 0, 23, "AAAAAAAAAAAAAAAAAAAAD+AAf/wD//gP//A/wOB+AEH4AAPgAAfAAB+AAD8D/H4H+PwP8PgD4fgHw/APg/AfB//+B//8A//4A/+AACAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'H':  {23, 0, 23, "AAAAAAAAAAAAAAAAAAADwB4PwH4fgPw/Afh+A/D8B+H4D8PwH4f//w///h///D//+H4D8PwH4fgPw/Afh+A/D8B+H4D8PwH4fgPwAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'I':  {10, 0, 10, "AAAAAAAAB4Pw/D8Pw/D8Pw/D8Pw/D8Pw/D8Pw/D8Pw/AAAAAAAAAAAAA"},
+	'J':  {10, -1, 11, "AAAAAAAAAB4H4Pwfg/B+D8H4Pwfg/B+D8H4Pwfg/B+D8H4Pwfj+P8fw/BwAAAA=="},
+	'K':  {22, 0, 23, "AAAAAAAAAAAAAAAAAAADwB8PwP4fg/g/D+B+P4D8/gH7+AP/4Af/AA/8AB/4AD/4AH/4AP/4Afv4A/P4B+P4D8P4H4P4PwP4fgP4AAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'L':  {18, 0, 18, "AAAAAAAAAAAAAAAHgAPwAPwAPwAPwAPwAPwAPwAPwAPwAPwAPwAPwAPwAPwAPwAPwAP/+P/+P/+P/+AAAAAAAAAAAAAAAAAAAAAA"},
+	'M':  {28, 0, 28, "AAAAAAAAAAAAAAAAAAAAAAAB+AH4P8A/g/wD+D/gf4P+B/g/4P+D/w/4P/D/g/ee+D9574P3/Pg/P8+D8/z4Px+Pg/H4+D8PD4Pw8Pg/AA+D8AD4PwAPg/AA+AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'N':  {23, 0, 23, "AAAAAAAAAAAAAAAAAAAD4B4P4D4f4Hw/wPh/wfD/g+H/h8P/D4f/Hw/ePh++fD88+H598Px/4fj/w/D/h+D/D8H+H4H8PwP4fgPwAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'O':  {24, 0, 24, "AAAAAAAAAAAAAAAAAAAAAH4AA//AB//gD//wH8P4PwH4PwD8PgD8PgB8fgB8fgB8fgB8fgB8PgB8PgD8PwD8H4H4H//4D//wB//gAf+AAAAAAAAAAAAAAAAAAAAAAAAAAAAA"},
+	'P':  {21, 0, 21, "AAAAAAAAAAAAAAAAAA/4AP/8B//wP//B//8Pwfh+B8PwPh+B8Pwfh//8P//B//wP/4B+AAPwAB+AAPwAB+AAPwAB+AAAAAAAAAAAAAAAAAAAAAAAAAAA"},
+	'Q':  {24, 0, 24, "AAAAAAAAAAAAAAAAAAAAAH4AA//AB//gD//wH8P4PwH4PwD8PgD8PgB8fgB8fgB8fgB8fgB8PgB8PgD8PwD8H4H4H//wD//wB//AAf+AAAfAAAfgAAPgAAHwAAAAAAAAAAAA"},
+	'R':  {22, 0, 22, "AAAAAAAAAAAAAAAAAAB/gAP/8A//4D//wP//A/B8D8HwPwfA/B8D8fgP/8A//gD//APx+A/D8D8HwPwfg/A+D8D8PwHw/AfgAAAAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'S':  {20, 0, 20, "AAAAAAAAAAAAAAAAAB/AD/+B//gf/4PwOD4AA+AAPwAD/gAf/gD/8Af/gA/8AA/AAHwAB8MAfD//w//4P/8A/+AAQAAAAAAAAAAAAAAAAAAAAAA="},
+	'T':  {19, 0, 19, "AAAAAAAAAAAAAAAA////////////7//8B8AA+AAfAAPgAHwAD4AB8AA+AAfAAPgAHwAD4AB8AA+AAfAAPgAAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'U':  {23, 0, 23, "AAAAAAAAAAAAAAAAAAADwD4PwHwfgPg/AfB+A+D8B8H4D4PwHwfgPg/AfB+A+D8B8H4D4PwHwPgPgfAfA/B+B//4B//wB//AB/4AAEAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'V':  {22, 0, 22, "AAAAAAAAAAAAAAAAAAHgAe/AD58APnwB8PgHw+A/D8D4HwPgfB+B+HwD4fAPj8AfPgB8+AH3wAP/AA/8AD/gAH+AAf4AB/AAAAAAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'W':  {31, 0, 31, "AAAAAAAAAAAAAAAAAAAAAAAAAA+A+A+fAfAfPgfgPn4P4Ph8H8Hw+D+D4fD/B8Px7x8D494+B8e8fA+OOPgfvHvgH3j3wD7x74B/wf8A/4P+AP8H+AH+D/AD+B/gB/AfwAfgPwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'X':  {22, 0, 22, "AAAAAAAAAAAAAAAAAAHwA8fgHw/A+B8H4H4fAPz4AffgB/8AD/gAH+AAfwAB/gAP+AA/8AH/4A/PgD4/AfB+D8D4PgPx8AfgAAAAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'Y':  {20, 0, 21, "AAAAAAAAAAAAAAAAAHwA+/APz8B8Pgfh+H4H4+AfPwD/8AP/AA/4AH+AAfgAD8AAfgAD8AAfgAD8AAfgAD8AAfgAD8AAAAAAAAAAAAAAAAAAAAAAAAAA"},
+	'Z':  {20, 0, 20, "AAAAAAAAAAAAAAAAA//8f//n//5//+P//AAfgAHwAD8AB+AA/AAfgAPwAD8AB+AA/AAfgAPwAH//5//+f//n//4AAAAAAAAAAAAAAAAAAAAAAAA="},
+	'[':  {13, 0, 13, "AAAAAAAAAAAf8P+H/D4B8A+AfAPgHwD4B8A+AfAPgHwD4B8A+AfAPgHwD/h/w/4P8AAAAAAA"},
+	'\\': {10, 0, 11, "AAAAAAAAAcA4A4BwDgDgHAOAeAcA4BwBwDgHAHAOAcAcA4BwBwDgHAAAAAAAAA=="},
+	']':  {13, 0, 13, "AAAAAAAAAAAf8P+H/APgHwD4B8A+AfAPgHwD4B8A+AfAPgHwD4B8A+AfD/h/w/4f4AAAAAAA"},
+	'^':  {23, 0, 23, "AAAAAAAAAAAAAAAAAAAABwAAHwAAfwAB/wAH3wAfDwB4DwHgDwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'_':  {14, 0, 15, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA//3/+//w="},
+	'`':  {14, 0, 14, "AAAAAAAOADwAeADgAcADgAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'a':  {19, 0, 19, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAH8Af/gP/4H/+AAfAAHwH/4P/8P/+HwfHwPj4Px+P4f/8P++D+fAIAAAAAAAAAAAAAAAAAAAAAA=="},
+	'b':  {20, 0, 20, "AAAAAAAAAAAAAAAAA+AAPgAD4AA+AAPgAD4cA+fwP/+D//w/j8PwfD4H4+A+PgPj4D4/B+PwfD+Pw//4Pv+D5/AAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'c':  {17, 0, 17, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAADwA/8H/4P/w/Bh8AH4APgAfAA+AB+AB8AD+GD/8D/4D/wAAAAAAAAAAAAAAAAAAAAA="},
+	'd':  {20, 0, 20, "AAAAAAAAAAAAAAAAAAB8AAfAAHwAB8AAfAOHwP58H//D//w/H8Pg/H4Hx8B8fAfHwHx+D8Pg/D8fwf/8H/fAfnwAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'e':  {19, 0, 19, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAB8AD/gH/4D/+D4Ph8Hx8D8//+f//P//nwAB8AQ/A4P/8D/+A/+AAAAAAAAAAAAAAAAAAAAAAAA=="},
+	'f':  {12, 0, 13, "AAAAAAAAAAAD/D/j/x8A+AfB/+//f/h8A+AfAPgHwD4B8A+AfAPgHwD4AAAAAAAAAAAAAAAA"},
+	'g':  {20, 0, 20, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAOAAP58H//D//w/H8Pg/H4Hx8B8fAfH4Hx+D8Pg/D//wf/8D/fAfHwAD8AA+Bw/gf/wH/4A/4AAAAA="},
+	'h':  {20, 0, 20, "AAAAAAAAAAAAAAAAA+AAPgAD4AA+AAPgAD4cA+fwP/+D//g/j8PwfD8Hw+B8PgfD4Hw+B8PgfD4Hw+B8PgfD4HwAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'i':  {10, 0, 10, "AAAAAAAAD4Pg+D4AAAD4Pg+D4Pg+D4Pg+D4Pg+D4Pg+AAAAAAAAAAAAA"},
+	'j':  {10, 0, 10, "AAAAAAAAD4Pg+D4AAAD4Pg+D4Pg+D4Pg+D4Pg+D4Pg+D4Ph+Pw/DwAAA"},
+	'k':  {19, 0, 20, "AAAAAAAAAAAAAAAAA+AAPgAD4AA+AAPgAD4AA+D8Ph+D4/A+fgPvgD/wA/4AP/AD/4A+/APn4D4/A+H4Pg/D4H4AAAAAAAAAAAAAAAAAAAAAAAA="},
+	'l':  {10, 0, 10, "AAAAAAAAD4Pg+D4Pg+D4Pg+D4Pg+D4Pg+D4Pg+D4Pg+AAAAAAAAAAAAA"},
+	'm':  {29, 0, 29, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA4DgHz8fwP/9/wf///g/n+fh+H4fD8Pg+HwfB8Pg+D4fB8Hw+D4Ph8HwfD4Pg+HwfB8Pg+D4fB8HwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"},
+	'n':  {20, 0, 20, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAcA+fwP/+D//g/j8PwfD8Hw+B8PgfD4Hw+B8PgfD4Hw+B8PgfD4HwAAAAAAAAAAAAAAAAAAAAAAAA="},
+	'o':  {19, 0, 19, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAB8AD/wH/8D//D8Ph8H5+B8+A+fAfPgPn4Hx8H4/H4P/8D/8A/4ABAAAAAAAAAAAAAAAAAAAAAA=="},
+	'p':  {20, 0, 20, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAcA+fwP/+D//w/j8PwfD4H4+A+PgPj4D4/B+PwfD+Pw//4Pv+D5/A+AAPgAD4AA+AAPgAD4AAAAAA="},
+	'q':  {20, 0, 20, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAOAAP58H//D//w/H8Pg/H4Hx8B8fAfHwHx+D8Pg/D8fwf/8H/fAfnwAB8AAfAAHwAB8AAfAAHwAAAA="},
+	'r':  {14, 0, 14, "AAAAAAAAAAAAAAAAAAAAAAAAAY+fP/z/8/xPwD8A+APgD4A+APgD4A+APgD4AAAAAAAAAAAAAAAAAA=="},
+	's':  {17, 0, 17, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAPgD/4P/wf/h8BD4AD/AH/wH/wD/wAPgAPDA+H/8P/wf/AAgAAAAAAAAAAAAAAAAAAA="},
+	't':  {13, 0, 13, "AAAAAAAAAAAAAHwD4B8A+AfB//////x8A+AfAPgHwD4B8A+Af8P+D/A/gAAAAAAAAAAAAAAA"},
+	'u':  {20, 0, 20, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA+B8PgfD4Hw+B8PgfD4Hw+B8PgfD4Hw+D8Pg/D+/wf/8H/fA/nwBAAAAAAAAAAAAAAAAAAAAAAA="},
+	'v':  {18, 0, 18, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAfAffA+PA+Pg8Ph8Hh8Hz4Hz4D7wD/wB/wB/gB/gA/AA/AAAAAAAAAAAAAAAAAAAAAAA"},
+	'w':  {26, 0, 26, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAB8Hg+fD4PDw/Hw+Px8Pj8fD5/Hged74H3O+B9zvgP8/wD+P8A/h/AP4fwB+H4Afh+AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"},
+	'x':  {18, 0, 18, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAfA+Ph8Hz4H/4D/wB/gA/AA/AB/gD/wD/wHz4Ph8Ph+fA+AAAAAAAAAAAAAAAAAAAAAA"},
+	'y':  {18, 0, 18, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAfAefA+PA+Pg8Ph8Hx8Hx4Dz4D7wB/wB/wB/gA/gA/AAfAAfAAeAA+AH8AH8AHwAAAAA"},
+	'z':  {16, 0, 16, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAH/+f/5//gB+APwB+APwB+APwB+APwB//n/+f/5//gAAAAAAAAAAAAAAAAAA"},
+	'{':  {20, 0, 20, "AAAAAAAAAAAAAAAAAAP4AH+AD/AA+AAPgAD4AA+AAPgAD4AB8AAfAA/wAf4AH+AAPwAB8AAPAAD4AA+AAPgAD4AA+AAP8AD/gAf4AA8AAAAAAAA="},
+	'|':  {10, 0, 10, "AAAAAAAMB4HgeB4HgeB4HgeB4HgeB4HgeB4HgeB4HgeB4HgeB4HgeAwA"},
+	'}':  {20, 0, 20, "AAAAAAAAAAAAAAAAAfwAH+AA/wAB8AAfAAHwAB8AAfAAHwAA8AAPgAD/AAf4AH+AD8AA+AAfAAHwAB8AAfAAHwAB8AD/AB/wAf4ADwAAAAAAAAA="},
+	'~':  {23, 0, 23, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAP4DB//+D//4GB/gIA8AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"flag"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+const cardWidth = 1200
+const cardHeight = 630
+const cardMargin = 80
+
+var cards = flag.Bool("cards", false, "Generate Open Graph card images with the title and blog title for articles without an image")
+
+// cardBackgroundFileNames are images in the templates directory cards are drawn on, tried in order.
+// Without one, cards are drawn on a plain background.
+var cardBackgroundFileNames = []string{"cardtemplate.png", "cardtemplate.jpg", "cardtemplate.jpeg"}
+
+var cardBackgroundColor = color.RGBA{0x1d, 0x27, 0x33, 0xff}
+var cardTextColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+
+// cardPath is where the card image of an article is published, relative to the site root
+func cardPath(article *post.Article) string {
+	return path.Join(article.BasePath(), articleStem(article)+"-card.png")
+}
+
+// hasCard tells whether a card image is generated for an article
+func hasCard(article *post.Article) bool {
+	return *cards && article.Image == ""
+}
+
+// cardImage is the address of the image representing an article when shared, its image or its
+// generated card, or empty when it has neither
+func cardImage(article *post.Article) string {
+	switch {
+	case article.Image != "":
+		return article.Image
+	case hasCard(article):
+		return absURL(cardPath(article))
+	}
+
+	return ""
+}
+
+// cardMeta renders Open Graph and Twitter meta tags for the image of an article
+func cardMeta(article *post.Article) string {
+	image := cardImage(article)
+
+	if image == "" {
+		return ""
+	}
+
+	return `<meta property="og:image" content="` + html.EscapeString(image) + `">` + "\n" +
+		`<meta name="twitter:card" content="summary_large_image">`
+}
+
+// loadCardBackground returns the image cards are drawn on
+func loadCardBackground() image.Image {
+	for _, name := range cardBackgroundFileNames {
+		file, err := os.Open(path.Join(*templatesPath, name))
+
+		if err != nil {
+			continue
+		}
+
+		background, _, err := image.Decode(file)
+		file.Close()
+
+		if err != nil {
+			log.Printf("Could not read card background %v: %v", name, err)
+			break
+		}
+
+		return background
+	}
+
+	return image.NewUniform(cardBackgroundColor)
+}
+
+// cardText returns the glyphs of text, transliterated to the ASCII the card font covers
+func cardText(text string) []cardGlyph {
+	var glyphs []cardGlyph
+
+	for _, r := range transliterate(text) {
+		if r == '\n' || r == '\t' {
+			r = ' '
+		}
+
+		glyph, found := cardFont[r]
+
+		if !found {
+			glyph = cardFont['?']
+		}
+
+		glyphs = append(glyphs, glyph)
+	}
+
+	return glyphs
+}
+
+// textWidth measures text drawn at a scale
+func textWidth(text string, scale int) int {
+	width := 0
+
+	for _, glyph := range cardText(text) {
+		width += glyph.Advance * scale
+	}
+
+	return width
+}
+
+// wrapText breaks text into lines fitting a width, ending the last line with an ellipsis when the
+// text does not fit into the given number of lines
+func wrapText(text string, width, scale, maxLines int) []string {
+	var lines []string
+	line := ""
+
+	for _, word := range strings.Fields(text) {
+		candidate := strings.TrimSpace(line + " " + word)
+
+		if line != "" && textWidth(candidate, scale) > width {
+			lines = append(lines, line)
+			line = word
+		} else {
+			line = candidate
+		}
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		last := lines[maxLines-1]
+
+		for last != "" && textWidth(last+"...", scale) > width {
+			last = last[:len(last)-1]
+		}
+
+		lines[maxLines-1] = strings.TrimSpace(last) + "..."
+	}
+
+	return lines
+}
+
+// drawText draws a line of text with its top left corner at a point, each font pixel scaled up
+func drawText(canvas draw.Image, text string, x, y, scale int, ink color.Color) {
+	fill := image.NewUniform(ink)
+
+	for _, glyph := range cardText(text) {
+		bits, _ := base64.StdEncoding.DecodeString(glyph.Bits)
+
+		for row := 0; row < cardFontHeight; row++ {
+			for column := 0; column < glyph.Width; column++ {
+				bit := row*glyph.Width + column
+
+				if bit/8 >= len(bits) || bits[bit/8]&(0x80>>uint(bit%8)) == 0 {
+					continue
+				}
+
+				left, top := x+(glyph.Offset+column)*scale, y+row*scale
+				draw.Draw(canvas, image.Rect(left, top, left+scale, top+scale), fill, image.Point{}, draw.Src)
+			}
+		}
+
+		x += glyph.Advance * scale
+	}
+}
+
+// renderCard draws the title of an article in large type and the blog title below it
+func renderCard(background image.Image, article *post.Article) []byte {
+	bounds := image.Rect(0, 0, cardWidth, cardHeight)
+
+	if _, uniform := background.(*image.Uniform); !uniform {
+		bounds = background.Bounds().Sub(background.Bounds().Min)
+	}
+
+	canvas := image.NewRGBA(bounds)
+	draw.Draw(canvas, bounds, background, background.Bounds().Min, draw.Src)
+
+	title := article.Title
+
+	if title == "" {
+		title = article.Description
+	}
+
+	title = transliterate(title)
+
+	y := cardMargin
+
+	for _, line := range wrapText(title, bounds.Dx()-2*cardMargin, 2, 4) {
+		drawText(canvas, line, cardMargin, y, 2, cardTextColor)
+		y += cardFontHeight * 2
+	}
+
+	drawText(canvas, *blogTitle, cardMargin, bounds.Dy()-cardMargin-cardFontHeight, 1, cardTextColor)
+
+	var buffer bytes.Buffer
+
+	if err := png.Encode(&buffer, canvas); err != nil {
+		log.Printf("Could not encode card of %v: %v", article.Identifier, err)
+		return nil
+	}
+
+	return buffer.Bytes()
+}
+
+// writeCard writes the card image of an article without an image, when cards are enabled
+func writeCard(destinationDir string, background image.Image, article *post.Article) {
+	if !hasCard(article) {
+		return
+	}
+
+	card := renderCard(background, article)
+
+	if card == nil {
+		return
+	}
+
+	cardFileName := path.Join(destinationDir, cardPath(article))
+
+	if writeErr := writeFile(cardFileName, card); writeErr != nil {
+		log.Printf("Could not write file %v due to error: %v", cardFileName, writeErr)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultsFileName is the file in a posts subdirectory giving default front matter to all sources
+// underneath, like:
+//
+//	type: Snippet
+//	tags: [micro]
+//
+// Defaults of deeper directories override those above, and front matter of a source overrides them all.
+const defaultsFileName = "_defaults.yaml"
+
+// readDefaults reads the defaults file of a directory, nil when it has none
+func readDefaults(dir string) map[string]string {
+	contents, err := ioutil.ReadFile(filepath.Join(dir, defaultsFileName))
+
+	if err != nil {
+		return nil
+	}
+
+	var values map[string]interface{}
+
+	if err := yaml.Unmarshal(contents, &values); err != nil {
+		log.Printf("Ignoring %v: %v", filepath.Join(dir, defaultsFileName), err)
+		return nil
+	}
+
+	defaults := map[string]string{}
+
+	for key, value := range values {
+		if list, isList := value.([]interface{}); isList {
+			defaults[key] = strings.Join(matterStrings(list), ", ")
+		} else {
+			defaults[key] = matterString(value)
+		}
+	}
+
+	return defaults
+}
+
+// cascadedDefaults collects the default front matter of a source file from the defaults files
+// of its directory and the directories above it, up to the posts directory
+func cascadedDefaults(sourceFile PostFile, cache map[string]map[string]string) map[string]string {
+	root := filepath.Clean(sourceFile.Dir)
+	var dirs []string
+
+	for dir := filepath.Dir(sourceFile.Path); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+
+		if dir == root || dir == filepath.Dir(dir) || !strings.HasPrefix(dir, root) {
+			break
+		}
+	}
+
+	var defaults map[string]string
+
+	// the posts directory comes first, so deeper directories override it
+	for index := len(dirs) - 1; index >= 0; index-- {
+		dirDefaults, cached := cache[dirs[index]]
+
+		if !cached {
+			dirDefaults = readDefaults(dirs[index])
+			cache[dirs[index]] = dirDefaults
+		}
+
+		for key, value := range dirDefaults {
+			if defaults == nil {
+				defaults = map[string]string{}
+			}
+
+			defaults[key] = value
+		}
+	}
+
+	return defaults
+}
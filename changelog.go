@@ -0,0 +1,41 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"macbirdie.net/blogger/post"
+)
+
+// An article lists its edits in front matter, newest first or in any order:
+//
+//	edits: 2024-03-10 Fixed a typo; 2024-04-01 major: Rewrote the conclusion
+//
+// Templates render them from .Article.Changelog, each edit having a Date, Note and Major flag.
+// A major edit brings the article back to the top of the feed, with feedUpdated as its Atom
+// updated date.
+
+// feedUpdated is when an article last changed in a way feed readers should notice: its newest
+// major edit, or its updated or publication date when it has no changelog
+func feedUpdated(article *post.Article) *time.Time {
+	if len(article.Edits) > 0 {
+		if major := article.LastMajorEdit(); major != nil {
+			return major
+		}
+
+		return article.DateModified
+	}
+
+	if article.DateUpdated != nil {
+		return article.DateUpdated
+	}
+
+	return article.DateModified
+}
+
+// sortByFeedUpdate orders feed articles by their last significant change, newest first
+func sortByFeedUpdate(articles post.Articles) {
+	sort.SliceStable(articles, func(i, j int) bool {
+		return feedUpdated(articles[j]).Before(*feedUpdated(articles[i]))
+	})
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)<(?:a|link|img|script|iframe|source)\s[^>]*?(?:href|src)\s*=\s*["']([^"']*)["']`)
+var anchorPattern = regexp.MustCompile(`(?i)\s(?:id|name)\s*=\s*["']([^"']*)["']`)
+
+// Link is a single link found in a generated file
+type Link struct {
+	File   string
+	Line   int
+	Target string
+}
+
+// BrokenLink is a link which couldn't be resolved, with a reason and the source file of the page
+type BrokenLink struct {
+	Link
+	Source string
+	Reason string
+}
+
+func (b BrokenLink) String() string {
+	if b.Source != "" {
+		return fmt.Sprintf("%s (%s:%d): %s (%s)", b.Source, b.File, b.Line, b.Target, b.Reason)
+	}
+
+	return fmt.Sprintf("%s:%d: %s (%s)", b.File, b.Line, b.Target, b.Reason)
+}
+
+// findLinks returns all links and anchor identifiers of a generated HTML file
+func findLinks(fileName string, contents []byte) ([]Link, map[string]bool) {
+	var links []Link
+	anchors := map[string]bool{}
+
+	for lineIndex, line := range bytes.Split(contents, []byte("\n")) {
+		for _, match := range linkPattern.FindAllSubmatch(line, -1) {
+			links = append(links, Link{File: fileName, Line: lineIndex + 1, Target: string(match[1])})
+		}
+
+		for _, match := range anchorPattern.FindAllSubmatch(line, -1) {
+			anchors[string(match[1])] = true
+		}
+	}
+
+	return links, anchors
+}
+
+// resolveInternalLink maps a link to a file in the destination directory and an anchor
+func resolveInternalLink(destinationDir string, link Link, target *url.URL) (string, string) {
+	linkPath := target.Path
+
+	if linkPath == "" {
+		return link.File, target.Fragment
+	}
+
+	if strings.HasPrefix(linkPath, "/") {
+		if root := strings.TrimSuffix(rootPath(), "/"); linkPath == root || strings.HasPrefix(linkPath, root+"/") {
+			linkPath = strings.TrimPrefix(linkPath, root)
+		}
+
+		linkPath = path.Join(destinationDir, linkPath)
+	} else {
+		linkPath = path.Join(path.Dir(link.File), linkPath)
+	}
+
+	return linkPath, target.Fragment
+}
+
+// fileForPath finds a generated file for a link path, trying directory indexes and the destination extension
+func fileForPath(linkPath string) (string, bool) {
+	candidates := []string{linkPath, linkPath + *destinationExt, path.Join(linkPath, "index.html")}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+// ExternalLinkCache remembers results of external link checks between runs
+type ExternalLinkCache struct {
+	fileName string
+	maxAge   time.Duration
+	mutex    sync.Mutex
+	Entries  map[string]ExternalLinkResult
+}
+
+// ExternalLinkResult is a cached result of an external link check
+type ExternalLinkResult struct {
+	Status  int
+	Error   string
+	Checked time.Time
+}
+
+func loadExternalLinkCache(fileName string, maxAge time.Duration) *ExternalLinkCache {
+	cache := &ExternalLinkCache{fileName: fileName, maxAge: maxAge, Entries: map[string]ExternalLinkResult{}}
+
+	if fileName == "" {
+		return cache
+	}
+
+	if contents, err := ioutil.ReadFile(fileName); err == nil {
+		json.Unmarshal(contents, &cache.Entries)
+	}
+
+	return cache
+}
+
+func (c *ExternalLinkCache) get(target string) (ExternalLinkResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result, found := c.Entries[target]
+
+	if !found || time.Since(result.Checked) > c.maxAge {
+		return result, false
+	}
+
+	return result, true
+}
+
+func (c *ExternalLinkCache) set(target string, result ExternalLinkResult) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.Entries[target] = result
+}
+
+func (c *ExternalLinkCache) save() {
+	if c.fileName == "" {
+		return
+	}
+
+	contents, _ := json.MarshalIndent(c.Entries, "", "  ")
+
+	if err := ioutil.WriteFile(c.fileName, contents, 0644); err != nil {
+		log.Printf("Could not write link cache %v: %v", c.fileName, err)
+	}
+}
+
+// checkExternalLink requests a link with HEAD, falling back to GET for servers not supporting it
+func checkExternalLink(client *http.Client, target string) ExternalLinkResult {
+	result := ExternalLinkResult{Checked: time.Now()}
+
+	for _, method := range []string{"HEAD", "GET"} {
+		request, err := http.NewRequest(method, target, nil)
+
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+
+		request.Header.Set("User-Agent", "blogger link checker")
+
+		response, err := client.Do(request)
+
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+
+		response.Body.Close()
+		result.Status = response.StatusCode
+		result.Error = ""
+
+		if response.StatusCode != http.StatusMethodNotAllowed && response.StatusCode != http.StatusNotImplemented {
+			break
+		}
+	}
+
+	return result
+}
+
+// checkExternalLinks checks unique external links with limited concurrency
+func checkExternalLinks(links []Link, concurrency int, cache *ExternalLinkCache) []BrokenLink {
+	client := &http.Client{Timeout: 15 * time.Second}
+	targets := map[string][]Link{}
+
+	for _, link := range links {
+		targets[link.Target] = append(targets[link.Target], link)
+	}
+
+	var broken []BrokenLink
+	var brokenMutex sync.Mutex
+	var wait sync.WaitGroup
+	limit := make(chan bool, concurrency)
+
+	for target, targetLinks := range targets {
+		wait.Add(1)
+
+		go func(target string, targetLinks []Link) {
+			defer wait.Done()
+
+			result, cached := cache.get(target)
+
+			if !cached {
+				limit <- true
+				result = checkExternalLink(client, target)
+				<-limit
+				cache.set(target, result)
+			}
+
+			reason := result.Error
+
+			if reason == "" && result.Status >= 400 {
+				reason = fmt.Sprintf("HTTP %d", result.Status)
+			}
+
+			if reason == "" {
+				return
+			}
+
+			brokenMutex.Lock()
+			for _, link := range targetLinks {
+				broken = append(broken, BrokenLink{Link: link, Reason: reason})
+			}
+			brokenMutex.Unlock()
+		}(target, targetLinks)
+	}
+
+	wait.Wait()
+
+	return broken
+}
+
+// checkLinks validates links in all HTML files of the destination directory, including absolute
+// links to the -root host, attributing broken ones to the source files of the pages
+func checkLinks(destinationDir string, external bool, concurrency int, cache *ExternalLinkCache) []BrokenLink {
+	fileLinks := map[string][]Link{}
+	fileAnchors := map[string]map[string]bool{}
+
+	filepath.Walk(destinationDir, func(fileName string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		ext := path.Ext(fileName)
+
+		if ext != ".html" && ext != ".htm" && ext != *destinationExt {
+			return nil
+		}
+
+		contents, readErr := ioutil.ReadFile(fileName)
+
+		if readErr != nil {
+			log.Printf("Skipping %v due to error: %v", fileName, readErr)
+			return nil
+		}
+
+		fileLinks[fileName], fileAnchors[fileName] = findLinks(fileName, contents)
+
+		return nil
+	})
+
+	var externalLinks []Link
+	var brokenLinks []BrokenLink
+
+	for _, links := range fileLinks {
+		for _, link := range links {
+			target, err := url.Parse(link.Target)
+
+			if err != nil {
+				brokenLinks = append(brokenLinks, BrokenLink{Link: link, Reason: "invalid URL"})
+				continue
+			}
+
+			// absolute links to the site itself are checked against the output like relative ones
+			if isExternalLink(link.Target) {
+				externalLinks = append(externalLinks, link)
+				continue
+			}
+
+			if target.Scheme != "" && target.Scheme != "http" && target.Scheme != "https" {
+				continue
+			}
+
+			linkPath, anchor := resolveInternalLink(destinationDir, link, target)
+			fileName, found := fileForPath(linkPath)
+
+			if !found {
+				brokenLinks = append(brokenLinks, BrokenLink{Link: link, Reason: "missing file"})
+				continue
+			}
+
+			if anchor == "" {
+				continue
+			}
+
+			anchors, parsed := fileAnchors[fileName]
+
+			if !parsed {
+				contents, _ := ioutil.ReadFile(fileName)
+				_, anchors = findLinks(fileName, contents)
+				fileAnchors[fileName] = anchors
+			}
+
+			if !anchors[anchor] {
+				brokenLinks = append(brokenLinks, BrokenLink{Link: link, Reason: "missing anchor"})
+			}
+		}
+	}
+
+	if external {
+		brokenLinks = append(brokenLinks, checkExternalLinks(externalLinks, concurrency, cache)...)
+	}
+
+	sources := outputSources(destinationDir)
+
+	for index := range brokenLinks {
+		relative, _ := filepath.Rel(destinationDir, brokenLinks[index].File)
+		brokenLinks[index].Source = sources[filepath.ToSlash(relative)]
+	}
+
+	sort.Slice(brokenLinks, func(i, j int) bool {
+		if brokenLinks[i].Source != brokenLinks[j].Source {
+			return brokenLinks[i].Source < brokenLinks[j].Source
+		}
+		if brokenLinks[i].File != brokenLinks[j].File {
+			return brokenLinks[i].File < brokenLinks[j].File
+		}
+		return brokenLinks[i].Line < brokenLinks[j].Line
+	})
+
+	return brokenLinks
+}
+
+// check reports broken links, and with -html invalid markup and with -a11y accessibility
+// problems, in the generated site, exiting with an error status when any are found
+func check(args []string) {
+	checkFlags := flag.NewFlagSet("check", flag.ExitOnError)
+	external := checkFlags.Bool("external", false, "Check external links with HEAD requests")
+	concurrency := checkFlags.Int("concurrency", 8, "Maximum number of concurrent external link checks")
+	cacheFile := checkFlags.String("cache", "", "File caching external link check results")
+	cacheAge := checkFlags.Duration("cache-age", 24*time.Hour, "How long cached external link results stay valid")
+	markup := checkFlags.Bool("html", false, "Validate the HTML of generated pages too, reporting problems by source file")
+	accessibility := checkFlags.Bool("a11y", false, "Audit generated pages for images without alt text, skipped heading levels and links without text, reporting by post")
+	checkFlags.Parse(args)
+
+	if *concurrency < 1 {
+		*concurrency = 1
+	}
+
+	cache := loadExternalLinkCache(*cacheFile, *cacheAge)
+	brokenLinks := checkLinks(path.Clean(*destinationPath), *external, *concurrency, cache)
+	cache.save()
+
+	for _, brokenLink := range brokenLinks {
+		fmt.Println(brokenLink)
+	}
+
+	var markupIssues []HTMLIssue
+
+	if *markup {
+		markupIssues = checkMarkup(path.Clean(*destinationPath))
+
+		for _, issue := range markupIssues {
+			fmt.Println(issue)
+		}
+	}
+
+	accessibilityIssues := 0
+
+	if *accessibility {
+		accessibilityIssues = printAccessibilityReport(checkAccessibility(path.Clean(*destinationPath)))
+	}
+
+	if len(brokenLinks) > 0 || len(markupIssues) > 0 || accessibilityIssues > 0 {
+		log.Fatalf("Found %d broken links, %d HTML problems and %d accessibility problems", len(brokenLinks), len(markupIssues), accessibilityIssues)
+	}
+
+	log.Println("No broken links found")
+}
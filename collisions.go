@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"path"
+
+	"macbirdie.net/blogger/post"
+)
+
+// articleOutputs lists files an article is written to, its page and additional formats
+func articleOutputs(destinationDir string, article *post.Article, formats []OutputFormat) []string {
+	articleDestinationDir := articleDestination(destinationDir, article)
+	outputs := []string{path.Join(articleDestinationDir, article.OutputPath())}
+
+	if hasCard(article) {
+		outputs = append(outputs, path.Join(articleDestinationDir, cardPath(article)))
+	}
+
+	if hasPublishedSource(article) {
+		outputs = append(outputs, path.Join(articleDestinationDir, markdownSourcePath(article)))
+	}
+
+	for _, format := range formats {
+		if format.Applies(article) {
+			outputs = append(outputs, path.Join(articleDestinationDir, format.FileName(article)))
+		}
+	}
+
+	return outputs
+}
+
+// checkCollisions finds articles which would be written to the same file, like posts with the
+// same name in different post directories. Collisions fail the build, except while watching,
+// where the article read later is left out.
+func checkCollisions(destinationDir string, articles post.Articles, formats []OutputFormat) post.Articles {
+	writers := map[string]*post.Article{}
+	var kept post.Articles
+	collisions := 0
+
+	for _, article := range articles {
+		collides := false
+
+		for _, output := range articleOutputs(destinationDir, article, formats) {
+			if previous, found := writers[output]; found {
+				reportError(&SourceError{File: article.Source, Err: fmt.Errorf("would be written to %v like %v", output, previous.Source)})
+				collides = true
+			}
+		}
+
+		if collides {
+			collisions++
+			continue
+		}
+
+		for _, output := range articleOutputs(destinationDir, article, formats) {
+			writers[output] = article
+		}
+
+		kept = append(kept, article)
+	}
+
+	if collisions > 0 && !*listen {
+		failBuild("Found %d articles colliding with other articles", collisions)
+	}
+
+	return kept
+}
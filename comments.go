@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/russross/blackfriday"
+
+	"gopkg.in/yaml.v3"
+
+	"macbirdie.net/blogger/post"
+)
+
+// CommentSource provides comments for articles, keyed by article identifier
+type CommentSource interface {
+	Comments(articles post.Articles) (map[string][]post.Comment, error)
+}
+
+// commentSource creates a comment source from a type:location specification
+func commentSource(specification string) (CommentSource, error) {
+	values := strings.SplitN(specification, ":", 2)
+
+	if len(values) != 2 {
+		return nil, fmt.Errorf("invalid comment source %q, expected type:location", specification)
+	}
+
+	switch values[0] {
+	case "dir":
+		return DirectoryComments{Path: values[1]}, nil
+	case "github":
+		return GitHubComments{Repository: values[1], Token: os.Getenv("GITHUB_TOKEN")}, nil
+	}
+
+	return nil, fmt.Errorf("unknown comment source %q", values[0])
+}
+
+// renderComment renders comment Markdown, leaving out any raw HTML commenters included
+func renderComment(markdown string) string {
+	renderer := blackfriday.HtmlRenderer(blackfriday.HTML_SKIP_HTML|blackfriday.HTML_SAFELINK|blackfriday.HTML_NOFOLLOW_LINKS, "", "")
+	extensions := blackfriday.EXTENSION_AUTOLINK | blackfriday.EXTENSION_FENCED_CODE | blackfriday.EXTENSION_STRIKETHROUGH
+
+	return string(blackfriday.Markdown([]byte(markdown), renderer, extensions))
+}
+
+// attachComments loads comments from a source and assigns them to articles, oldest first
+func attachComments(articles post.Articles, specification string) {
+	if specification == "" {
+		return
+	}
+
+	source, err := commentSource(specification)
+
+	if err != nil {
+		log.Printf("Skipping comments: %v", err)
+		return
+	}
+
+	comments, err := source.Comments(articles)
+
+	if err != nil {
+		log.Printf("Could not load comments: %v", err)
+	}
+
+	for _, article := range articles {
+		articleComments := comments[article.Identifier]
+
+		sort.SliceStable(articleComments, func(i, j int) bool {
+			if articleComments[i].Date == nil || articleComments[j].Date == nil {
+				return articleComments[j].Date != nil
+			}
+			return articleComments[i].Date.Before(*articleComments[j].Date)
+		})
+
+		for index := range articleComments {
+			articleComments[index] = escapedComment(articleComments[index])
+		}
+
+		article.Comments = articleComments
+	}
+}
+
+// escapedComment HTML-escapes the commenter's name and address, dropping addresses that
+// aren't http or https links
+func escapedComment(comment post.Comment) post.Comment {
+	comment.Author = html.EscapeString(comment.Author)
+
+	if link, err := url.Parse(comment.AuthorURL); err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		comment.AuthorURL = ""
+	} else {
+		comment.AuthorURL = html.EscapeString(link.String())
+	}
+
+	return comment
+}
+
+// DirectoryComments reads comments from a directory, either as <identifier>/<comment>.yml|json
+// files the way Staticman stores them, or as a <identifier>.json list of comments
+type DirectoryComments struct {
+	Path string
+}
+
+// Comments implements CommentSource
+func (d DirectoryComments) Comments(articles post.Articles) (map[string][]post.Comment, error) {
+	comments := map[string][]post.Comment{}
+
+	for _, article := range articles {
+		var entries []map[string]interface{}
+
+		if contents, err := ioutil.ReadFile(path.Join(d.Path, article.Identifier+".json")); err == nil {
+			if err := json.Unmarshal(contents, &entries); err != nil {
+				log.Printf("Skipping comments for %v: %v", article.Identifier, err)
+			}
+		}
+
+		files, _ := ioutil.ReadDir(path.Join(d.Path, article.Identifier))
+
+		for _, file := range files {
+			fileName := path.Join(d.Path, article.Identifier, file.Name())
+			contents, err := ioutil.ReadFile(fileName)
+
+			if err != nil {
+				continue
+			}
+
+			var entry map[string]interface{}
+
+			switch path.Ext(file.Name()) {
+			case ".json":
+				err = json.Unmarshal(contents, &entry)
+			case ".yml", ".yaml":
+				err = yaml.Unmarshal(contents, &entry)
+			default:
+				continue
+			}
+
+			if err != nil {
+				log.Printf("Skipping comment %v: %v", fileName, err)
+				continue
+			}
+
+			entries = append(entries, entry)
+		}
+
+		for _, entry := range entries {
+			comments[article.Identifier] = append(comments[article.Identifier], commentFromEntry(entry))
+		}
+	}
+
+	return comments, nil
+}
+
+// commentFromEntry maps common comment field names, including Staticman defaults, to a comment
+func commentFromEntry(entry map[string]interface{}) post.Comment {
+	field := func(names ...string) string {
+		for _, name := range names {
+			if value := matterString(entry[name]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	comment := post.Comment{
+		Author:    field("name", "author"),
+		AuthorURL: field("url", "website", "author_url"),
+		Content:   renderComment(field("message", "body", "content", "comment")),
+	}
+
+	switch date := entry["date"].(type) {
+	case float64:
+		timestamp := time.Unix(int64(date), 0)
+		comment.Date = &timestamp
+	case int:
+		timestamp := time.Unix(int64(date), 0)
+		comment.Date = &timestamp
+	case string:
+		if seconds, err := strconv.ParseInt(date, 10, 64); err == nil {
+			timestamp := time.Unix(seconds, 0)
+			comment.Date = &timestamp
+		} else {
+			comment.Date = matterTime(date)
+		}
+	default:
+		comment.Date = matterTime(date)
+	}
+
+	return comment
+}
+
+// GitHubComments reads comments from issues of a GitHub repository. Issues are matched
+// to articles by title, which is the article path or identifier, the same way utterances maps them.
+type GitHubComments struct {
+	Repository string
+	Token      string
+}
+
+type gitHubIssue struct {
+	Title       string `json:"title"`
+	CommentsURL string `json:"comments_url"`
+	Comments    int    `json:"comments"`
+}
+
+type gitHubComment struct {
+	User struct {
+		Login   string `json:"login"`
+		HTMLURL string `json:"html_url"`
+	} `json:"user"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (g GitHubComments) get(url string, result interface{}) error {
+	request, err := http.NewRequest("GET", url, nil)
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/vnd.github+json")
+
+	if g.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, response.Status)
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// Comments implements CommentSource
+func (g GitHubComments) Comments(articles post.Articles) (map[string][]post.Comment, error) {
+	identifiers := map[string]string{}
+
+	for _, article := range articles {
+		identifiers[article.Identifier] = article.Identifier
+		identifiers[strings.Trim(article.FullPath(), "/")] = article.Identifier
+	}
+
+	comments := map[string][]post.Comment{}
+
+	for page := 1; ; page++ {
+		var issues []gitHubIssue
+
+		issuesURL := fmt.Sprintf("https://api.github.com/repos/%s/issues?state=all&per_page=100&page=%d", g.Repository, page)
+
+		if err := g.get(issuesURL, &issues); err != nil {
+			return comments, err
+		}
+
+		for _, issue := range issues {
+			identifier, found := identifiers[strings.Trim(issue.Title, "/ ")]
+
+			if !found || issue.Comments == 0 {
+				continue
+			}
+
+			var issueComments []gitHubComment
+
+			if err := g.get(issue.CommentsURL+"?per_page=100", &issueComments); err != nil {
+				return comments, err
+			}
+
+			for _, issueComment := range issueComments {
+				created := issueComment.CreatedAt
+
+				comments[identifier] = append(comments[identifier], post.Comment{
+					Author:    issueComment.User.Login,
+					AuthorURL: issueComment.User.HTMLURL,
+					Content:   renderComment(issueComment.Body),
+					Date:      &created,
+				})
+			}
+		}
+
+		if len(issues) < 100 {
+			break
+		}
+	}
+
+	return comments, nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"gopkg.in/yaml.v3"
+
+	"macbirdie.net/blogger/funcs"
+	"macbirdie.net/blogger/post"
+)
+
+// defaultConfigFileNames are looked for in the working directory when -config is not given
+var defaultConfigFileNames = []string{"blogger.toml", "blogger.yaml", "blogger.yml", "blogger.json"}
+
+var defaultDateFormats = append([]string{}, post.DateFormats...)
+
+// siteConfig holds all values of the configuration file for the site being built, including keys
+// that are not flags
+var siteConfig = map[string]interface{}{}
+
+// sharedConfig holds the top-level values of a workspace configuration, shared by its sites
+var sharedConfig = map[string]interface{}{}
+
+// commandLineFlags are flags given on the command line, which take precedence over the configuration file
+var commandLineFlags = map[string]bool{}
+
+// configFileName returns the configuration file in use, if any
+func configFileName() string {
+	if *configPath != "" {
+		return *configPath
+	}
+
+	for _, name := range defaultConfigFileNames {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// configValue turns a configuration value into a flag value. Lists are joined the way
+// the flag expects them, tables become key=value lists.
+func configValue(name string, value interface{}) string {
+	separator := ","
+
+	if name == "date-formats" {
+		separator = "|"
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return strings.Join(values, separator)
+	case map[string]interface{}:
+		var values []string
+		for key, item := range v {
+			values = append(values, key+"="+fmt.Sprint(item))
+		}
+		sort.Strings(values)
+		return strings.Join(values, separator)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// workspaceSites are site sections of a workspace configuration, each applied over the shared
+// top-level settings when its site is built
+var workspaceSites []map[string]interface{}
+
+// loadConfig reads the configuration file, whose keys are flag names, and sets flags that were not
+// given on the command line. A workspace configuration lists several sites under a sites key, the
+// first of which is applied until another one is selected.
+func loadConfig() error {
+	if len(commandLineFlags) == 0 {
+		flag.Visit(func(f *flag.Flag) { commandLineFlags[f.Name] = true })
+	}
+
+	fileName := configFileName()
+
+	if fileName == "" {
+		return nil
+	}
+
+	contents, err := ioutil.ReadFile(fileName)
+
+	if err != nil {
+		return err
+	}
+
+	config := map[string]interface{}{}
+
+	switch path.Ext(fileName) {
+	case ".toml":
+		err = toml.Unmarshal(contents, &config)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(contents, &config)
+	case ".json":
+		err = json.Unmarshal(contents, &config)
+	default:
+		err = fmt.Errorf("unknown configuration format %q", path.Ext(fileName))
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %v", fileName, err)
+	}
+
+	workspaceSites = nil
+
+	if sites, found := config["sites"]; found {
+		delete(config, "sites")
+
+		list, _ := sites.([]interface{})
+
+		if maps, ok := sites.([]map[string]interface{}); ok {
+			for _, site := range maps {
+				list = append(list, site)
+			}
+		}
+
+		for _, site := range list {
+			siteMap, ok := site.(map[string]interface{})
+
+			if !ok {
+				return fmt.Errorf("%s: sites has to be a list of tables", fileName)
+			}
+
+			workspaceSites = append(workspaceSites, siteMap)
+		}
+	}
+
+	sharedConfig = config
+	siteConfig = config
+	applyConfig(fileName, config)
+
+	if len(workspaceSites) > 0 {
+		selectSite(0)
+	}
+
+	return nil
+}
+
+// applyConfig sets flags that were not given on the command line from configuration values,
+// resetting the ones missing from the configuration to their defaults
+func applyConfig(fileName string, config map[string]interface{}) {
+	flag.VisitAll(func(f *flag.Flag) {
+		if commandLineFlags[f.Name] || f.Name == "config" {
+			return
+		}
+
+		value := f.DefValue
+
+		if configured, found := config[f.Name]; found {
+			value = configValue(f.Name, configured)
+		}
+
+		if err := f.Value.Set(value); err != nil {
+			log.Printf("Invalid %v in %v: %v", f.Name, fileName, err)
+		}
+	})
+}
+
+// selectSite applies the settings of a workspace site over the shared ones
+func selectSite(index int) {
+	merged := map[string]interface{}{}
+
+	for key, value := range sharedConfig {
+		merged[key] = value
+	}
+
+	for key, value := range workspaceSites[index] {
+		merged[key] = value
+	}
+
+	siteConfig = merged
+	applyConfig(configFileName(), merged)
+	configure()
+	configureDrafts()
+}
+
+// forEachSite runs an action for every site of the workspace, or once without a workspace
+func forEachSite(action func()) {
+	if len(workspaceSites) == 0 {
+		action()
+		return
+	}
+
+	for index := range workspaceSites {
+		selectSite(index)
+		action()
+	}
+}
+
+// configure applies settings derived from flags, again whenever the configuration is reloaded
+func configure() {
+	configureTimezone()
+
+	post.DateFormats = append([]string{}, defaultDateFormats...)
+
+	for _, layout := range strings.Split(*dateFormats, "|") {
+		if layout = strings.TrimSpace(layout); layout != "" {
+			post.DateFormats = append(post.DateFormats, layout)
+		}
+	}
+
+	post.Taxonomies = nil
+
+	for _, taxonomy := range strings.Split(*taxonomies, ",") {
+		if taxonomy = strings.TrimSpace(taxonomy); taxonomy != "" && taxonomy != "tags" {
+			post.Taxonomies = append(post.Taxonomies, taxonomy)
+		}
+	}
+
+	configureTags(*tagNormalization, *tagAliases)
+	configureHooks()
+	configureConverters()
+	configureSourceExtensions()
+	configureTypography()
+	configureLocale()
+	configureSlugs()
+	configureTypePaths()
+	configureBaseURL()
+	configureSite()
+}
+
+// timezoneConfigured tells whether a time zone was set up, so reloading the configuration with an
+// unknown one keeps it rather than stopping a running watch or serve process
+var timezoneConfigured bool
+
+// configureTimezone sets the time zone used for reading and displaying dates
+func configureTimezone() {
+	location := time.Local
+
+	if *timezone != "" {
+		var err error
+
+		if location, err = time.LoadLocation(*timezone); err != nil {
+			if !timezoneConfigured {
+				log.Fatalf("Unknown time zone %q: %v", *timezone, err)
+			}
+
+			log.Printf("Keeping time zone %v, unknown time zone %q: %v", post.Location, *timezone, err)
+			return
+		}
+	}
+
+	post.Location = location
+	funcs.Location = location
+	timezoneConfigured = true
+}
+
+// configureLocale sets the language dates are formatted in by localDate
+func configureLocale() {
+	if _, found := funcs.Locales[strings.SplitN(strings.ToLower(strings.Replace(*locale, "_", "-", -1)), "-", 2)[0]]; !found {
+		log.Printf("No date names for locale %q, using English", *locale)
+	}
+
+	funcs.Locale = *locale
+}
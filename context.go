@@ -0,0 +1,64 @@
+package main
+
+import (
+	"time"
+
+	"macbirdie.net/blogger/post"
+)
+
+// PageContext is what every template is executed with. Fields which don't apply to a kind of
+// page are left empty, so one template can render pages of all kinds:
+//
+//	Site         the site, with params from the configuration
+//	Title        the page title, including the blog title on article and tag pages
+//	BlogTitle    the blog title
+//	Home         whether the page is the home page or a site-wide feed
+//	Root         the site root URL
+//	File         the file name of a feed
+//	Articles     articles listed on an index page or in a feed
+//	Pagination   links to other pages of an index split into pages
+//	Article      the article of an article page
+//	Tags         the tag cloud of index and tag pages
+//	Tag          the tag of a tag page
+//	Taxonomy     the taxonomy of a term page, with its Term
+//	Podcast      whether the feed is the podcast feed
+//	Section      the section of a section index or feed
+//	Authors      authors of the site, in humans.txt
+//	Blogroll     blogs listed on the blogroll page
+//	CreatedTime  when the site was generated
+//	Data         files of the data directory
+//
+// Feeds lists the feeds of the page, for autodiscovery links.
+type PageContext struct {
+	Site        Site
+	Title       string
+	BlogTitle   string
+	Home        bool
+	Root        string
+	File        string
+	Articles    post.Articles
+	Pagination  *Pagination
+	Article     *post.Article
+	Tags        []TagCount
+	Tag         *post.Tag
+	Taxonomy    string
+	Term        *post.Tag
+	Podcast     bool
+	Section     string
+	Authors     []string
+	Blogroll    []BlogrollEntry
+	CreatedTime *time.Time
+	Data        map[string]interface{}
+}
+
+// pageContext returns a context with the fields shared by all pages filled in
+func pageContext(data map[string]interface{}, now time.Time) PageContext {
+	return PageContext{
+		Site:        currentSite,
+		Title:       *blogTitle,
+		BlogTitle:   *blogTitle,
+		Root:        *siteRoot,
+		CreatedTime: &now,
+		Data:        data,
+	}
+}
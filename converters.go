@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var converterCommands = flag.String("converters", "", "Commands converting other markup to HTML, as a comma-separated list of extension=command, e.g. .adoc=asciidoctor -s -o - -")
+
+// converters map source extensions to commands reading the article body on their standard
+// input and printing HTML
+var converters = map[string][]string{}
+
+// configureConverters reads -converters, so files with the listed extensions are read as posts
+func configureConverters() {
+	converters = map[string][]string{}
+
+	for _, entry := range strings.Split(*converterCommands, ",") {
+		values := strings.SplitN(entry, "=", 2)
+
+		if len(values) < 2 {
+			continue
+		}
+
+		extension := strings.TrimSpace(values[0])
+		command := strings.Fields(values[1])
+
+		if !strings.HasPrefix(extension, ".") || len(command) == 0 {
+			log.Printf("Ignoring invalid converter %q, expected .extension=command", entry)
+			continue
+		}
+
+		converters[extension] = command
+	}
+}
+
+// convertContent turns an article body into HTML with the converter of its extension
+func convertContent(extension string, content []byte) (string, error) {
+	command := converters[extension]
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+
+	if err != nil {
+		return "", fmt.Errorf("%v: %v", strings.Join(command, " "), err)
+	}
+
+	return string(output), nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+var firstImageCover = flag.Bool("first-image-cover", true, "Use the first image of articles without a cover as their image, e.g. in og:image tags and index thumbnails")
+
+var firstImagePattern = regexp.MustCompile(`(?i)<img\b[^>]*?\ssrc="([^"]+)"`)
+
+// contentURL resolves a link in the rendered content of an article to a URL including the site's
+// host, when the root has one
+func contentURL(article *post.Article, link string) string {
+	if target, err := url.Parse(link); err != nil || target.IsAbs() || strings.HasPrefix(link, "//") {
+		return link
+	}
+
+	if !strings.HasPrefix(link, "/") {
+		page := article.FullPath()
+
+		if !strings.HasSuffix(page, "/") {
+			page = path.Dir(page)
+		}
+
+		return absURL(path.Join(page, link))
+	}
+
+	if root, err := url.Parse(*siteRoot); err == nil && root.Host != "" {
+		root.Path = link
+		return root.String()
+	}
+
+	return link
+}
+
+// resolveImage sets the image of an article to its cover or, unless disabled, the first image of
+// its content. Password-protected articles have none, so their content doesn't leak into cards,
+// indexes and the API.
+func resolveImage(article *post.Article) {
+	if article.Password != "" {
+		article.Image = ""
+		return
+	}
+
+	switch {
+	case article.Cover != "":
+		article.Image = absURL(article.Cover)
+	case *firstImageCover:
+		if match := firstImagePattern.FindStringSubmatch(article.Content); match != nil && !strings.HasPrefix(match[1], "data:") {
+			article.Image = contentURL(article, html.UnescapeString(match[1]))
+		}
+	}
+}
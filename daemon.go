@@ -0,0 +1,281 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"macbirdie.net/blogger/post"
+)
+
+var controlAddress = flag.String("control", "", "Keep running and accept rebuild, status, new and deploy commands over HTTP on a unix socket path or host:port")
+var controlToken = flag.String("control-token", "", "Secret control requests over TCP must send as a Bearer token in the Authorization header, required unless -control listens on loopback")
+var deployCommand = flag.String("deploy", "", "Shell command publishing the destination, run by the deploy control command, e.g. rsync -a dest/ host:/var/www/")
+
+// buildLock serializes builds started by watched changes and control commands
+var buildLock sync.Mutex
+
+// DaemonStatus is reported by the status control command
+type DaemonStatus struct {
+	Building    bool
+	LastBuild   *time.Time `json:",omitempty"`
+	Duration    string     `json:",omitempty"`
+	Errors      int
+	LastDeploy  *time.Time `json:",omitempty"`
+	DeployError string     `json:",omitempty"`
+}
+
+var daemonStatus struct {
+	sync.Mutex
+	DaemonStatus
+}
+
+// currentStatus returns a copy of the daemon status
+func currentStatus() DaemonStatus {
+	daemonStatus.Lock()
+	defer daemonStatus.Unlock()
+
+	return daemonStatus.DaemonStatus
+}
+
+// lockedBuild runs a build holding the build lock, recording its outcome for the status command.
+// Sites built together share the pages fetched during the build.
+func lockedBuild(run func()) {
+	buildLock.Lock()
+	defer buildLock.Unlock()
+
+	resetFetchedPages()
+
+	daemonStatus.Lock()
+	daemonStatus.Building = true
+	daemonStatus.Unlock()
+
+	start := time.Now()
+	run()
+
+	buildErrors.Lock()
+	errorCount := len(buildErrors.errors)
+	buildErrors.Unlock()
+
+	daemonStatus.Lock()
+	daemonStatus.Building = false
+	daemonStatus.LastBuild = &start
+	daemonStatus.Duration = time.Since(start).Round(time.Millisecond).String()
+	daemonStatus.Errors = errorCount
+	daemonStatus.Unlock()
+}
+
+// newPost creates a post source dated today in the first posts directory, returning its file name
+func newPost(title, articleType, tags string) (string, error) {
+	if title == "" {
+		return "", fmt.Errorf("a title is required")
+	}
+
+	if articleType == "" {
+		articleType = string(post.Post)
+	}
+
+	for name, value := range map[string]string{"title": title, "type": articleType, "tags": tags} {
+		if strings.IndexFunc(value, unicode.IsControl) >= 0 {
+			return "", fmt.Errorf("the %s can't contain line breaks or control characters", name)
+		}
+	}
+
+	slug := slugify(title, *slugScheme != "unicode")
+
+	if slug == "" {
+		return "", fmt.Errorf("title %q has no letters to name the file after", title)
+	}
+
+	now := time.Now().In(post.Location)
+	postDir := strings.TrimSpace(strings.Split(*postsPath, ",")[0])
+	fileName := path.Join(postDir, now.Format("2006-01-02")+"-"+slug+".md")
+
+	if _, err := os.Stat(fileName); err == nil {
+		return "", fmt.Errorf("%v already exists", fileName)
+	}
+
+	frontMatter := fmt.Sprintf("---\ntitle: %s\ntype: %s\ndate: %s\n", title, strings.Title(strings.ToLower(articleType)), now.Format(post.DefaultDateFormat))
+
+	if tags != "" {
+		frontMatter += "tags: " + tags + "\n"
+	}
+
+	frontMatter += "---\n"
+
+	return fileName, ioutil.WriteFile(fileName, []byte(frontMatter), 0644)
+}
+
+// deploy runs the deploy command on the built destination
+func deploy() (string, error) {
+	if *deployCommand == "" {
+		return "", fmt.Errorf("no -deploy command is configured")
+	}
+
+	buildLock.Lock()
+	output, err := exec.Command("sh", "-c", *deployCommand).CombinedOutput()
+	buildLock.Unlock()
+
+	now := time.Now()
+
+	daemonStatus.Lock()
+	daemonStatus.LastDeploy = &now
+	daemonStatus.DeployError = ""
+
+	if err != nil {
+		daemonStatus.DeployError = err.Error()
+	}
+
+	daemonStatus.Unlock()
+
+	return string(output), err
+}
+
+// writeJSON responds with a value as JSON
+func writeJSON(writer http.ResponseWriter, status int, value interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(value)
+}
+
+// isLoopbackHost tells whether a host, with or without a port, names the local machine
+func isLoopbackHost(host string) bool {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// authorizedControl guards control commands against web pages. Browsers send an Origin with
+// cross-origin and scripted requests, which command line clients don't, so those are refused.
+// TCP requests need the -control-token, or a loopback Host when no token is configured, which
+// also keeps DNS rebinding out.
+func authorizedControl(handler http.Handler, tcp bool) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Origin") != "" {
+			writeJSON(writer, http.StatusForbidden, map[string]string{"error": "requests from web pages are not accepted"})
+			return
+		}
+
+		if tcp && *controlToken != "" {
+			given := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+
+			if subtle.ConstantTimeCompare([]byte(given), []byte(*controlToken)) != 1 {
+				writeJSON(writer, http.StatusUnauthorized, map[string]string{"error": "missing or wrong control token"})
+				return
+			}
+		} else if tcp && !isLoopbackHost(request.Host) {
+			writeJSON(writer, http.StatusForbidden, map[string]string{"error": "control commands are accepted on loopback only"})
+			return
+		}
+
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+// removeStaleSocket removes a unix socket left behind by an earlier run, leaving other files alone
+func removeStaleSocket(fileName string) {
+	if info, err := os.Lstat(fileName); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(fileName)
+	}
+}
+
+// controlHandler routes control commands, which change something only when posted
+func controlHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	posted := func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			if request.Method != http.MethodPost {
+				writeJSON(writer, http.StatusMethodNotAllowed, map[string]string{"error": "use POST"})
+				return
+			}
+
+			handler(writer, request)
+		}
+	}
+
+	mux.HandleFunc("/status", func(writer http.ResponseWriter, request *http.Request) {
+		writeJSON(writer, http.StatusOK, currentStatus())
+	})
+
+	mux.HandleFunc("/rebuild", posted(func(writer http.ResponseWriter, request *http.Request) {
+		log.Println("Rebuilding on request")
+		lockedBuild(func() { forEachSite(build) })
+		writeJSON(writer, http.StatusOK, currentStatus())
+	}))
+
+	mux.HandleFunc("/new", posted(func(writer http.ResponseWriter, request *http.Request) {
+		buildLock.Lock()
+		fileName, err := newPost(request.FormValue("title"), request.FormValue("type"), request.FormValue("tags"))
+		buildLock.Unlock()
+
+		if err != nil {
+			writeJSON(writer, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		log.Printf("Created %v", fileName)
+		writeJSON(writer, http.StatusCreated, map[string]string{"file": fileName})
+	}))
+
+	mux.HandleFunc("/deploy", posted(func(writer http.ResponseWriter, request *http.Request) {
+		output, err := deploy()
+
+		if err != nil {
+			writeJSON(writer, http.StatusInternalServerError, map[string]string{"error": err.Error(), "output": output})
+			return
+		}
+
+		log.Println("Deployed")
+		writeJSON(writer, http.StatusOK, map[string]string{"output": output})
+	}))
+
+	return mux
+}
+
+// serveControl accepts control commands until the process ends. Addresses containing a slash are
+// unix sockets, replacing a socket left behind by an earlier run. TCP addresses other than
+// loopback ones need a -control-token.
+func serveControl() {
+	network := "tcp"
+
+	if strings.Contains(*controlAddress, "/") {
+		network = "unix"
+		removeStaleSocket(*controlAddress)
+	} else if host, _, err := net.SplitHostPort(*controlAddress); err == nil && !isLoopbackHost(host) && *controlToken == "" {
+		log.Fatalf("Control commands on %v need a -control-token, or listen on a loopback address or a unix socket", *controlAddress)
+	}
+
+	listener, err := net.Listen(network, *controlAddress)
+
+	if err != nil {
+		log.Fatalf("Could not listen for control commands on %v: %v", *controlAddress, err)
+	}
+
+	log.Printf("Accepting control commands on %v", *controlAddress)
+	signalReady()
+
+	if err := http.Serve(listener, authorizedControl(controlHandler(), network == "tcp")); err != nil {
+		log.Fatalf("Control server stopped: %v", err)
+	}
+}
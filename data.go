@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadData reads all data files from the data directory and returns them keyed by
+// file name without extension, ready to be exposed to templates as .Data.<name>
+func loadData() map[string]interface{} {
+	data := map[string]interface{}{}
+
+	files, dirErr := ioutil.ReadDir(*dataPath)
+
+	if dirErr != nil {
+		if !os.IsNotExist(dirErr) {
+			log.Printf("Could not read data directory %v: %v", *dataPath, dirErr)
+		}
+		return data
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		ext := path.Ext(file.Name())
+		name := strings.TrimSuffix(file.Name(), ext)
+		fileName := path.Join(*dataPath, file.Name())
+
+		contents, readErr := ioutil.ReadFile(fileName)
+
+		if readErr != nil {
+			log.Printf("Skipping data file %v due to error: %v", fileName, readErr)
+			continue
+		}
+
+		var value interface{}
+		var parseErr error
+
+		switch ext {
+		case ".yaml", ".yml":
+			parseErr = yaml.Unmarshal(contents, &value)
+		case ".json":
+			parseErr = json.Unmarshal(contents, &value)
+		case ".toml":
+			var table map[string]interface{}
+			parseErr = toml.Unmarshal(contents, &table)
+			value = table
+		default:
+			continue
+		}
+
+		if parseErr != nil {
+			log.Printf("Skipping data file %v due to parse error: %v", fileName, parseErr)
+			continue
+		}
+
+		data[name] = value
+	}
+
+	return data
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"macbirdie.net/blogger/post"
+)
+
+// ArticleRecord is an article in the exported article database
+type ArticleRecord struct {
+	Identifier  string              `json:"identifier"`
+	Source      string              `json:"source"`
+	Type        string              `json:"type"`
+	Title       string              `json:"title"`
+	Author      string              `json:"author,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Date        *time.Time          `json:"date"`
+	Updated     *time.Time          `json:"updated,omitempty"`
+	Path        string              `json:"path"`
+	URL         string              `json:"url"`
+	Section     string              `json:"section,omitempty"`
+	Draft       bool                `json:"draft"`
+	Unlisted    bool                `json:"unlisted"`
+	Tags        []string            `json:"tags"`
+	Taxonomies  map[string][]string `json:"taxonomies,omitempty"`
+	Words       int                 `json:"words"`
+	Links       []string            `json:"links"`
+	FrontMatter map[string]string   `json:"frontMatter"`
+}
+
+// csvColumns are the columns of the CSV export, lists being joined with semicolons
+var csvColumns = []string{"identifier", "source", "type", "title", "author", "date", "updated", "path", "url", "section", "draft", "unlisted", "tags", "words", "links"}
+
+// contentLinks lists the targets of all links in rendered content, in order
+func contentLinks(content string) []string {
+	links := []string{}
+
+	for _, tag := range anchorTagPattern.FindAllString(content, -1) {
+		if match := hrefPattern.FindStringSubmatch(tag); match != nil {
+			links = append(links, match[1])
+		}
+	}
+
+	return links
+}
+
+// articleRecord describes an article with its computed path, word count and links. The password
+// of protected articles is left out, and so are the word count and links of their content.
+func articleRecord(article *post.Article) ArticleRecord {
+	record := ArticleRecord{
+		Identifier:  article.Identifier,
+		Source:      article.Source,
+		Type:        string(article.Type),
+		Title:       article.Title,
+		Author:      article.Author,
+		Description: article.Description,
+		Date:        article.DateModified,
+		Updated:     article.DateUpdated,
+		Path:        sitePath(article.FullPath()),
+		URL:         articleURL(article),
+		Section:     article.Section,
+		Draft:       article.Draft,
+		Unlisted:    article.Unlisted,
+		Tags:        []string{},
+		Links:       []string{},
+		FrontMatter: map[string]string{},
+	}
+
+	for key, value := range article.FrontMatter {
+		if key != "password" {
+			record.FrontMatter[key] = value
+		}
+	}
+
+	// the content of protected articles is only published encrypted
+	if article.Password == "" {
+		record.Words = len(pageWords([]byte(article.Content)))
+		record.Links = contentLinks(article.Content)
+	}
+
+	if article.Undated {
+		record.Date = nil
+	}
+
+	for _, tag := range article.Tags {
+		record.Tags = append(record.Tags, tag.OriginalName)
+	}
+
+	for taxonomy, terms := range article.Taxonomies {
+		if record.Taxonomies == nil {
+			record.Taxonomies = map[string][]string{}
+		}
+
+		for _, term := range terms {
+			record.Taxonomies[taxonomy] = append(record.Taxonomies[taxonomy], term.OriginalName)
+		}
+	}
+
+	return record
+}
+
+// formatRecordDate formats an optional date for CSV
+func formatRecordDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+
+	return date.Format(post.DefaultDateFormat)
+}
+
+// writeRecordsCSV writes records as CSV with a header row
+func writeRecordsCSV(output io.Writer, records []ArticleRecord) error {
+	writer := csv.NewWriter(output)
+	writer.Write(csvColumns)
+
+	for _, record := range records {
+		writer.Write([]string{
+			record.Identifier, record.Source, record.Type, record.Title, record.Author,
+			formatRecordDate(record.Date), formatRecordDate(record.Updated), record.Path, record.URL, record.Section,
+			strconv.FormatBool(record.Draft), strconv.FormatBool(record.Unlisted),
+			strings.Join(record.Tags, ";"), strconv.Itoa(record.Words), strings.Join(record.Links, ";"),
+		})
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// exportDatabase dumps all parsed articles as JSON or CSV, drafts only when asked for
+func exportDatabase(format string, args []string) {
+	databaseFlags := flag.NewFlagSet("export "+format, flag.ExitOnError)
+	output := databaseFlags.String("output", "-", "Output file, - for the standard output")
+	includeDrafts := databaseFlags.Bool("drafts", false, "Include drafts")
+	databaseFlags.Parse(args)
+
+	configureDrafts()
+
+	articles := readArticles(findSourceFiles(), loadShortcodes(templateFuncs()), markdownRenderer(0))
+	sort.Sort(articles)
+
+	records := []ArticleRecord{}
+
+	for _, article := range articles {
+		if article.Draft && !*includeDrafts {
+			continue
+		}
+
+		records = append(records, articleRecord(article))
+	}
+
+	var writer io.Writer = os.Stdout
+
+	if *output != "-" {
+		file, err := os.Create(*output)
+
+		if err != nil {
+			log.Fatalf("Could not create %v: %v", *output, err)
+		}
+
+		defer file.Close()
+		writer = file
+	}
+
+	var err error
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(records)
+	case "csv":
+		err = writeRecordsCSV(writer, records)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+
+	if err != nil {
+		log.Fatalf("Could not export articles: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+)
+
+var dedupeMedia = flag.Bool("dedupe-media", false, "Hard link identical static files, bundle assets and attachments to a single copy in the destination")
+
+// mediaCopies maps content hashes of copied files to their first copy, and copies to their hashes.
+// Copies of scrubbed images differ from their source, so the hashes of the copies themselves are
+// kept for the manifest.
+var mediaCopies struct {
+	sync.Mutex
+	byHash  map[string]string
+	byPath  map[string]string
+	outputs map[string]string
+}
+
+// resetMediaCopies forgets the copies of an earlier build
+func resetMediaCopies() {
+	mediaCopies.Lock()
+	mediaCopies.byHash = map[string]string{}
+	mediaCopies.byPath = map[string]string{}
+	mediaCopies.outputs = map[string]string{}
+	mediaCopies.Unlock()
+}
+
+// fileHash returns the SHA-256 hash of a file's content
+func fileHash(fileName string) (string, error) {
+	file, err := os.Open(fileName)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// linkDuplicate replaces destination with a hard link to an earlier copy of the same content,
+// telling whether there was one to link to. Failing links, like ones across file systems, are
+// left to copying.
+func linkDuplicate(source, destination, hash string) bool {
+	mediaCopies.Lock()
+	original, found := mediaCopies.byHash[hash]
+	outputHash := mediaCopies.outputs[original]
+	mediaCopies.Unlock()
+
+	if !found || original == destination {
+		return false
+	}
+
+	temporary, err := ioutil.TempFile(path.Dir(destination), "."+path.Base(destination)+".*")
+
+	if err != nil {
+		return false
+	}
+
+	temporary.Close()
+	os.Remove(temporary.Name())
+
+	if err := os.Link(original, temporary.Name()); err != nil {
+		return false
+	}
+
+	if err := os.Rename(temporary.Name(), destination); err != nil {
+		os.Remove(temporary.Name())
+		return false
+	}
+
+	recordOutput(destination, source, outputHash)
+	rememberCopy(destination, hash, outputHash)
+
+	return true
+}
+
+// rememberCopy keeps the first copy of content, for later duplicates to link to. A copy replacing
+// a file with other content no longer stands for that content.
+func rememberCopy(destination, hash, outputHash string) {
+	mediaCopies.Lock()
+	defer mediaCopies.Unlock()
+
+	if mediaCopies.byHash == nil {
+		return
+	}
+
+	if previous, found := mediaCopies.byPath[destination]; found && previous != hash && mediaCopies.byHash[previous] == destination {
+		delete(mediaCopies.byHash, previous)
+	}
+
+	mediaCopies.byPath[destination] = hash
+	mediaCopies.outputs[destination] = outputHash
+
+	if _, found := mediaCopies.byHash[hash]; !found {
+		mediaCopies.byHash[hash] = destination
+	}
+}
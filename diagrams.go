@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"html"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// diagramCommand returns the build-time rendering command for a diagram language
+func diagramCommand(language string) string {
+	switch language {
+	case "dot", "graphviz":
+		return *dotCommand
+	case "mermaid":
+		return *mermaidCommand
+	}
+
+	return ""
+}
+
+// renderDiagram writes markup for mermaid and dot code blocks. With a command configured
+// for the language, the diagram is rendered to SVG at build time, the command reading
+// the diagram source from standard input and writing SVG to standard output. Otherwise
+// the source is left in a container for client-side rendering.
+func renderDiagram(out *bytes.Buffer, text []byte, info string) bool {
+	if !*diagrams {
+		return false
+	}
+
+	language := ""
+
+	if fields := strings.Fields(info); len(fields) > 0 {
+		language = fields[0]
+	}
+
+	if language != "mermaid" && language != "dot" && language != "graphviz" {
+		return false
+	}
+
+	if language == "graphviz" {
+		language = "dot"
+	}
+
+	if commandLine := diagramCommand(language); commandLine != "" {
+		command := exec.Command("sh", "-c", commandLine)
+		command.Stdin = bytes.NewReader(text)
+		command.Stderr = os.Stderr
+
+		svg, err := command.Output()
+
+		if err == nil {
+			out.WriteString(`<figure class="diagram diagram-` + language + `">`)
+			out.Write(bytes.TrimSpace(svg))
+			out.WriteString("</figure>\n")
+			return true
+		}
+
+		log.Printf("Could not render %s diagram, leaving it for the client: %v", language, err)
+	}
+
+	out.WriteString(`<pre class="` + language + `">`)
+	out.WriteString(html.EscapeString(string(text)))
+	out.WriteString("</pre>\n")
+
+	return true
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+const draftsTokenFileName = ".blogger-drafts-token"
+
+var noindexMeta = []byte(`<meta name="robots" content="noindex, nofollow">`)
+
+// configureDrafts sets the path drafts are written to, optionally hidden behind a token.
+// A "random" token is generated once and kept in a file in the working directory,
+// so draft links stay valid between runs.
+func configureDrafts() {
+	token := *draftsToken
+
+	if token == "random" {
+		stored, err := ioutil.ReadFile(draftsTokenFileName)
+		token = strings.TrimSpace(string(stored))
+
+		if err != nil || token == "" {
+			tokenBytes := make([]byte, 16)
+			rand.Read(tokenBytes)
+			token = hex.EncodeToString(tokenBytes)
+
+			if writeErr := ioutil.WriteFile(draftsTokenFileName, []byte(token+"\n"), 0600); writeErr != nil {
+				log.Printf("Could not store drafts token: %v", writeErr)
+			}
+		}
+	}
+
+	if token != "" {
+		post.DraftsPath = "drafts-" + token
+	}
+}
+
+// articleDestination returns the directory an article is written to, keeping drafts apart when requested
+func articleDestination(destinationDir string, article *post.Article) string {
+	if article.Draft && *draftsDestination != "" {
+		return *draftsDestination
+	}
+
+	return destinationDir
+}
+
+// injectNoindex adds a robots noindex meta tag to a rendered page, in its head when there is one
+func injectNoindex(page []byte) []byte {
+	return injectIntoHead(page, noindexMeta)
+}
+
+// injectIntoHead adds a tag to the head of a rendered page, or before it when it has no head
+func injectIntoHead(page, tag []byte) []byte {
+	if bytes.Contains(page, tag) {
+		return page
+	}
+
+	headEnd := bytes.Index(bytes.ToLower(page), []byte("</head>"))
+
+	if headEnd < 0 {
+		return append(append(append([]byte{}, tag...), '\n'), page...)
+	}
+
+	var result bytes.Buffer
+	result.Write(page[:headEnd])
+	result.Write(tag)
+	result.WriteString("\n")
+	result.Write(page[headEnd:])
+
+	return result.Bytes()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/kyokomi/emoji/v2"
+)
+
+var emojiPattern = regexp.MustCompile(`:[a-z0-9_+\-]+:`)
+
+// skippedEmojiElements are elements whose text is left as written
+var skippedEmojiElements = []string{"code", "pre", "kbd", "samp", "script", "style"}
+
+// emojiReplacer returns a function replacing a known shortcode with its Unicode character,
+// or with an image when the -emoji flag is image:<url pattern>, %s in the pattern
+// standing for the shortcode name
+func emojiReplacer(mode string) func(string) string {
+	codes := emoji.CodeMap()
+
+	return func(shortcode string) string {
+		character, found := codes[shortcode]
+
+		if !found {
+			return shortcode
+		}
+
+		if !strings.HasPrefix(mode, "image:") {
+			return character
+		}
+
+		name := strings.Trim(shortcode, ":")
+		source := fmt.Sprintf(strings.TrimPrefix(mode, "image:"), name)
+
+		return fmt.Sprintf(`<span class="emoji" title="%[1]s"><img src="%[2]s" alt="%[3]s"></span>`,
+			html.EscapeString(shortcode), html.EscapeString(source), character)
+	}
+}
+
+// replaceEmoji converts emoji shortcodes in rendered HTML text, leaving tags, attributes and code alone
+func replaceEmoji(content string, mode string) string {
+	if mode == "" || !strings.Contains(content, ":") {
+		return content
+	}
+
+	replace := emojiReplacer(mode)
+
+	var result strings.Builder
+	skipping := ""
+
+	for len(content) > 0 {
+		tagStart := strings.IndexByte(content, '<')
+
+		if tagStart < 0 {
+			tagStart = len(content)
+		}
+
+		text := content[:tagStart]
+
+		if skipping == "" {
+			text = emojiPattern.ReplaceAllStringFunc(text, replace)
+		}
+
+		result.WriteString(text)
+		content = content[tagStart:]
+
+		if len(content) == 0 {
+			break
+		}
+
+		tagEnd := strings.IndexByte(content, '>')
+
+		if tagEnd < 0 {
+			result.WriteString(content)
+			break
+		}
+
+		tag := content[:tagEnd+1]
+		result.WriteString(tag)
+		content = content[tagEnd+1:]
+
+		tagName := ""
+
+		if fields := strings.Fields(strings.Trim(tag, "<>/")); len(fields) > 0 {
+			tagName = strings.ToLower(fields[0])
+		}
+
+		if skipping == "" && !strings.HasPrefix(tag, "</") && containsString(skippedEmojiElements, tagName) {
+			skipping = tagName
+		} else if skipping != "" && strings.HasPrefix(tag, "</") && tagName == skipping {
+			skipping = ""
+		}
+	}
+
+	return result.String()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"path"
+	"text/template"
+
+	"macbirdie.net/blogger/post"
+)
+
+const podcastTemplateFileName = "podcasttemplate.html"
+
+// resolveEnclosure fills in the length and type of an enclosure from its local file and extension
+func resolveEnclosure(article *post.Article, articleDir string) {
+	enclosure := article.Enclosure
+
+	if enclosure == nil {
+		return
+	}
+
+	if enclosure.Type == "" {
+		enclosure.Type = mime.TypeByExtension(path.Ext(enclosure.URL))
+	}
+
+	if enclosure.Length == 0 {
+		if fileName := localFile(enclosure.URL, articleDir); fileName != "" {
+			if info, err := os.Stat(fileName); err == nil {
+				enclosure.Length = info.Size()
+			}
+		}
+	}
+
+	if enclosure.Length == 0 || enclosure.Type == "" {
+		log.Printf("Enclosure of %v is missing its length or type, which feed readers require", article.Identifier)
+	}
+}
+
+// enclosureTag returns the RSS enclosure element of an article, empty for articles without one
+func enclosureTag(article *post.Article) string {
+	if article.Enclosure == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(`<enclosure url="%s" length="%d" type="%s"/>`,
+		xmlEscape(absURL(article.Enclosure.URL)), article.Enclosure.Length, xmlEscape(article.Enclosure.Type))
+}
+
+// itunesTags returns iTunes podcast item elements of an article. Feeds using them have to
+// declare xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" on the rss element.
+func itunesTags(article *post.Article) string {
+	if article.Enclosure == nil {
+		return ""
+	}
+
+	var tags bytes.Buffer
+
+	fmt.Fprintf(&tags, "<itunes:title>%s</itunes:title>", xmlEscape(article.Title))
+
+	if article.Enclosure.Duration != "" {
+		fmt.Fprintf(&tags, "<itunes:duration>%s</itunes:duration>", xmlEscape(article.Enclosure.Duration))
+	}
+
+	fmt.Fprintf(&tags, "<itunes:explicit>%t</itunes:explicit>", article.Enclosure.Explicit)
+
+	if article.Description != "" {
+		fmt.Fprintf(&tags, "<itunes:summary>%s</itunes:summary>", xmlEscape(article.Description))
+	}
+
+	return tags.String()
+}
+
+// writePodcastFeed writes a feed of articles with enclosures, using the podcast template
+// when there is one and the RSS template otherwise
+func writePodcastFeed(destinationDir string, funcMap template.FuncMap, rssTemplate *template.Template, articles post.Articles, context PageContext) {
+	feedTemplate := rssTemplate
+
+	if _, err := os.Stat(path.Join(*templatesPath, podcastTemplateFileName)); err == nil {
+		podcastTemplate, err := template.New(podcastTemplateFileName).Funcs(funcMap).ParseFiles(path.Join(*templatesPath, podcastTemplateFileName))
+
+		if err != nil {
+			log.Printf("Could not parse podcast template, using the RSS template: %v", err)
+		} else {
+			feedTemplate = podcastTemplate
+		}
+	}
+
+	var episodes post.Articles
+
+	for _, article := range articles {
+		if article.Enclosure != nil {
+			episodes = append(episodes, article)
+		}
+	}
+
+	context.Articles = episodes
+	context.File = *podcastFeed
+	context.Podcast = true
+
+	executeToFile(path.Join(destinationDir, *podcastFeed), feedTemplate, context)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"html"
+
+	"macbirdie.net/blogger/post"
+)
+
+// encryptionIterations has to match the iteration count in the decryption script
+const encryptionIterations = 210000
+
+// protectedContent is shown in place of a password-protected article. The inline script derives
+// the key with PBKDF2 and decrypts the content with AES-GCM using the browser's Web Crypto API.
+const protectedContent = `<div class="protected" data-salt="%s" data-iv="%s" data-content="%s">
+<form class="protected-form">
+<label>This post is password protected. <input type="password" autocomplete="current-password"></label>
+<button type="submit">Show</button>
+<p class="protected-error" hidden>Wrong password.</p>
+</form>
+</div>
+<script>
+(function () {
+  var container = document.currentScript.previousElementSibling;
+  var form = container.querySelector("form");
+  var decode = function (value) { return Uint8Array.from(atob(value), function (c) { return c.charCodeAt(0); }); };
+  form.addEventListener("submit", function (event) {
+    event.preventDefault();
+    var password = new TextEncoder().encode(form.querySelector("input").value);
+    crypto.subtle.importKey("raw", password, "PBKDF2", false, ["deriveKey"]).then(function (material) {
+      return crypto.subtle.deriveKey({name: "PBKDF2", salt: decode(container.dataset.salt), iterations: %d, hash: "SHA-256"},
+        material, {name: "AES-GCM", length: 256}, false, ["decrypt"]);
+    }).then(function (key) {
+      return crypto.subtle.decrypt({name: "AES-GCM", iv: decode(container.dataset.iv)}, key, decode(container.dataset.content));
+    }).then(function (plain) {
+      container.innerHTML = new TextDecoder().decode(plain);
+    }, function () {
+      form.querySelector(".protected-error").hidden = false;
+    });
+  });
+})();
+</script>
+`
+
+// encryptContent replaces an article's rendered content with its encrypted form and a decryption form
+func encryptContent(article *post.Article) error {
+	salt := make([]byte, 16)
+	iv := make([]byte, 12)
+
+	if *reproducible {
+		// the same content and password always encrypt alike, so the nonce is never reused for
+		// another plaintext
+		seed := sha256.Sum256([]byte(article.Password + "\x00" + article.Identifier + "\x00" + article.Content))
+		copy(salt, seed[:16])
+		copy(iv, seed[16:28])
+	} else {
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+
+		if _, err := rand.Read(iv); err != nil {
+			return err
+		}
+	}
+
+	key, err := pbkdf2.Key(sha256.New, article.Password, salt, encryptionIterations, 32)
+
+	if err != nil {
+		return err
+	}
+
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return err
+	}
+
+	encrypted := gcm.Seal(nil, iv, []byte(article.Content), nil)
+
+	article.Content = fmt.Sprintf(protectedContent,
+		base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(iv),
+		html.EscapeString(base64.StdEncoding.EncodeToString(encrypted)),
+		encryptionIterations)
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path"
+	"text/template"
+)
+
+// errorPages are error documents rendered from their templates, with their titles. Pages without a
+// template in the templates directory are not written.
+var errorPages = []struct {
+	Template string
+	Page     string
+	Title    string
+}{
+	{"404template.html", "404.html", "Page not found"},
+	{"500template.html", "500.html", "Server error"},
+}
+
+// isErrorPage tells whether a page relative to the destination is an error document, which
+// servers return for any path
+func isErrorPage(relative string) bool {
+	for _, errorPage := range errorPages {
+		if relative == errorPage.Page {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeErrorPages renders error documents with the standard site context
+func writeErrorPages(destinationDir string, funcMap template.FuncMap, context PageContext) {
+	for _, errorPage := range errorPages {
+		fileName := path.Join(*templatesPath, errorPage.Template)
+
+		if _, err := os.Stat(fileName); err != nil {
+			continue
+		}
+
+		errorTemplate, err := template.New(errorPage.Template).Funcs(funcMap).ParseFiles(fileName)
+
+		if err != nil {
+			log.Printf("Could not parse %v: %v", fileName, err)
+			continue
+		}
+
+		pageContext := context
+		pageContext.Title = errorPage.Title + " – " + *blogTitle
+		executeToFile(path.Join(destinationDir, errorPage.Page), errorTemplate, pageContext)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"macbirdie.net/blogger/post"
+)
+
+// SourceError is a problem with a source file, located by line when it is known
+type SourceError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *SourceError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %v", e.File, e.Err)
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// sourceError locates an error in a source file, taking the line from front matter errors
+func sourceError(fileName string, err error) *SourceError {
+	var frontMatterErr *post.FrontMatterError
+
+	if errors.As(err, &frontMatterErr) {
+		if frontMatterErr.Key != "" {
+			return &SourceError{File: fileName, Line: frontMatterErr.Line, Err: fmt.Errorf("%s: %w", frontMatterErr.Key, frontMatterErr.Err)}
+		}
+
+		return &SourceError{File: fileName, Line: frontMatterErr.Line, Err: frontMatterErr.Err}
+	}
+
+	return &SourceError{File: fileName, Err: err}
+}
+
+// buildErrors collects errors of the build in progress, summarized when it ends
+var buildErrors struct {
+	sync.Mutex
+	errors []*SourceError
+}
+
+// reportError logs an error and keeps it for the summary of the build
+func reportError(err *SourceError) {
+	log.Print(err)
+
+	buildErrors.Lock()
+	buildErrors.errors = append(buildErrors.errors, err)
+	buildErrors.Unlock()
+}
+
+// resetBuildErrors starts collecting errors of a new build
+func resetBuildErrors() {
+	buildErrors.Lock()
+	buildErrors.errors = nil
+	buildErrors.Unlock()
+}
+
+// summarizeBuildErrors lists all errors of the build once more, so they aren't lost in its log
+func summarizeBuildErrors() {
+	buildErrors.Lock()
+	defer buildErrors.Unlock()
+
+	if len(buildErrors.errors) == 0 {
+		return
+	}
+
+	log.Printf("Build finished with %d errors:", len(buildErrors.errors))
+
+	for _, err := range buildErrors.errors {
+		log.Printf("  %v", err)
+	}
+}
+
+// abortBuild ends a build that can't go on, exiting unless a snapshot is built
+var abortBuild = func(err error) {
+	log.Fatal(err)
+}
+
+// failBuild stops the build with a summary of its errors
+func failBuild(format string, args ...interface{}) {
+	summarizeBuildErrors()
+	abortBuild(fmt.Errorf(format, args...))
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path"
+	"strings"
+)
+
+var stripMetadata = flag.Bool("strip-exif", false, "Strip EXIF, GPS, XMP and other metadata from JPEG and PNG images copied into the destination")
+var keptExifFields = flag.String("exif-keep", "Orientation", "EXIF fields kept when stripping metadata (comma-separated), e.g. Orientation,Artist,Copyright")
+
+// exifTags name the main image EXIF fields which can be kept. Fields of the EXIF and GPS
+// directories, like the location and camera settings, are always stripped.
+var exifTags = map[string]uint16{
+	"ImageDescription": 0x010e,
+	"Make":             0x010f,
+	"Model":            0x0110,
+	"Orientation":      0x0112,
+	"XResolution":      0x011a,
+	"YResolution":      0x011b,
+	"ResolutionUnit":   0x0128,
+	"Software":         0x0131,
+	"DateTime":         0x0132,
+	"Artist":           0x013b,
+	"Copyright":        0x8298,
+}
+
+// exifTypeSizes are sizes of the TIFF field types in bytes
+var exifTypeSizes = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+var exifHeader = []byte("Exif\x00\x00")
+var xmpHeader = []byte("http://ns.adobe.com/xap/1.0/\x00")
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngMetadataChunks are PNG chunks holding EXIF data and text, like the creating software or location
+var pngMetadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// keptExifTags returns the tags of the fields listed in -exif-keep
+func keptExifTags() map[uint16]bool {
+	kept := map[uint16]bool{}
+
+	for _, name := range strings.Split(*keptExifFields, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+
+		if tag, found := exifTags[name]; found {
+			kept[tag] = true
+		} else {
+			log.Printf("Ignoring unknown EXIF field %q", name)
+		}
+	}
+
+	return kept
+}
+
+// filterExif rebuilds EXIF data with only the kept fields of the main image, returning nil when
+// none are left
+func filterExif(exif []byte, kept map[uint16]bool) ([]byte, error) {
+	if len(exif) < 8 {
+		return nil, errors.New("EXIF data too short")
+	}
+
+	var order binary.ByteOrder
+
+	switch string(exif[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("unknown EXIF byte order")
+	}
+
+	offset := order.Uint32(exif[4:8])
+
+	if uint64(offset)+2 > uint64(len(exif)) {
+		return nil, errors.New("EXIF directory out of bounds")
+	}
+
+	count := uint32(order.Uint16(exif[offset:]))
+
+	type field struct {
+		entry []byte
+		value []byte
+	}
+
+	var fields []field
+
+	for index := uint32(0); index < count; index++ {
+		start := offset + 2 + index*12
+
+		if uint64(start)+12 > uint64(len(exif)) {
+			return nil, errors.New("EXIF field out of bounds")
+		}
+
+		entry := append([]byte{}, exif[start:start+12]...)
+
+		if !kept[order.Uint16(entry)] {
+			continue
+		}
+
+		size := exifTypeSizes[order.Uint16(entry[2:])] * order.Uint32(entry[4:])
+
+		if size <= 4 {
+			fields = append(fields, field{entry: entry})
+			continue
+		}
+
+		valueOffset := order.Uint32(entry[8:])
+
+		if uint64(valueOffset)+uint64(size) > uint64(len(exif)) {
+			continue
+		}
+
+		fields = append(fields, field{entry: entry, value: exif[valueOffset : valueOffset+size]})
+	}
+
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	var header, values bytes.Buffer
+	header.Write(exif[:4])
+	binary.Write(&header, order, uint32(8))
+	binary.Write(&header, order, uint16(len(fields)))
+
+	valuesOffset := uint32(8 + 2 + 12*len(fields) + 4)
+
+	for _, field := range fields {
+		if field.value != nil {
+			order.PutUint32(field.entry[8:], valuesOffset+uint32(values.Len()))
+			values.Write(field.value)
+
+			// values start on word boundaries
+			if values.Len()%2 == 1 {
+				values.WriteByte(0)
+			}
+		}
+
+		header.Write(field.entry)
+	}
+
+	binary.Write(&header, order, uint32(0))
+	header.Write(values.Bytes())
+
+	return header.Bytes(), nil
+}
+
+// stripJPEGMetadata removes XMP, IPTC and comment segments of a JPEG image and filters its EXIF data
+func stripJPEGMetadata(image []byte, kept map[uint16]bool) ([]byte, error) {
+	if len(image) < 4 || image[0] != 0xff || image[1] != 0xd8 {
+		return nil, errors.New("not a JPEG image")
+	}
+
+	result := bytes.NewBuffer(append([]byte{}, image[:2]...))
+	position := 2
+
+	for position+4 <= len(image) {
+		if image[position] != 0xff {
+			return nil, errors.New("invalid JPEG segment")
+		}
+
+		marker := image[position+1]
+
+		// the entropy-coded data after the start of scan is kept as it is
+		if marker == 0xda {
+			result.Write(image[position:])
+			return result.Bytes(), nil
+		}
+
+		length := int(binary.BigEndian.Uint16(image[position+2:]))
+
+		if length < 2 || position+2+length > len(image) {
+			return nil, errors.New("JPEG segment out of bounds")
+		}
+
+		segment := image[position : position+2+length]
+		payload := segment[4:]
+		position += 2 + length
+
+		switch {
+		case marker == 0xe1 && bytes.HasPrefix(payload, exifHeader):
+			exif, err := filterExif(payload[len(exifHeader):], kept)
+
+			if err != nil || exif == nil {
+				continue
+			}
+
+			exif = append(append([]byte{}, exifHeader...), exif...)
+			result.Write([]byte{0xff, 0xe1})
+			binary.Write(result, binary.BigEndian, uint16(len(exif)+2))
+			result.Write(exif)
+		case marker == 0xe1 && bytes.HasPrefix(payload, xmpHeader), marker == 0xed, marker == 0xfe:
+		default:
+			result.Write(segment)
+		}
+	}
+
+	result.Write(image[position:])
+
+	return result.Bytes(), nil
+}
+
+// stripPNGMetadata removes EXIF, text and time chunks of a PNG image
+func stripPNGMetadata(image []byte) ([]byte, error) {
+	if !bytes.HasPrefix(image, pngSignature) {
+		return nil, errors.New("not a PNG image")
+	}
+
+	result := bytes.NewBuffer(append([]byte{}, pngSignature...))
+	position := len(pngSignature)
+
+	for position+12 <= len(image) {
+		length := int(binary.BigEndian.Uint32(image[position:]))
+
+		if length < 0 || position+12+length > len(image) {
+			return nil, errors.New("PNG chunk out of bounds")
+		}
+
+		chunk := image[position : position+12+length]
+		position += 12 + length
+
+		if !pngMetadataChunks[string(chunk[4:8])] {
+			result.Write(chunk)
+		}
+	}
+
+	return result.Bytes(), nil
+}
+
+// scrubsImage tells whether a file is an image stripped of its metadata when copied
+func scrubsImage(fileName string) bool {
+	extension := strings.ToLower(path.Ext(fileName))
+
+	return *stripMetadata && (extension == ".jpg" || extension == ".jpeg" || extension == ".png")
+}
+
+// scrubbedImage reads an image without its metadata when -strip-exif is set, telling whether
+// it applies to the file. Images which can't be read or parsed fail, so their metadata is never
+// published.
+func scrubbedImage(fileName string) ([]byte, bool, error) {
+	if !scrubsImage(fileName) {
+		return nil, false, nil
+	}
+
+	image, err := ioutil.ReadFile(fileName)
+
+	if err != nil {
+		return nil, true, err
+	}
+
+	var scrubbed []byte
+
+	if strings.ToLower(path.Ext(fileName)) == ".png" {
+		scrubbed, err = stripPNGMetadata(image)
+	} else {
+		scrubbed, err = stripJPEGMetadata(image, keptExifTags())
+	}
+
+	if err != nil {
+		return nil, true, fmt.Errorf("could not strip metadata of %v: %v", fileName, err)
+	}
+
+	return scrubbed, true, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path"
+
+	"macbirdie.net/blogger/post"
+)
+
+var expiredPages = flag.String("expired", "notice", "What becomes of pages past their expires date: keep, notice (keep with a notice) or redirect (to expired-redirect or the home page)")
+var expiredNotice = flag.String("expired-notice", "This page has expired and is kept for reference.", "Notice shown on expired pages")
+
+// expired tells whether an article is past its expiry date at build time. Watch mode only notices
+// expiry on the next rebuild.
+func expired(article *post.Article) bool {
+	return article.Expires != nil && !buildTime().Before(*article.Expires)
+}
+
+// expiredRedirect returns where an expired article redirects to, empty when it is kept
+func expiredRedirect(article *post.Article) string {
+	if !expired(article) {
+		return ""
+	}
+
+	if article.ExpiredRedirect != "" {
+		return sitePath(article.ExpiredRedirect)
+	}
+
+	if *expiredPages == "redirect" {
+		return sitePath("/")
+	}
+
+	return ""
+}
+
+// addExpiredNotice puts the expired notice above the content of an expired article kept in place
+func addExpiredNotice(article *post.Article) {
+	if *expiredPages != "notice" || !expired(article) || expiredRedirect(article) != "" {
+		return
+	}
+
+	article.Content = `<p class="expired-notice">` + html.EscapeString(*expiredNotice) + "</p>\n" + article.Content
+}
+
+// writeExpiredRedirect replaces the page of an expired article with a redirect
+func writeExpiredRedirect(destinationDir string, article *post.Article, target string) {
+	fileName := path.Join(articleDestination(destinationDir, article), article.OutputPath())
+	os.MkdirAll(path.Dir(fileName), os.ModePerm)
+
+	if writeErr := writeFile(fileName, []byte(fmt.Sprintf(redirectStub, html.EscapeString(target)))); writeErr != nil {
+		log.Printf("Could not write file %v due to error: %v", fileName, writeErr)
+	}
+}
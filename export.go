@@ -0,0 +1,342 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/russross/blackfriday"
+
+	"macbirdie.net/blogger/post"
+)
+
+const exportDateFormat = "2006-01-02"
+
+var epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+var epubPackageTemplate = template.Must(template.New("content.opf").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="bookid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="bookid">{{xml .Identifier}}</dc:identifier>
+    <dc:title>{{xml .Title}}</dc:title>
+    <dc:language>{{xml .Language}}</dc:language>
+    {{if .Author}}<dc:creator>{{xml .Author}}</dc:creator>{{end}}
+    <meta property="dcterms:modified">{{.Modified}}</meta>
+    {{if .Cover}}<meta name="cover" content="cover-image"/>{{end}}
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    {{if .Cover}}<item id="cover-image" href="{{xml .Cover}}" media-type="{{.CoverType}}" properties="cover-image"/>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>{{end}}
+    {{range .Chapters}}<item id="{{.ID}}" href="{{.ID}}.xhtml" media-type="application/xhtml+xml"/>
+    {{end}}
+  </manifest>
+  <spine toc="ncx">
+    {{if .Cover}}<itemref idref="cover"/>{{end}}
+    <itemref idref="nav"/>
+    {{range .Chapters}}<itemref idref="{{.ID}}"/>
+    {{end}}
+  </spine>
+</package>
+`))
+
+var epubNavTemplate = template.Must(template.New("nav.xhtml").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>{{xml .Title}}</title></head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>{{xml .Title}}</h1>
+    <ol>
+      {{range .Chapters}}<li><a href="{{.ID}}.xhtml">{{xml .Article.Title}}</a></li>
+      {{end}}
+    </ol>
+  </nav>
+</body>
+</html>
+`))
+
+var epubNcxTemplate = template.Must(template.New("toc.ncx").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head><meta name="dtb:uid" content="{{xml .Identifier}}"/></head>
+  <docTitle><text>{{xml .Title}}</text></docTitle>
+  <navMap>
+    {{range $chapter := .Chapters}}<navPoint id="{{$chapter.ID}}" playOrder="{{$chapter.Order}}">
+      <navLabel><text>{{xml $chapter.Article.Title}}</text></navLabel>
+      <content src="{{$chapter.ID}}.xhtml"/>
+    </navPoint>
+    {{end}}
+  </navMap>
+</ncx>
+`))
+
+var epubChapterTemplate = template.Must(template.New("chapter.xhtml").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>{{xml .Article.Title}}</title></head>
+<body>
+  <h1>{{xml .Article.Title}}</h1>
+  <p class="date">{{.Date}}</p>
+  {{.Content}}
+</body>
+</html>
+`))
+
+var epubCoverPage = `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>Cover</title></head>
+<body><img src="%s" alt="Cover"/></body>
+</html>
+`
+
+// EpubChapter is a single article compiled into an EPUB book. Order is its position in the
+// reading order, counted from 1 as NCX playOrder requires.
+type EpubChapter struct {
+	ID      string
+	Order   int
+	Article *post.Article
+	Content string
+	Date    string
+}
+
+// EpubBook holds metadata and chapters of an exported EPUB
+type EpubBook struct {
+	Identifier string
+	Title      string
+	Author     string
+	Language   string
+	Modified   string
+	Cover      string
+	CoverType  string
+	Chapters   []EpubChapter
+}
+
+func xmlEscape(value string) string {
+	var buffer bytes.Buffer
+	xml.EscapeText(&buffer, []byte(value))
+	return buffer.String()
+}
+
+// selectExportArticles returns published posts and snippets matching the export filters, oldest first
+func selectExportArticles(articles post.Articles, tag, series string, from, to *time.Time) post.Articles {
+	var selected post.Articles
+
+	for _, article := range articles {
+		if article.Draft || article.Unlisted || expired(article) || article.Type == post.Page || article.Password != "" {
+			continue
+		}
+
+		if tag != "" && !article.HasTag(strings.ToLower(tag)) {
+			continue
+		}
+
+		if series != "" && article.Series != series {
+			continue
+		}
+
+		if from != nil && article.DateModified.Before(*from) {
+			continue
+		}
+
+		if to != nil && !article.DateModified.Before(*to) {
+			continue
+		}
+
+		selected = append(selected, article)
+	}
+
+	sort.Sort(sort.Reverse(selected))
+
+	return selected
+}
+
+func parseExportDate(value string, name string) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	date, err := time.ParseInLocation(exportDateFormat, value, post.Location)
+
+	if err != nil {
+		log.Fatalf("Invalid -%s date %q, expected YYYY-MM-DD", name, value)
+	}
+
+	return &date
+}
+
+// writeEpub packs a book into an EPUB file
+func writeEpub(book EpubBook, coverPath string, outputPath string) error {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+
+	// mimetype has to be the first, uncompressed entry
+	mimetype, err := archive.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+
+	if err != nil {
+		return err
+	}
+
+	mimetype.Write([]byte("application/epub+zip"))
+
+	files := map[string]func() ([]byte, error){
+		"META-INF/container.xml": func() ([]byte, error) { return []byte(epubContainer), nil },
+		"OEBPS/content.opf":      func() ([]byte, error) { return executeToBytes(epubPackageTemplate, book) },
+		"OEBPS/nav.xhtml":        func() ([]byte, error) { return executeToBytes(epubNavTemplate, book) },
+		"OEBPS/toc.ncx":          func() ([]byte, error) { return executeToBytes(epubNcxTemplate, book) },
+	}
+
+	if book.Cover != "" {
+		files["OEBPS/"+book.Cover] = func() ([]byte, error) { return ioutil.ReadFile(coverPath) }
+		files["OEBPS/cover.xhtml"] = func() ([]byte, error) { return []byte(fmt.Sprintf(epubCoverPage, book.Cover)), nil }
+	}
+
+	for _, chapter := range book.Chapters {
+		chapter := chapter
+		files["OEBPS/"+chapter.ID+".xhtml"] = func() ([]byte, error) { return executeToBytes(epubChapterTemplate, chapter) }
+	}
+
+	var names []string
+
+	for name := range files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		contents, err := files[name]()
+
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+
+		writer, err := archive.Create(name)
+
+		if err != nil {
+			return err
+		}
+
+		writer.Write(contents)
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(outputPath, buffer.Bytes(), 0644)
+}
+
+func executeToBytes(t *template.Template, data interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := t.Execute(&buffer, data)
+	return buffer.Bytes(), err
+}
+
+// export compiles selected articles into an EPUB book and optionally converts it to PDF, or dumps
+// the article database with export json or export csv
+func export(args []string) {
+	if len(args) > 0 && (args[0] == "json" || args[0] == "csv") {
+		exportDatabase(args[0], args[1:])
+		return
+	}
+
+	exportFlags := flag.NewFlagSet("export", flag.ExitOnError)
+	tag := exportFlags.String("tag", "", "Export articles with a tag")
+	series := exportFlags.String("series", "", "Export articles from a series")
+	from := exportFlags.String("from", "", "Export articles published on or after a date (YYYY-MM-DD)")
+	to := exportFlags.String("to", "", "Export articles published before a date (YYYY-MM-DD)")
+	output := exportFlags.String("output", "export.epub", "EPUB output file")
+	title := exportFlags.String("book-title", "", "Book title, defaults to the blog title")
+	author := exportFlags.String("book-author", *templateAuthor, "Book author")
+	language := exportFlags.String("language", "en", "Book language")
+	cover := exportFlags.String("cover", "", "Cover image file")
+	pdfCommand := exportFlags.String("pdf-command", "", "Command converting the EPUB to PDF, {input} and {output} are replaced with file names")
+	exportFlags.Parse(args)
+
+	fromDate := parseExportDate(*from, "from")
+	toDate := parseExportDate(*to, "to")
+
+	funcMap := templateFuncs()
+	articles := readArticles(findSourceFiles(), loadShortcodes(funcMap), markdownRenderer(blackfriday.HTML_USE_XHTML))
+	selected := selectExportArticles(articles, *tag, *series, fromDate, toDate)
+
+	if len(selected) == 0 {
+		log.Fatal("No articles match the export criteria")
+	}
+
+	book := EpubBook{
+		Identifier: "urn:blogger:" + strings.TrimSuffix(path.Base(*output), path.Ext(*output)),
+		Title:      *title,
+		Author:     *author,
+		Language:   *language,
+		Modified:   time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+	}
+
+	if book.Title == "" {
+		book.Title = *blogTitle
+	}
+
+	if *cover != "" {
+		book.Cover = "cover" + path.Ext(*cover)
+
+		switch strings.ToLower(path.Ext(*cover)) {
+		case ".png":
+			book.CoverType = "image/png"
+		case ".gif":
+			book.CoverType = "image/gif"
+		case ".svg":
+			book.CoverType = "image/svg+xml"
+		default:
+			book.CoverType = "image/jpeg"
+		}
+	}
+
+	for index, article := range selected {
+		book.Chapters = append(book.Chapters, EpubChapter{
+			ID:      fmt.Sprintf("chapter%03d", index+1),
+			Order:   index + 1,
+			Article: article,
+			Content: xhtmlContent(article.Content),
+			Date:    article.DateModified.In(post.Location).Format("January 2, 2006"),
+		})
+	}
+
+	if err := writeEpub(book, *cover, *output); err != nil {
+		log.Fatalf("Could not write %v: %v", *output, err)
+	}
+
+	log.Printf("Exported %d articles to %s", len(selected), *output)
+
+	if *pdfCommand == "" {
+		return
+	}
+
+	pdfOutput := strings.TrimSuffix(*output, path.Ext(*output)) + ".pdf"
+	commandLine := strings.NewReplacer("{input}", *output, "{output}", pdfOutput).Replace(*pdfCommand)
+
+	command := exec.Command("sh", "-c", commandLine)
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	if err := command.Run(); err != nil {
+		log.Fatalf("PDF conversion failed: %v", err)
+	}
+
+	log.Printf("Converted %s to %s", *output, pdfOutput)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+)
+
+// .html is left out by default, so HTML files in bundles stay assets unless pre-rendered posts are
+// asked for, e.g. with -source-extensions .md,.markdown,.txt,.html
+var sourceExtensions = flag.String("source-extensions", ".md,.markdown,.txt,.rst,.adoc,.asciidoc", "Extensions of files read as posts, comma-separated, each optionally followed by =renderer: markdown, html, rst or asciidoc")
+
+// defaultSourceRenderers are renderers of extensions listed without one, other extensions are Markdown
+var defaultSourceRenderers = map[string]string{
+	".html":     "html",
+	".htm":      "html",
+	".rst":      "rst",
+	".adoc":     "asciidoc",
+	".asciidoc": "asciidoc",
+}
+
+// sourceRenderers map extensions of files read as posts to the renderer of their content
+var sourceRenderers = map[string]string{}
+
+// configureSourceExtensions reads -source-extensions
+func configureSourceExtensions() {
+	sourceRenderers = map[string]string{}
+
+	for _, entry := range strings.Split(*sourceExtensions, ",") {
+		values := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		extension := strings.TrimSpace(values[0])
+
+		if extension == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+
+		renderer := defaultSourceRenderers[extension]
+
+		if len(values) > 1 {
+			renderer = strings.ToLower(strings.TrimSpace(values[1]))
+		}
+
+		switch renderer {
+		case "":
+			renderer = "markdown"
+		case "markdown", "html":
+		default:
+			if _, found := markupTranslators[renderer]; !found {
+				log.Printf("Ignoring extension %v with unknown renderer %q", extension, renderer)
+				continue
+			}
+		}
+
+		sourceRenderers[extension] = renderer
+	}
+}
+
+// sourceRenderer returns the renderer of files with an extension, command for ones converted
+// by external commands, or an empty string for files not read as posts
+func sourceRenderer(extension string) string {
+	if _, converted := converters[extension]; converted {
+		return "command"
+	}
+
+	return sourceRenderers[extension]
+}
+
+// isSourceExtension tells whether files with an extension are read as posts
+func isSourceExtension(extension string) bool {
+	return sourceRenderer(extension) != ""
+}
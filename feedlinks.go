@@ -0,0 +1,94 @@
+package main
+
+import (
+	"html"
+	"path"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+const feedType = "application/rss+xml"
+
+// FeedLink is a feed readers can subscribe to from a page
+type FeedLink struct {
+	Title string
+	URL   string
+	Type  string
+}
+
+// tagFeedName is the file name of the feed of a tag
+func tagFeedName(tag post.Tag) string {
+	return "index-tag-" + tag.FileName() + ".xml"
+}
+
+// tagFeedEnabled tells whether a feed is written for a tag, listed in -tagfeeds
+func tagFeedEnabled(tag post.Tag) bool {
+	return containsString(strings.Split(*tagfeeds, ","), tag.OriginalName)
+}
+
+// Feeds lists the feeds which apply to the page: the site feeds, the feed of its section, then
+// feeds of the tag or term of a tag page, or of the tags and terms of an article
+func (c PageContext) Feeds() []FeedLink {
+	feeds := []FeedLink{
+		{Title: *blogTitle, URL: absURL("index.xml"), Type: feedType},
+		{Title: "Snippets – " + *blogTitle, URL: absURL("snippets.xml"), Type: feedType},
+	}
+
+	if *podcastFeed != "" {
+		feeds = append(feeds, FeedLink{Title: "Podcast – " + *blogTitle, URL: absURL(*podcastFeed), Type: feedType})
+	}
+
+	addTag := func(tag post.Tag) {
+		if tagFeedEnabled(tag) {
+			feeds = append(feeds, FeedLink{Title: "Tag: " + tag.Name + " – " + *blogTitle, URL: absURL(tagFeedName(tag)), Type: feedType})
+		}
+	}
+
+	addTerm := func(taxonomy string, term post.Tag) {
+		feeds = append(feeds, FeedLink{Title: taxonomy + ": " + term.OriginalName + " – " + *blogTitle, URL: absURL(termFeedName(taxonomy, term.FileName())), Type: feedType})
+	}
+
+	section := c.Section
+
+	if c.Article != nil && section == "" {
+		section = c.Article.Section
+	}
+
+	if section != "" {
+		feeds = append(feeds, FeedLink{Title: strings.Title(section) + " – " + *blogTitle, URL: absURL(path.Join(section, "index.xml")), Type: feedType})
+	}
+
+	if c.Tag != nil {
+		addTag(*c.Tag)
+	}
+
+	if c.Term != nil {
+		addTerm(c.Taxonomy, *c.Term)
+	}
+
+	if c.Article != nil {
+		for _, tag := range c.Article.VisibleTags() {
+			addTag(tag)
+		}
+
+		for _, taxonomy := range post.Taxonomies {
+			for _, term := range c.Article.Taxonomies[taxonomy] {
+				addTerm(taxonomy, term)
+			}
+		}
+	}
+
+	return feeds
+}
+
+// feedLinks renders autodiscovery links to the feeds of a page, for its head
+func feedLinks(context PageContext) string {
+	var links []string
+
+	for _, feed := range context.Feeds() {
+		links = append(links, `<link rel="alternate" type="`+feed.Type+`" title="`+html.EscapeString(feed.Title)+`" href="`+html.EscapeString(feed.URL)+`">`)
+	}
+
+	return strings.Join(links, "\n")
+}
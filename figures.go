@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// imageHintKeys are query parameters of image links read as presentation hints instead of being part of the URL
+var imageHintKeys = []string{"width", "height", "align"}
+
+// splitImageHints removes width, height and align hints from the query of an image link,
+// e.g. photo.jpg?width=600&align=right, returning the cleaned link and the hints found
+func splitImageHints(link string) (string, url.Values) {
+	queryStart := strings.Index(link, "?")
+
+	if queryStart < 0 {
+		return link, nil
+	}
+
+	query, err := url.ParseQuery(link[queryStart+1:])
+
+	if err != nil {
+		return link, nil
+	}
+
+	hints := url.Values{}
+
+	for _, key := range imageHintKeys {
+		if value := query.Get(key); value != "" {
+			hints.Set(key, value)
+			query.Del(key)
+		}
+	}
+
+	if len(hints) == 0 {
+		return link, nil
+	}
+
+	cleanLink := link[:queryStart]
+
+	if len(query) > 0 {
+		cleanLink += "?" + query.Encode()
+	}
+
+	return cleanLink, hints
+}
+
+// renderFigure writes an image rendered by the HTML renderer with hints applied, wrapped
+// in a figure with the title as its caption when it has one
+func renderFigure(out *bytes.Buffer, image []byte, title string, hints url.Values) {
+	var attributes string
+
+	for _, key := range []string{"width", "height"} {
+		if value := hints.Get(key); value != "" {
+			attributes += fmt.Sprintf(` %s="%s"`, key, html.EscapeString(value))
+		}
+	}
+
+	alignClass := ""
+
+	if align := hints.Get("align"); align != "" {
+		alignClass = "align-" + html.EscapeString(align)
+	}
+
+	if title == "" {
+		if alignClass != "" {
+			attributes += ` class="` + alignClass + `"`
+		}
+
+		out.Write(bytes.Replace(image, []byte("<img "), []byte("<img"+attributes+" "), 1))
+		return
+	}
+
+	out.WriteString(strings.TrimSpace(`<figure class="figure `+alignClass) + `">`)
+	out.Write(bytes.Replace(image, []byte("<img "), []byte("<img"+attributes+" "), 1))
+	out.WriteString(`<figcaption>` + html.EscapeString(title) + `</figcaption></figure>`)
+}
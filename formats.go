@@ -0,0 +1,112 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+
+	"macbirdie.net/blogger/post"
+)
+
+// OutputFormat is an additional rendition of an article, generated next to the primary one
+type OutputFormat struct {
+	Name      string
+	Extension string
+	Types     []post.PageType
+	Template  *template.Template
+}
+
+// Applies checks if the format should be generated for the given article. Formats listed
+// in the article's outputs front matter take precedence over the configured article types.
+func (f OutputFormat) Applies(article *post.Article) bool {
+	if article.Outputs != nil {
+		return containsString(article.Outputs, f.Name)
+	}
+
+	if len(f.Types) == 0 {
+		return true
+	}
+
+	for _, articleType := range f.Types {
+		if articleType == article.Type {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FileName returns the file name of the article rendered in this format
+func (f OutputFormat) FileName(article *post.Article) string {
+	if isDirectoryFormat(f.Extension) {
+		return path.Join(article.BasePath(), articleStem(article), f.Extension, "index.html")
+	}
+
+	return path.Join(article.BasePath(), articleStem(article)+f.Extension)
+}
+
+// Path returns the path of the article rendered in this format, relative to blog root path
+func (f OutputFormat) Path(article *post.Article) string {
+	if isDirectoryFormat(f.Extension) {
+		return path.Join(article.BasePath(), articleStem(article), f.Extension) + "/"
+	}
+
+	return f.FileName(article)
+}
+
+// parseOutputFormats reads the -formats flag, formatted as a comma-separated list of
+// name:extension[:Type|Type] entries. Each format is rendered with format-<name>.html
+// from the templates directory. An extension starting with a slash, like /amp/, places
+// the format in a directory below the article, e.g. amp:/amp/ for AMP versions linked
+// from the article pages.
+func parseOutputFormats(funcMap template.FuncMap) []OutputFormat {
+	var formats []OutputFormat
+
+	for _, entry := range strings.Split(*outputFormats, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		values := strings.Split(entry, ":")
+
+		if len(values) < 2 || values[0] == "" {
+			log.Fatalf("Invalid output format %q, expected name:extension[:Type|Type]", entry)
+		}
+
+		format := OutputFormat{Name: values[0], Extension: values[1]}
+
+		if isDirectoryFormat(format.Extension) && *uglyURLs && *destinationExt == "" {
+			log.Printf("Output format %v is written below article files without an extension, which needs -ugly-urls=false or an -extension", format.Name)
+		}
+
+		if len(values) > 2 {
+			for _, articleType := range strings.Split(values[2], "|") {
+				format.Types = append(format.Types, post.PageType(articleType))
+			}
+		}
+
+		templateName := "format-" + format.Name + ".html"
+		format.Template = template.Must(template.New(templateName).Funcs(funcMap).ParseFiles(path.Join(*templatesPath, templateName)))
+
+		formats = append(formats, format)
+	}
+
+	return formats
+}
+
+// writeOutputFormats renders an article in every applicable additional format
+func writeOutputFormats(formats []OutputFormat, destinationDir string, article *post.Article, context PageContext) {
+	for _, format := range formats {
+		if !format.Applies(article) {
+			continue
+		}
+
+		fileName := path.Join(destinationDir, format.FileName(article))
+		os.MkdirAll(path.Dir(fileName), os.ModePerm)
+		executeToFile(fileName, format.Template, context)
+	}
+}
@@ -0,0 +1,215 @@
+// Package funcs holds template functions available in all blogger templates. Programs
+// embedding blogger can add their own functions with Register before generating a site.
+package funcs
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/russross/blackfriday"
+)
+
+// Location is the time zone dates are formatted in
+var Location = time.Local
+
+// TypographyFlags are the blackfriday smartypants flags Markdownify renders with
+var TypographyFlags = blackfriday.HTML_USE_SMARTYPANTS | blackfriday.HTML_SMARTYPANTS_FRACTIONS |
+	blackfriday.HTML_SMARTYPANTS_LATEX_DASHES
+
+// markdownifyExtensions are the extensions of blackfriday.MarkdownCommon
+const markdownifyExtensions = blackfriday.EXTENSION_NO_INTRA_EMPHASIS | blackfriday.EXTENSION_TABLES |
+	blackfriday.EXTENSION_FENCED_CODE | blackfriday.EXTENSION_AUTOLINK | blackfriday.EXTENSION_STRIKETHROUGH |
+	blackfriday.EXTENSION_SPACE_HEADERS | blackfriday.EXTENSION_HEADER_IDS | blackfriday.EXTENSION_BACKSLASH_LINE_BREAK |
+	blackfriday.EXTENSION_DEFINITION_LISTS
+
+var registryMutex sync.RWMutex
+
+var registry = template.FuncMap{
+	"truncate":    Truncate,
+	"markdownify": Markdownify,
+	"list":        List,
+	"where":       Where,
+	"first":       First,
+	"dateFormat":  DateFormat,
+	"localDate":   LocalDate,
+	"locale":      CurrentLocale,
+}
+
+// Register adds a template function, replacing a built-in one with the same name
+func Register(name string, function interface{}) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	registry[name] = function
+}
+
+// Map returns all registered template functions merged over the given ones
+func Map(base template.FuncMap) template.FuncMap {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	funcMap := template.FuncMap{}
+
+	for name, function := range base {
+		funcMap[name] = function
+	}
+
+	for name, function := range registry {
+		funcMap[name] = function
+	}
+
+	return funcMap
+}
+
+// Truncate shortens text to a number of characters, ending it with an ellipsis when cut. Negative
+// lengths cut all text.
+func Truncate(length int, text string) string {
+	if length < 0 {
+		length = 0
+	}
+
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+
+	runes := []rune(text)
+	truncated := strings.TrimRightFunc(string(runes[:length]), func(r rune) bool { return r == ' ' || r == '\n' })
+
+	return truncated + "…"
+}
+
+// Markdownify renders Markdown to HTML, dropping the paragraph around single line results
+func Markdownify(markdown string) string {
+	renderer := blackfriday.HtmlRenderer(blackfriday.HTML_USE_XHTML|TypographyFlags, "", "")
+	rendered := strings.TrimSpace(string(blackfriday.Markdown([]byte(markdown), renderer, markdownifyExtensions)))
+
+	if strings.Count(rendered, "<p>") == 1 && strings.HasPrefix(rendered, "<p>") && strings.HasSuffix(rendered, "</p>") {
+		rendered = strings.TrimSuffix(strings.TrimPrefix(rendered, "<p>"), "</p>")
+	}
+
+	return rendered
+}
+
+// List creates a list from its arguments. It isn't named slice, which is a built-in function
+// of Go templates slicing strings and lists.
+func List(items ...interface{}) []interface{} {
+	return items
+}
+
+// fieldValue reads a struct field, a map key or a method result without arguments
+func fieldValue(item reflect.Value, key string) (reflect.Value, bool) {
+	for item.Kind() == reflect.Ptr || item.Kind() == reflect.Interface {
+		if item.IsNil() {
+			return reflect.Value{}, false
+		}
+
+		if method := item.MethodByName(key); method.IsValid() && method.Type().NumIn() == 0 && method.Type().NumOut() >= 1 {
+			return method.Call(nil)[0], true
+		}
+
+		item = item.Elem()
+	}
+
+	switch item.Kind() {
+	case reflect.Struct:
+		if field := item.FieldByName(key); field.IsValid() {
+			return field, true
+		}
+	case reflect.Map:
+		if value := item.MapIndex(reflect.ValueOf(key)); value.IsValid() {
+			return value, true
+		}
+	}
+
+	if method := item.MethodByName(key); method.IsValid() && method.Type().NumIn() == 0 && method.Type().NumOut() >= 1 {
+		return method.Call(nil)[0], true
+	}
+
+	return reflect.Value{}, false
+}
+
+// Where filters a list to items with a field, map key or method equal to a value.
+// List fields match when they contain the value.
+func Where(collection interface{}, key string, value interface{}) ([]interface{}, error) {
+	items := reflect.ValueOf(collection)
+
+	if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		return nil, fmt.Errorf("where: can't filter %T", collection)
+	}
+
+	var filtered []interface{}
+
+	for index := 0; index < items.Len(); index++ {
+		item := items.Index(index)
+		field, found := fieldValue(item, key)
+
+		if !found {
+			continue
+		}
+
+		if matches(field, value) {
+			filtered = append(filtered, item.Interface())
+		}
+	}
+
+	return filtered, nil
+}
+
+func matches(field reflect.Value, value interface{}) bool {
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return value == nil
+		}
+		field = field.Elem()
+	}
+
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		for index := 0; index < field.Len(); index++ {
+			if matches(field.Index(index), value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return fmt.Sprint(field.Interface()) == fmt.Sprint(value)
+}
+
+// First returns up to a number of items from the beginning of a list
+func First(count int, collection interface{}) (interface{}, error) {
+	items := reflect.ValueOf(collection)
+
+	if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		return nil, fmt.Errorf("first: can't take items of %T", collection)
+	}
+
+	if count > items.Len() {
+		count = items.Len()
+	}
+
+	if count < 0 {
+		count = 0
+	}
+
+	return items.Slice(0, count).Interface(), nil
+}
+
+// DateFormat formats a time, or a pointer to one, with a Go layout
+func DateFormat(layout string, date interface{}) (string, error) {
+	switch value := date.(type) {
+	case time.Time:
+		return value.In(Location).Format(layout), nil
+	case *time.Time:
+		if value == nil {
+			return "", nil
+		}
+		return value.In(Location).Format(layout), nil
+	}
+
+	return "", fmt.Errorf("dateFormat: %T is not a time", date)
+}
@@ -0,0 +1,179 @@
+package funcs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Locale is the language localDate formats dates in, e.g. pl or de-AT
+var Locale = "en"
+
+// DateLocale holds the names and date orderings of a language. Months are in the form used in a
+// full date, which for some languages is the genitive.
+type DateLocale struct {
+	Months      [12]string
+	ShortMonths [12]string
+	Days        [7]string
+	ShortDays   [7]string
+	// Layouts are the Go layouts of the short, medium, long and full date styles
+	Layouts map[string]string
+}
+
+// Locales are the languages known to localDate, by their base language tag
+var Locales = map[string]DateLocale{
+	"en": {
+		Months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		ShortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		Days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		ShortDays:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		Layouts:     map[string]string{"short": "1/2/06", "medium": "Jan 2, 2006", "long": "January 2, 2006", "full": "Monday, January 2, 2006"},
+	},
+	"de": {
+		Months:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		ShortMonths: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		Days:        [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		ShortDays:   [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		Layouts:     map[string]string{"short": "02.01.06", "medium": "02.01.2006", "long": "2. January 2006", "full": "Monday, 2. January 2006"},
+	},
+	"fr": {
+		Months:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		ShortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		Days:        [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		ShortDays:   [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		Layouts:     map[string]string{"short": "02/01/2006", "medium": "2 Jan 2006", "long": "2 January 2006", "full": "Monday 2 January 2006"},
+	},
+	"es": {
+		Months:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		ShortMonths: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		Days:        [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		ShortDays:   [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		Layouts:     map[string]string{"short": "2/1/06", "medium": "2 Jan 2006", "long": "2 de January de 2006", "full": "Monday, 2 de January de 2006"},
+	},
+	"it": {
+		Months:      [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		ShortMonths: [12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		Days:        [7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		ShortDays:   [7]string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+		Layouts:     map[string]string{"short": "02/01/06", "medium": "2 Jan 2006", "long": "2 January 2006", "full": "Monday 2 January 2006"},
+	},
+	"nl": {
+		Months:      [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		ShortMonths: [12]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+		Days:        [7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+		ShortDays:   [7]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+		Layouts:     map[string]string{"short": "02-01-2006", "medium": "2 Jan 2006", "long": "2 January 2006", "full": "Monday 2 January 2006"},
+	},
+	"pl": {
+		Months:      [12]string{"stycznia", "lutego", "marca", "kwietnia", "maja", "czerwca", "lipca", "sierpnia", "września", "października", "listopada", "grudnia"},
+		ShortMonths: [12]string{"sty", "lut", "mar", "kwi", "maj", "cze", "lip", "sie", "wrz", "paź", "lis", "gru"},
+		Days:        [7]string{"niedziela", "poniedziałek", "wtorek", "środa", "czwartek", "piątek", "sobota"},
+		ShortDays:   [7]string{"niedz.", "pon.", "wt.", "śr.", "czw.", "pt.", "sob."},
+		Layouts:     map[string]string{"short": "02.01.2006", "medium": "2 Jan 2006", "long": "2 January 2006", "full": "Monday, 2 January 2006"},
+	},
+	"pt": {
+		Months:      [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		ShortMonths: [12]string{"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set", "out", "nov", "dez"},
+		Days:        [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		ShortDays:   [7]string{"dom", "seg", "ter", "qua", "qui", "sex", "sáb"},
+		Layouts:     map[string]string{"short": "02/01/2006", "medium": "2 de Jan de 2006", "long": "2 de January de 2006", "full": "Monday, 2 de January de 2006"},
+	},
+}
+
+// dateNameTokens are the layout tokens replaced with localized names, longer ones first
+var dateNameTokens = []string{"January", "Monday", "Jan", "Mon"}
+
+// currentLocale returns the configured locale, falling back to its base language and then English
+func currentLocale() DateLocale {
+	tag := strings.ToLower(strings.Replace(Locale, "_", "-", -1))
+
+	if locale, found := Locales[tag]; found {
+		return locale
+	}
+
+	if index := strings.Index(tag, "-"); index > 0 {
+		if locale, found := Locales[tag[:index]]; found {
+			return locale
+		}
+	}
+
+	return Locales["en"]
+}
+
+// CurrentLocale returns the language tag of the configured locale
+func CurrentLocale() string {
+	return Locale
+}
+
+// LocalDate formats a time, or a pointer to one, in the configured locale. The format is a date
+// style, short, medium, long or full, ordered the way the locale writes dates, or a Go layout
+// whose month and day names are translated.
+func LocalDate(format string, date interface{}) (string, error) {
+	var moment time.Time
+
+	switch value := date.(type) {
+	case time.Time:
+		moment = value
+	case *time.Time:
+		if value == nil {
+			return "", nil
+		}
+		moment = *value
+	default:
+		return "", fmt.Errorf("localDate: %T is not a time", date)
+	}
+
+	locale := currentLocale()
+	moment = moment.In(Location)
+
+	layout := format
+
+	if styleLayout, found := locale.Layouts[format]; found {
+		layout = styleLayout
+	}
+
+	var formatted strings.Builder
+
+	for layout != "" {
+		index, token := nextDateNameToken(layout)
+
+		if token == "" {
+			formatted.WriteString(moment.Format(layout))
+			break
+		}
+
+		if index > 0 {
+			formatted.WriteString(moment.Format(layout[:index]))
+		}
+
+		switch token {
+		case "January":
+			formatted.WriteString(locale.Months[moment.Month()-1])
+		case "Jan":
+			formatted.WriteString(locale.ShortMonths[moment.Month()-1])
+		case "Monday":
+			formatted.WriteString(locale.Days[moment.Weekday()])
+		case "Mon":
+			formatted.WriteString(locale.ShortDays[moment.Weekday()])
+		}
+
+		layout = layout[index+len(token):]
+	}
+
+	return formatted.String(), nil
+}
+
+// nextDateNameToken finds the first month or day name token of a layout
+func nextDateNameToken(layout string) (int, string) {
+	firstIndex, firstToken := -1, ""
+
+	for _, token := range dateNameTokens {
+		index := strings.Index(layout, token)
+
+		if index >= 0 && (firstIndex < 0 || index < firstIndex || (index == firstIndex && len(token) > len(firstToken))) {
+			firstIndex, firstToken = index, token
+		}
+	}
+
+	return firstIndex, firstToken
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"macbirdie.net/blogger/post"
+)
+
+var gitMetadata = flag.Bool("git-info", false, "Take missing dates and authors from the git history of source files, and expose their last commit to templates")
+
+// GitInfo is the git history of an article source
+type GitInfo struct {
+	Commit string
+	Path   string
+}
+
+// gitInfos are the histories of article sources, keyed by source file
+var gitInfos = struct {
+	sync.Mutex
+	bySource map[string]GitInfo
+}{bySource: map[string]GitInfo{}}
+
+// gitCommit is the last commit of an article source, empty when it isn't tracked. Templates link
+// to the source or its history with it and gitPath.
+func gitCommit(article *post.Article) string {
+	gitInfos.Lock()
+	defer gitInfos.Unlock()
+
+	return gitInfos.bySource[article.Source].Commit
+}
+
+// gitPath is the path of an article source relative to the root of its repository
+func gitPath(article *post.Article) string {
+	gitInfos.Lock()
+	defer gitInfos.Unlock()
+
+	return gitInfos.bySource[article.Source].Path
+}
+
+// applyGitInfo dates an article by its first and last commit and credits the author of the first,
+// when front matter leaves them out. Untracked sources are left as they are.
+func applyGitInfo(article *post.Article) {
+	if !*gitMetadata {
+		return
+	}
+
+	dir, name := filepath.Split(article.Source)
+
+	if dir == "" {
+		dir = "."
+	}
+
+	output, err := exec.Command("git", "-C", dir, "log", "--follow", "--format=%H%x09%aI%x09%an", "--", name).Output()
+
+	if err != nil || len(strings.TrimSpace(string(output))) == 0 {
+		return
+	}
+
+	commits := strings.Split(strings.TrimSpace(string(output)), "\n")
+	last, first := strings.Split(commits[0], "\t"), strings.Split(commits[len(commits)-1], "\t")
+
+	if len(last) < 3 || len(first) < 3 {
+		return
+	}
+
+	info := GitInfo{Commit: last[0]}
+
+	if fullName, err := exec.Command("git", "-C", dir, "ls-files", "--full-name", "--", name).Output(); err == nil {
+		info.Path = strings.TrimSpace(string(fullName))
+	}
+
+	gitInfos.Lock()
+	gitInfos.bySource[article.Source] = info
+	gitInfos.Unlock()
+
+	if created, err := time.Parse(time.RFC3339, first[1]); err == nil && article.Undated {
+		created = created.In(post.Location)
+		article.DateModified = &created
+		article.Undated = false
+	}
+
+	if updated, err := time.Parse(time.RFC3339, last[1]); err == nil && article.DateUpdated == nil && len(commits) > 1 {
+		updated = updated.In(post.Location)
+		article.DateUpdated = &updated
+	}
+
+	if article.Author == "" {
+		article.Author = first[2]
+	}
+}
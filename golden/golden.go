@@ -0,0 +1,138 @@
+// Package golden compares generated sites with golden copies of their files, so template and
+// renderer changes can be checked in tests and continuous integration.
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Difference is a generated file which doesn't match its golden copy
+type Difference struct {
+	File    string
+	Problem string
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s", d.File, d.Problem)
+}
+
+// Generator generates a site into a destination directory, failing when the build does
+type Generator func(destination string) error
+
+// BuildSnapshot generates a site into a temporary directory and returns the generated files by
+// their path in the destination
+func BuildSnapshot(generate Generator) (map[string][]byte, error) {
+	destination, err := ioutil.TempDir("", "blogger-snapshot")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(destination)
+
+	if err := generate(destination); err != nil {
+		return nil, err
+	}
+
+	return ReadSnapshot(destination)
+}
+
+// ReadSnapshot reads all files of a directory by their slash-separated relative path
+func ReadSnapshot(dir string) (map[string][]byte, error) {
+	snapshot := map[string][]byte{}
+
+	err := filepath.Walk(dir, func(fileName string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		contents, readErr := ioutil.ReadFile(fileName)
+
+		if readErr != nil {
+			return readErr
+		}
+
+		relative, _ := filepath.Rel(dir, fileName)
+		snapshot[filepath.ToSlash(relative)] = contents
+
+		return nil
+	})
+
+	return snapshot, err
+}
+
+// Compare compares a snapshot with the golden files of a directory, reporting missing,
+// unexpected and differing files
+func Compare(snapshot map[string][]byte, goldenDir string) ([]Difference, error) {
+	golden, err := ReadSnapshot(goldenDir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var differences []Difference
+
+	for name, contents := range snapshot {
+		expected, found := golden[name]
+
+		if !found {
+			differences = append(differences, Difference{File: name, Problem: "not in golden files"})
+			continue
+		}
+
+		if !bytes.Equal(contents, expected) {
+			differences = append(differences, Difference{File: name, Problem: firstDifference(expected, contents)})
+		}
+	}
+
+	for name := range golden {
+		if _, found := snapshot[name]; !found {
+			differences = append(differences, Difference{File: name, Problem: "not generated"})
+		}
+	}
+
+	sort.Slice(differences, func(i, j int) bool { return differences[i].File < differences[j].File })
+
+	return differences, nil
+}
+
+// firstDifference describes the first line where generated content differs from the expected one
+func firstDifference(expected, actual []byte) string {
+	expectedLines := bytes.Split(expected, []byte("\n"))
+	actualLines := bytes.Split(actual, []byte("\n"))
+
+	for index := 0; index < len(expectedLines) && index < len(actualLines); index++ {
+		if !bytes.Equal(expectedLines[index], actualLines[index]) {
+			return fmt.Sprintf("line %d differs, expected %q, got %q", index+1, expectedLines[index], actualLines[index])
+		}
+	}
+
+	return fmt.Sprintf("expected %d lines, got %d", len(expectedLines), len(actualLines))
+}
+
+// Update replaces the golden files of a directory with a snapshot
+func Update(snapshot map[string][]byte, goldenDir string) error {
+	if err := os.RemoveAll(goldenDir); err != nil {
+		return err
+	}
+
+	for name, contents := range snapshot {
+		fileName := path.Join(goldenDir, name)
+
+		if err := os.MkdirAll(path.Dir(fileName), os.ModePerm); err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(fileName, contents, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
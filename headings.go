@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+var titleHeadingPattern = regexp.MustCompile(`^#[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+
+// headingID derives an identifier for a heading from its rendered content according to the
+// -heading-ids scheme, numbering repeated identifiers within an article
+func headingID(content []byte, usedIDs map[string]int) string {
+	text := html.UnescapeString(tagPattern.ReplaceAllString(string(content), ""))
+
+	var id string
+
+	switch *headingIDs {
+	case "ascii":
+		id = slugify(text, true)
+	case "unicode":
+		id = slugify(text, false)
+	default:
+		return ""
+	}
+
+	if id == "" {
+		id = "section"
+	}
+
+	count := usedIDs[id]
+	usedIDs[id] = count + 1
+
+	if count > 0 {
+		id = fmt.Sprintf("%s-%d", id, count)
+	}
+
+	return id
+}
+
+// extractTitle finds the first level one heading of a Markdown body outside code blocks, returning
+// its text and the body without it, or an empty title and the body as it is
+func extractTitle(body []byte) (string, []byte) {
+	lines := bytes.Split(body, []byte("\n"))
+	fenced := false
+
+	for index, line := range lines {
+		trimmed := strings.TrimSpace(string(line))
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+
+		if fenced {
+			continue
+		}
+
+		if match := titleHeadingPattern.FindStringSubmatch(strings.TrimRight(string(line), "\r")); match != nil && match[1] != "" {
+			rest := append(append([][]byte{}, lines[:index]...), lines[index+1:]...)
+			return strings.TrimSpace(match[1]), bytes.Join(rest, []byte("\n"))
+		}
+	}
+
+	return "", body
+}
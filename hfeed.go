@@ -0,0 +1,249 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+// An index page becomes an h-feed readers can subscribe to with markup like:
+//
+//	<div class="h-feed">
+//	  {{hFeedName .Title}}
+//	  {{range .Articles}}{{hEntry .}}{{end}}
+//	</div>
+//
+// hEntry marks up every property readers need. Templates with their own article markup can use
+// pName, uURL, dtPublished, pAuthor, pCategories and eContent inside an element of class h-entry.
+
+var elementTagPattern = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*?)(/?)>`)
+var classAttributePattern = regexp.MustCompile(`(?i)\sclass\s*=\s*["']([^"']*)["']`)
+
+// voidElements have no closing tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// hFeedName marks up the name of a feed
+func hFeedName(title string) string {
+	return `<h1 class="p-name">` + html.EscapeString(title) + `</h1>`
+}
+
+// pAuthor marks up the author of an article as an h-card, empty when it has none
+func pAuthor(article *post.Article) string {
+	if article.Author == "" {
+		return ""
+	}
+
+	return `<span class="p-author h-card">` + html.EscapeString(article.Author) + `</span>`
+}
+
+// pCategories marks up the visible tags of an article, linked to their tag pages
+func pCategories(article *post.Article) string {
+	var categories []string
+
+	for _, tag := range article.VisibleTags() {
+		categories = append(categories, `<a class="p-category" href="`+html.EscapeString(sitePath(tagIndexName(tag)))+`">`+html.EscapeString(tag.Name)+`</a>`)
+	}
+
+	return strings.Join(categories, " ")
+}
+
+// hEntry marks up an article listed in an h-feed with its name, permalink, date, author, tags and content
+func hEntry(article *post.Article) string {
+	entry := `<article class="h-entry">`
+
+	if name := pName(article); name != "" {
+		entry += `<h2>` + uURL(article, name) + `</h2>` + dtPublished(article)
+	} else {
+		entry += uURL(article, dtPublished(article))
+	}
+
+	entry += pAuthor(article)
+
+	if categories := pCategories(article); categories != "" {
+		entry += `<div class="categories">` + categories + `</div>`
+	}
+
+	if response := responseLink(article); response != "" {
+		entry += response
+	}
+
+	return entry + eContent(article) + `</article>`
+}
+
+// microformatsElement is an element with microformats classes, with the classes of its descendants
+type microformatsElement struct {
+	classes     []string
+	descendants map[string]bool
+	entries     int
+}
+
+// hasClass tells whether a list of classes contains one
+func hasClass(classes []string, class string) bool {
+	return containsString(classes, class)
+}
+
+// microformatsIssues finds h-feed and h-entry elements of a page missing properties readers need,
+// and counts the h-feed elements
+func microformatsIssues(contents string) (issues []string, feeds int) {
+	var stack []*microformatsElement
+	var tags []string
+
+	closeElement := func(element *microformatsElement) {
+		if hasClass(element.classes, "h-entry") {
+			if !element.descendants["u-url"] {
+				issues = append(issues, "h-entry without u-url")
+			}
+
+			if !element.descendants["dt-published"] {
+				issues = append(issues, "h-entry without dt-published")
+			}
+
+			if !element.descendants["p-name"] && !element.descendants["e-content"] {
+				issues = append(issues, "h-entry without p-name or e-content")
+			}
+		}
+
+		if hasClass(element.classes, "h-feed") {
+			feeds++
+
+			if element.entries == 0 {
+				issues = append(issues, "h-feed without h-entry children")
+			}
+		}
+	}
+
+	for _, match := range elementTagPattern.FindAllStringSubmatch(contents, -1) {
+		closing, name, attributes, selfClosing := match[1] == "/", strings.ToLower(match[2]), match[3], match[4] == "/"
+
+		if closing {
+			// unwind to the matching element, tolerating unclosed ones
+			for index := len(tags) - 1; index >= 0; index-- {
+				if tags[index] != name {
+					continue
+				}
+
+				for len(tags) > index {
+					if element := stack[len(stack)-1]; element != nil {
+						closeElement(element)
+					}
+
+					tags, stack = tags[:len(tags)-1], stack[:len(stack)-1]
+				}
+
+				break
+			}
+
+			continue
+		}
+
+		var element *microformatsElement
+
+		if classMatch := classAttributePattern.FindStringSubmatch(attributes); classMatch != nil {
+			classes := strings.Fields(classMatch[1])
+
+			for _, ancestor := range stack {
+				if ancestor == nil {
+					continue
+				}
+
+				for _, class := range classes {
+					ancestor.descendants[class] = true
+				}
+
+				if hasClass(classes, "h-entry") && hasClass(ancestor.classes, "h-feed") {
+					ancestor.entries++
+				}
+			}
+
+			if hasClass(classes, "h-entry") || hasClass(classes, "h-feed") {
+				element = &microformatsElement{classes: classes, descendants: map[string]bool{}}
+			}
+		}
+
+		if voidElements[name] || selfClosing {
+			if element != nil {
+				closeElement(element)
+			}
+			continue
+		}
+
+		tags = append(tags, name)
+		stack = append(stack, element)
+	}
+
+	for index := len(stack) - 1; index >= 0; index-- {
+		if stack[index] != nil {
+			closeElement(stack[index])
+		}
+	}
+
+	return issues, feeds
+}
+
+// checkMicroformats validates microformats of all generated HTML pages, expecting the home index
+// to be an h-feed
+func checkMicroformats(destinationDir string) []string {
+	var issues []string
+	homeIndex := path.Join(destinationDir, "index.html")
+
+	filepath.Walk(destinationDir, func(fileName string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		if !isPageFile(fileName) {
+			return nil
+		}
+
+		contents, readErr := ioutil.ReadFile(fileName)
+
+		if readErr != nil {
+			log.Printf("Skipping %v due to error: %v", fileName, readErr)
+			return nil
+		}
+
+		pageIssues, feeds := microformatsIssues(string(contents))
+
+		if feeds == 0 && path.Clean(fileName) == homeIndex {
+			pageIssues = append(pageIssues, "home index is not an h-feed")
+		}
+
+		for _, issue := range pageIssues {
+			issues = append(issues, fmt.Sprintf("%s: %s", fileName, issue))
+		}
+
+		return nil
+	})
+
+	return issues
+}
+
+// validateMicroformats reports generated pages IndieWeb readers can't subscribe to or read entries of
+func validateMicroformats(args []string) {
+	microformatsFlags := flag.NewFlagSet("microformats", flag.ExitOnError)
+	microformatsFlags.Parse(args)
+
+	issues := checkMicroformats(path.Clean(*destinationPath))
+
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+
+	if len(issues) > 0 {
+		log.Fatalf("Found %d microformats problems", len(issues))
+	}
+
+	log.Println("No microformats problems found")
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"plugin"
+	"strings"
+
+	"macbirdie.net/blogger/hooks"
+	"macbirdie.net/blogger/post"
+)
+
+var hookCommands = flag.String("hooks", "", "Commands run at build stages, as a comma-separated list of stage=command, e.g. after-render=./fix-links")
+var pluginFiles = flag.String("plugins", "", "Comma-separated list of Go plugins registering hooks or template functions when loaded")
+
+// commandHooks are external commands run at each hook point
+var commandHooks = map[hooks.Point][]string{}
+
+// configureHooks reads -hooks. Commands receive the event as JSON on their standard input, and
+// may print a changed article as JSON, with just the fields to change.
+func configureHooks() {
+	commandHooks = map[hooks.Point][]string{}
+
+	for _, entry := range strings.Split(*hookCommands, ",") {
+		values := strings.SplitN(entry, "=", 2)
+
+		if len(values) < 2 || strings.TrimSpace(values[1]) == "" {
+			continue
+		}
+
+		point := hooks.Point(strings.TrimSpace(values[0]))
+		known := false
+
+		for _, candidate := range hooks.Points {
+			known = known || candidate == point
+		}
+
+		if !known {
+			log.Printf("Ignoring hook for unknown stage %q", point)
+			continue
+		}
+
+		commandHooks[point] = append(commandHooks[point], strings.TrimSpace(values[1]))
+	}
+}
+
+// loadPlugins opens Go plugins, which register their hooks and functions as they initialize
+func loadPlugins() {
+	for _, fileName := range strings.Split(*pluginFiles, ",") {
+		if fileName = strings.TrimSpace(fileName); fileName == "" {
+			continue
+		}
+
+		if _, err := plugin.Open(fileName); err != nil {
+			log.Fatalf("Could not load plugin %v: %v", fileName, err)
+		}
+	}
+}
+
+// runHookCommand runs an external hook with the event on its standard input
+func runHookCommand(command string, event *hooks.Event) error {
+	input, err := json.Marshal(event)
+
+	if err != nil {
+		return err
+	}
+
+	arguments := strings.Fields(command)
+	cmd := exec.Command(arguments[0], arguments[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+
+	if err != nil {
+		return fmt.Errorf("%v: %v", command, err)
+	}
+
+	if event.Article == nil || len(bytes.TrimSpace(output)) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(output, event.Article); err != nil {
+		return fmt.Errorf("%v printed an invalid article: %v", command, err)
+	}
+
+	return nil
+}
+
+// runHooks runs registered hooks, then external commands, at a build stage
+func runHooks(point hooks.Point, article *post.Article, destination string) error {
+	event := &hooks.Event{Point: point, Article: article, Destination: destination}
+
+	if err := hooks.Run(event); err != nil {
+		return err
+	}
+
+	for _, command := range commandHooks[point] {
+		if err := runHookCommand(command, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+// Package hooks runs functions at points of the blogger build lifecycle. Programs embedding
+// blogger, and Go plugins loaded with -plugins, can add their own with Register.
+package hooks
+
+import (
+	"sync"
+
+	"macbirdie.net/blogger/post"
+)
+
+// Point is a stage of the build at which hooks run
+type Point string
+
+const (
+	// AfterParse runs for each article once its front matter is read
+	AfterParse Point = "after-parse"
+	// BeforeRender runs for each article before its Markdown is rendered
+	BeforeRender Point = "before-render"
+	// AfterRender runs for each article once its content is rendered
+	AfterRender Point = "after-render"
+	// BeforeWrite runs for each article before its pages are written
+	BeforeWrite Point = "before-write"
+	// AfterBuild runs once the whole site is generated
+	AfterBuild Point = "after-build"
+)
+
+// Points are all hook points in the order they are reached
+var Points = []Point{AfterParse, BeforeRender, AfterRender, BeforeWrite, AfterBuild}
+
+// Event is passed to hooks. Article is set at the points reached for each article, and hooks
+// may change it.
+type Event struct {
+	Point       Point         `json:"point"`
+	Article     *post.Article `json:"article,omitempty"`
+	Destination string        `json:"destination"`
+}
+
+// Hook is a function run at a hook point. An error drops the article from the build.
+type Hook func(event *Event) error
+
+var registryMutex sync.RWMutex
+
+var registry = map[Point][]Hook{}
+
+// Register adds a hook run at a point, after the ones registered before
+func Register(point Point, hook Hook) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	registry[point] = append(registry[point], hook)
+}
+
+// Run runs the hooks registered for the point of an event, stopping at the first error
+func Run(event *Event) error {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	for _, hook := range registry[event.Point] {
+		if err := hook(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
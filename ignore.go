@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+)
+
+const ignoreFileName = ".bloggerignore"
+
+// ignoreRules are glob patterns of files and directories in a post directory that are not published.
+// Patterns without a slash match names at any depth, patterns with one match paths relative
+// to the post directory, and patterns ending with a slash only match directories.
+type ignoreRules []string
+
+// loadIgnoreRules reads the .bloggerignore file of a post directory, adding patterns of the -ignore flag
+func loadIgnoreRules(postDir string) ignoreRules {
+	var rules ignoreRules
+
+	for _, pattern := range strings.Split(*ignorePatterns, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			rules = append(rules, pattern)
+		}
+	}
+
+	file, err := os.Open(path.Join(postDir, ignoreFileName))
+
+	if err != nil {
+		return rules
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rules = append(rules, line)
+	}
+
+	return rules
+}
+
+// ignored tells if a path relative to the post directory matches any of the rules
+func (rules ignoreRules) ignored(relative string, isDir bool) bool {
+	for _, pattern := range rules {
+		if strings.HasSuffix(pattern, "/") {
+			if !isDir {
+				continue
+			}
+
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+
+		subject := relative
+
+		if !strings.Contains(pattern, "/") {
+			subject = path.Base(relative)
+		}
+
+		if matched, _ := path.Match(strings.TrimPrefix(pattern, "/"), subject); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	// Image formats dimensions are read from
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+type imageSize struct {
+	width, height int
+}
+
+// cachedImageSize is the size of an image file as of its modification time
+type cachedImageSize struct {
+	imageSize
+	modified time.Time
+}
+
+// imageSizes caches dimensions of image files by path, zero for files that could not be read.
+// Entries of files modified since, like images replaced while watching, are read again.
+var imageSizes = map[string]cachedImageSize{}
+
+// localFile finds the file a local link points to, looking in the directory of the
+// article for relative links, then in the destination and posts directories
+func localFile(link, articleDir string) string {
+	target, err := url.Parse(link)
+
+	if err != nil || target.IsAbs() || target.Host != "" || target.Path == "" {
+		return ""
+	}
+
+	var candidates []string
+
+	if strings.HasPrefix(target.Path, "/") {
+		relative := strings.TrimPrefix(target.Path, rootPath())
+		candidates = append(candidates, path.Join(*destinationPath, relative))
+
+		for _, postDir := range strings.Split(*postsPath, ",") {
+			candidates = append(candidates, path.Join(postDir, relative))
+		}
+	} else {
+		candidates = append(candidates, path.Join(articleDir, target.Path), path.Join(*destinationPath, target.Path))
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// readImageSize returns the dimensions of an image file, reading each version of a file once
+func readImageSize(fileName string) imageSize {
+	var modified time.Time
+
+	if info, err := os.Stat(fileName); err == nil {
+		modified = info.ModTime()
+	}
+
+	if cached, found := imageSizes[fileName]; found && cached.modified.Equal(modified) {
+		return cached.imageSize
+	}
+
+	var size imageSize
+
+	if file, err := os.Open(fileName); err == nil {
+		if config, _, err := image.DecodeConfig(file); err == nil {
+			size = imageSize{config.Width, config.Height}
+		}
+
+		file.Close()
+	}
+
+	imageSizes[fileName] = cachedImageSize{imageSize: size, modified: modified}
+
+	return size
+}
+
+// lazyImageAttributes returns loading hints for an image, with its dimensions when the image
+// file is found and no size is given explicitly, so browsers can reserve space for it
+func lazyImageAttributes(link, articleDir string, sized bool) string {
+	if !*lazyImages {
+		return ""
+	}
+
+	attributes := ` loading="lazy" decoding="async"`
+
+	if sized {
+		return attributes
+	}
+
+	if fileName := localFile(link, articleDir); fileName != "" {
+		if size := readImageSize(fileName); size.width > 0 {
+			attributes += fmt.Sprintf(` width="%d" height="%d"`, size.width, size.height)
+		}
+	}
+
+	return attributes
+}
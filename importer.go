@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"gopkg.in/yaml.v3"
+
+	"macbirdie.net/blogger/post"
+)
+
+// ImportedFile is a source file converted from another site generator
+type ImportedFile struct {
+	Name    string
+	Article post.Article
+}
+
+// splitForeignFrontMatter separates a YAML (---), TOML (+++) or JSON front matter block from content
+func splitForeignFrontMatter(contents []byte) (map[string]interface{}, []byte, error) {
+	matter := map[string]interface{}{}
+	contents = bytes.TrimPrefix(contents, []byte("\xef\xbb\xbf"))
+
+	var delimiter string
+
+	switch {
+	case bytes.HasPrefix(contents, []byte("---")):
+		delimiter = "---"
+	case bytes.HasPrefix(contents, []byte("+++")):
+		delimiter = "+++"
+	case bytes.HasPrefix(contents, []byte("{")):
+		decoder := json.NewDecoder(bytes.NewReader(contents))
+		if err := decoder.Decode(&matter); err != nil {
+			return matter, contents, err
+		}
+		return matter, bytes.TrimLeft(contents[decoder.InputOffset():], "\r\n"), nil
+	default:
+		return matter, contents, nil
+	}
+
+	lines := bytes.SplitAfter(contents, []byte("\n"))
+	var header bytes.Buffer
+	bodyStart := -1
+
+	for index, line := range lines[1:] {
+		if strings.TrimSpace(string(line)) == delimiter {
+			bodyStart = index + 2
+			break
+		}
+		header.Write(line)
+	}
+
+	if bodyStart < 0 {
+		return matter, contents, fmt.Errorf("unterminated %s front matter", delimiter)
+	}
+
+	var err error
+
+	if delimiter == "+++" {
+		err = toml.Unmarshal(header.Bytes(), &matter)
+	} else {
+		err = yaml.Unmarshal(header.Bytes(), &matter)
+	}
+
+	return matter, bytes.Join(lines[bodyStart:], nil), err
+}
+
+func matterString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+}
+
+func matterStrings(value interface{}) []string {
+	var values []string
+
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s := matterString(item); s != "" {
+				values = append(values, s)
+			}
+		}
+	case string:
+		values = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+
+	return values
+}
+
+func matterTime(value interface{}) *time.Time {
+	switch v := value.(type) {
+	case time.Time:
+		return &v
+	case string:
+		if date, err := post.ParseDate(v); err == nil {
+			return &date
+		}
+	}
+
+	return nil
+}
+
+// tagName makes a foreign tag or category usable as a blogger tag, which can't contain separators
+func tagName(value string) string {
+	return strings.Join(strings.FieldsFunc(value, func(r rune) bool { return r == ' ' || r == ',' || r == ';' || r == '\t' }), "-")
+}
+
+// convertFrontMatter translates Hugo and Jekyll front matter conventions into an article
+func convertFrontMatter(matter map[string]interface{}, defaultType post.PageType) post.Article {
+	article := post.Article{Type: defaultType}
+
+	var keys []string
+	for key := range matter {
+		keys = append(keys, key)
+	}
+
+	// categories come before tags, keeping the result stable between runs
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := matter[key]
+
+		switch strings.ToLower(key) {
+		case "title":
+			article.Title = matterString(value)
+		case "author":
+			article.Author = matterString(value)
+		case "authors":
+			article.Author = strings.Join(matterStrings(value), ", ")
+		case "description", "summary", "excerpt":
+			article.Description = matterString(value)
+		case "date", "publishdate":
+			if date := matterTime(value); date != nil && article.DateModified == nil {
+				article.DateModified = date
+			}
+		case "lastmod", "updated", "last_modified_at":
+			article.DateUpdated = matterTime(value)
+		case "draft":
+			article.Draft = matterString(value) == "true"
+		case "published":
+			article.Draft = matterString(value) == "false"
+		case "tags", "categories", "category":
+			for _, tag := range matterStrings(value) {
+				article.Tags = append(article.Tags, post.MakeTag(tagName(tag)))
+			}
+		case "series":
+			if series := matterStrings(value); len(series) > 0 {
+				article.Series = series[0]
+			}
+		case "canonical", "canonicalurl", "canonical_url":
+			article.Canonical = matterString(value)
+		case "syndication":
+			article.Syndication = append(article.Syndication, matterStrings(value)...)
+		case "link", "externallink":
+			article.Link = matterString(value)
+		case "layout", "type":
+			switch matterString(value) {
+			case "page":
+				article.Type = post.Page
+			case "post", "posts":
+				article.Type = post.Post
+			}
+		case "aliases", "redirect_from":
+			article.Aliases = append(article.Aliases, matterStrings(value)...)
+		case "slug", "permalink", "url":
+			if article.Meta == nil {
+				article.Meta = map[string]string{}
+			}
+			article.Meta[strings.ToLower(key)] = strings.Join(matterStrings(value), " ")
+		}
+	}
+
+	return article
+}
+
+// importFile reads a single Hugo or Jekyll source file
+func importFile(source string, name string, defaultType post.PageType) (ImportedFile, error) {
+	contents, err := ioutil.ReadFile(source)
+
+	if err != nil {
+		return ImportedFile{}, err
+	}
+
+	matter, body, err := splitForeignFrontMatter(contents)
+
+	if err != nil {
+		return ImportedFile{}, err
+	}
+
+	article := convertFrontMatter(matter, defaultType)
+	article.RawContent = body
+
+	if slug := article.Meta["slug"]; slug != "" {
+		name = slug
+	}
+
+	name, nameDate := post.SplitDatedName(name)
+
+	if article.DateModified == nil {
+		article.DateModified = nameDate
+	}
+
+	return ImportedFile{Name: name, Article: article}, nil
+}
+
+// importSite walks a Hugo content directory or a Jekyll site and converts all Markdown files
+func importSite(generator string, sourceDir string) []ImportedFile {
+	var imported []ImportedFile
+
+	walkFunc := func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relative, _ := filepath.Rel(sourceDir, filePath)
+		relative = filepath.ToSlash(relative)
+
+		if info.IsDir() {
+			base := info.Name()
+			if filePath != sourceDir && (strings.HasPrefix(base, ".") || (generator == "jekyll" && base == "_site")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ext := path.Ext(info.Name())
+
+		if ext != ".md" && ext != ".markdown" {
+			return nil
+		}
+
+		name := strings.TrimSuffix(info.Name(), ext)
+		var defaultType post.PageType = post.Page
+
+		switch generator {
+		case "hugo":
+			section := strings.SplitN(relative, "/", 2)[0]
+			if section == "post" || section == "posts" || section == "blog" {
+				defaultType = post.Post
+			}
+
+			// section list pages have no content of their own, page bundles are named after their directory
+			if name == "_index" {
+				return nil
+			}
+
+			if name == "index" {
+				name = path.Base(path.Dir(relative))
+			}
+		case "jekyll":
+			if strings.Contains("/"+relative, "/_posts/") || strings.Contains("/"+relative, "/_drafts/") {
+				defaultType = post.Post
+			} else if strings.HasPrefix(relative, "_") {
+				return nil
+			}
+		}
+
+		file, importErr := importFile(filePath, name, defaultType)
+
+		if importErr != nil {
+			log.Printf("Skipping %v due to error: %v", filePath, importErr)
+			return nil
+		}
+
+		if generator == "jekyll" && strings.Contains("/"+relative, "/_drafts/") {
+			file.Article.Draft = true
+		}
+
+		imported = append(imported, file)
+
+		return nil
+	}
+
+	if err := filepath.Walk(sourceDir, walkFunc); err != nil {
+		log.Fatalf("Could not read %v: %v", sourceDir, err)
+	}
+
+	return imported
+}
+
+// writeImportedFiles writes converted articles as blogger source files
+func writeImportedFiles(imported []ImportedFile, outputDir string) {
+	os.MkdirAll(outputDir, os.ModePerm)
+
+	for _, file := range imported {
+		fileName := path.Join(outputDir, file.Name+".md")
+
+		if _, err := os.Stat(fileName); err == nil {
+			log.Printf("Skipping %v, file already exists", fileName)
+			continue
+		}
+
+		var buffer bytes.Buffer
+		writer := bufio.NewWriter(&buffer)
+		file.Article.WriteHeader(writer)
+		writer.Write(file.Article.RawContent)
+		writer.Flush()
+
+		if err := ioutil.WriteFile(fileName, buffer.Bytes(), 0644); err != nil {
+			log.Printf("Could not write file %v due to error: %v", fileName, err)
+		}
+	}
+
+	log.Printf("Imported %d files into %s", len(imported), outputDir)
+}
+
+// importCommand converts content of other site generators into blogger source files
+func importCommand(args []string) {
+	importFlags := flag.NewFlagSet("import", flag.ExitOnError)
+	from := importFlags.String("from", "", "Source format: hugo, jekyll, ghost, tumblr")
+	source := importFlags.String("source", "", "Source directory, Hugo content directory, Jekyll site root, Ghost JSON export or Tumblr JSON backup")
+	snippetLength := importFlags.Int("snippet-length", 280, "Untitled text posts up to this many characters are imported as snippets")
+	output := importFlags.String("output", strings.Split(*postsPath, ",")[0], "Directory imported posts are written to")
+	importFlags.Parse(args)
+
+	if *source == "" {
+		log.Fatal("-source is required for import")
+	}
+
+	switch *from {
+	case "hugo", "jekyll":
+		writeImportedFiles(importSite(*from, *source), *output)
+	case "ghost", "tumblr":
+		importJSON := importGhost
+		if *from == "tumblr" {
+			importJSON = importTumblr
+		}
+
+		imported, err := importJSON(*source, *snippetLength)
+
+		if err != nil {
+			log.Fatalf("Could not import %v: %v", *source, err)
+		}
+
+		writeImportedFiles(imported, *output)
+	default:
+		log.Fatalf("Unsupported import format %q", *from)
+	}
+}
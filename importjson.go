@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"macbirdie.net/blogger/post"
+)
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// GhostExport is the part of a Ghost JSON export used for importing
+type GhostExport struct {
+	DB []struct {
+		Data struct {
+			Posts []struct {
+				ID            string `json:"id"`
+				Title         string `json:"title"`
+				Slug          string `json:"slug"`
+				Markdown      string `json:"markdown"`
+				HTML          string `json:"html"`
+				Plaintext     string `json:"plaintext"`
+				Status        string `json:"status"`
+				Type          string `json:"type"`
+				Page          bool   `json:"page"`
+				CustomExcerpt string `json:"custom_excerpt"`
+				CanonicalURL  string `json:"canonical_url"`
+				AuthorID      string `json:"author_id"`
+				PublishedAt   string `json:"published_at"`
+				UpdatedAt     string `json:"updated_at"`
+			} `json:"posts"`
+			Tags []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+				Slug string `json:"slug"`
+			} `json:"tags"`
+			PostsTags []struct {
+				PostID string `json:"post_id"`
+				TagID  string `json:"tag_id"`
+			} `json:"posts_tags"`
+			PostsAuthors []struct {
+				PostID   string `json:"post_id"`
+				AuthorID string `json:"author_id"`
+			} `json:"posts_authors"`
+			Users []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"users"`
+		} `json:"data"`
+	} `json:"db"`
+}
+
+// TumblrPost is a post as returned by the Tumblr API and stored in Tumblr JSON backups
+type TumblrPost struct {
+	ID          json.Number `json:"id"`
+	Type        string      `json:"type"`
+	Slug        string      `json:"slug"`
+	Timestamp   int64       `json:"timestamp"`
+	State       string      `json:"state"`
+	Tags        []string    `json:"tags"`
+	Title       string      `json:"title"`
+	Body        string      `json:"body"`
+	Caption     string      `json:"caption"`
+	Text        string      `json:"text"`
+	Source      string      `json:"source"`
+	URL         string      `json:"url"`
+	Description string      `json:"description"`
+	Question    string      `json:"question"`
+	Answer      string      `json:"answer"`
+	Photos      []struct {
+		OriginalSize struct {
+			URL string `json:"url"`
+		} `json:"original_size"`
+	} `json:"photos"`
+}
+
+// plainTextLength counts characters of HTML or Markdown content without markup
+func plainTextLength(content string) int {
+	return utf8.RuneCountInString(strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(content, ""))))
+}
+
+// articleType picks a snippet for short untitled text, following how short posts are used on tumblelogs
+func articleType(title string, content string, snippetLength int) post.PageType {
+	if title == "" && plainTextLength(content) <= snippetLength {
+		return post.Snippet
+	}
+
+	return post.Post
+}
+
+func importName(slug string, fallback string) string {
+	if slug != "" {
+		return slug
+	}
+
+	return fallback
+}
+
+func importTags(names []string) []post.Tag {
+	var tags []post.Tag
+
+	for _, name := range names {
+		if name = tagName(name); name != "" {
+			tags = append(tags, post.MakeTag(name))
+		}
+	}
+
+	return tags
+}
+
+// importGhost converts posts and pages of a Ghost JSON export
+func importGhost(exportFile string, snippetLength int) ([]ImportedFile, error) {
+	contents, err := ioutil.ReadFile(exportFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var export GhostExport
+
+	if err := json.Unmarshal(contents, &export); err != nil {
+		return nil, err
+	}
+
+	var imported []ImportedFile
+
+	for _, db := range export.DB {
+		tagNames := map[string]string{}
+		for _, tag := range db.Data.Tags {
+			tagNames[tag.ID] = tag.Name
+		}
+
+		postTags := map[string][]string{}
+		for _, postTag := range db.Data.PostsTags {
+			postTags[postTag.PostID] = append(postTags[postTag.PostID], tagNames[postTag.TagID])
+		}
+
+		userNames := map[string]string{}
+		for _, user := range db.Data.Users {
+			userNames[user.ID] = user.Name
+		}
+
+		postAuthors := map[string]string{}
+		for _, postAuthor := range db.Data.PostsAuthors {
+			if _, found := postAuthors[postAuthor.PostID]; !found {
+				postAuthors[postAuthor.PostID] = postAuthor.AuthorID
+			}
+		}
+
+		for _, ghostPost := range db.Data.Posts {
+			content := ghostPost.Markdown
+
+			if content == "" {
+				content = ghostPost.HTML
+			}
+
+			article := post.Article{
+				Title:       ghostPost.Title,
+				Description: ghostPost.CustomExcerpt,
+				Canonical:   ghostPost.CanonicalURL,
+				Draft:       ghostPost.Status != "published",
+				Tags:        importTags(postTags[ghostPost.ID]),
+				RawContent:  []byte(content + "\n"),
+			}
+
+			authorID := ghostPost.AuthorID
+			if postAuthor, found := postAuthors[ghostPost.ID]; found {
+				authorID = postAuthor
+			}
+			article.Author = userNames[authorID]
+
+			if ghostPost.Page || ghostPost.Type == "page" {
+				article.Type = post.Page
+			} else {
+				article.Type = articleType(ghostPost.Title, content, snippetLength)
+			}
+
+			article.DateModified = matterTime(ghostPost.PublishedAt)
+			article.DateUpdated = matterTime(ghostPost.UpdatedAt)
+
+			imported = append(imported, ImportedFile{Name: importName(ghostPost.Slug, ghostPost.ID), Article: article})
+		}
+	}
+
+	return imported, nil
+}
+
+// readTumblrPosts reads a single post, a list of posts or an API response from a Tumblr JSON file
+func readTumblrPosts(fileName string) ([]TumblrPost, error) {
+	contents, err := ioutil.ReadFile(fileName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Response struct {
+			Posts []TumblrPost `json:"posts"`
+		} `json:"response"`
+	}
+
+	if json.Unmarshal(contents, &response) == nil && len(response.Response.Posts) > 0 {
+		return response.Response.Posts, nil
+	}
+
+	var posts []TumblrPost
+
+	if json.Unmarshal(contents, &posts) == nil {
+		return posts, nil
+	}
+
+	var single TumblrPost
+
+	if err := json.Unmarshal(contents, &single); err != nil {
+		return nil, err
+	}
+
+	return []TumblrPost{single}, nil
+}
+
+// tumblrContent builds Markdown-compatible content for each of the Tumblr post types
+func tumblrContent(tumblrPost TumblrPost) string {
+	switch tumblrPost.Type {
+	case "photo":
+		var content []string
+		for _, photo := range tumblrPost.Photos {
+			content = append(content, fmt.Sprintf("![](%s)", photo.OriginalSize.URL))
+		}
+		return strings.Join(append(content, tumblrPost.Caption), "\n\n")
+	case "quote":
+		return "> " + strings.Replace(tumblrPost.Text, "\n", "\n> ", -1) + "\n\n" + tumblrPost.Source
+	case "link":
+		return tumblrPost.Description
+	case "answer":
+		return "> " + tumblrPost.Question + "\n\n" + tumblrPost.Answer
+	case "text":
+		return tumblrPost.Body
+	}
+
+	return tumblrPost.Caption
+}
+
+// importTumblr converts posts of a Tumblr JSON backup, either a single file or a directory of files
+func importTumblr(source string, snippetLength int) ([]ImportedFile, error) {
+	var files []string
+
+	info, err := os.Stat(source)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		files, _ = filepath.Glob(path.Join(source, "*.json"))
+	} else {
+		files = []string{source}
+	}
+
+	var imported []ImportedFile
+
+	for _, fileName := range files {
+		posts, err := readTumblrPosts(fileName)
+
+		if err != nil {
+			log.Printf("Skipping %v due to error: %v", fileName, err)
+			continue
+		}
+
+		for _, tumblrPost := range posts {
+			content := tumblrContent(tumblrPost)
+			date := time.Unix(tumblrPost.Timestamp, 0).UTC()
+
+			article := post.Article{
+				Title:        tumblrPost.Title,
+				Draft:        tumblrPost.State != "" && tumblrPost.State != "published",
+				Tags:         importTags(tumblrPost.Tags),
+				DateModified: &date,
+				RawContent:   []byte(content + "\n"),
+				Type:         post.Post,
+			}
+
+			switch tumblrPost.Type {
+			case "text", "quote", "answer", "chat":
+				article.Type = articleType(tumblrPost.Title, content, snippetLength)
+			case "link":
+				article.Link = tumblrPost.URL
+			}
+
+			// slugs are not unique on Tumblr, the post identifier keeps file names apart
+			name := tumblrPost.ID.String()
+
+			if tumblrPost.Slug != "" {
+				name += "-" + tumblrPost.Slug
+			}
+
+			imported = append(imported, ImportedFile{Name: name, Article: article})
+		}
+	}
+
+	return imported, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"time"
+
+	"macbirdie.net/blogger/post"
+)
+
+// samplePost is the first post of a new site, formatted with its date
+const samplePost = `---
+title: Hello, world
+type: Post
+date: %s
+tags: blogging
+---
+This is the first post of the site. Posts are Markdown files in the posts directory, with
+front matter between the dashed lines.
+
+Run ` + "`blogger`" + ` in this directory to build the site into the destination directory, or
+` + "`blogger -listen`" + ` to rebuild it on every change.
+`
+
+// siteConfigTemplate is the configuration of a new site, formatted with its title
+const siteConfigTemplate = `title = %q
+posts = "posts"
+templates = "templates"
+static = "static"
+destination = "destination"
+extension = ".html"
+`
+
+// scaffoldFile writes a file of a new site, keeping one which already exists
+func scaffoldFile(fileName string, contents []byte) error {
+	if _, err := os.Stat(fileName); err == nil {
+		log.Printf("Keeping existing %v", fileName)
+		return nil
+	}
+
+	if err := os.MkdirAll(path.Dir(fileName), 0755); err != nil {
+		return err
+	}
+
+	log.Printf("Created %v", fileName)
+
+	return ioutil.WriteFile(fileName, contents, 0644)
+}
+
+// scaffoldSite creates the directories, configuration, starter templates and a sample post of a site
+func scaffoldSite(dir, title string) error {
+	for _, name := range []string{"posts", "templates", "static", "data", "destination"} {
+		if err := os.MkdirAll(path.Join(dir, name), 0755); err != nil {
+			return err
+		}
+	}
+
+	if err := scaffoldFile(path.Join(dir, defaultConfigFileNames[0]), []byte(fmt.Sprintf(siteConfigTemplate, title))); err != nil {
+		return err
+	}
+
+	theme, _ := fs.Sub(defaultTheme, "theme")
+
+	names, err := fs.Glob(theme, "*.html")
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		contents, err := fs.ReadFile(theme, name)
+
+		if err != nil {
+			return err
+		}
+
+		if err := scaffoldFile(path.Join(dir, "templates", name), contents); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now().In(post.Location)
+	postFileName := path.Join(dir, "posts", now.Format("2006-01-02")+"-hello-world.md")
+
+	return scaffoldFile(postFileName, []byte(fmt.Sprintf(samplePost, now.Format(post.DefaultDateFormat))))
+}
+
+// initCommand scaffolds a new site in a directory, the working directory by default
+func initCommand(args []string) {
+	initFlags := flag.NewFlagSet("init", flag.ExitOnError)
+	title := initFlags.String("title", *blogTitle, "Title of the new site")
+	initFlags.Parse(args)
+
+	dir := "."
+
+	if initFlags.NArg() > 0 {
+		dir = initFlags.Arg(0)
+	}
+
+	if err := scaffoldSite(dir, *title); err != nil {
+		log.Fatalf("Could not create site in %v: %v", dir, err)
+	}
+
+	log.Printf("Site created, run blogger in %v to build it", dir)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"macbirdie.net/blogger/post"
+)
+
+const linkTitlesCacheFileName = ".blogger-link-titles.json"
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+var linkClient = &http.Client{Timeout: 10 * time.Second}
+
+// permalink is the address an article stands for in feeds and listings, which for link posts is the page linked to
+func permalink(article *post.Article) string {
+	if article.Type == post.Link && article.Link != "" {
+		return article.Link
+	}
+
+	return absURL(article.FullPath())
+}
+
+// fetchedPage is the outcome of fetching a page
+type fetchedPage struct {
+	page []byte
+	err  error
+}
+
+// fetchedPages keeps pages fetched during a build, shared by all sites of a workspace, so a page
+// linked from several of them is fetched once
+var fetchedPages struct {
+	sync.Mutex
+	pages map[string]fetchedPage
+}
+
+// resetFetchedPages forgets pages fetched by an earlier build
+func resetFetchedPages() {
+	fetchedPages.Lock()
+	fetchedPages.pages = map[string]fetchedPage{}
+	fetchedPages.Unlock()
+}
+
+// fetchPage reads the beginning of a web page, enough to find its metadata, once per build
+func fetchPage(link string) ([]byte, error) {
+	fetchedPages.Lock()
+	fetched, found := fetchedPages.pages[link]
+	fetchedPages.Unlock()
+
+	if found {
+		return fetched.page, fetched.err
+	}
+
+	page, err := downloadPage(link)
+
+	fetchedPages.Lock()
+	if fetchedPages.pages != nil {
+		fetchedPages.pages[link] = fetchedPage{page: page, err: err}
+	}
+	fetchedPages.Unlock()
+
+	return page, err
+}
+
+// downloadPage reads the beginning of a web page
+func downloadPage(link string) ([]byte, error) {
+	response, err := linkClient.Get(link)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", link, response.Status)
+	}
+
+	return ioutil.ReadAll(io.LimitReader(response.Body, 1024*1024))
+}
+
+// pageTitle returns the title of a fetched page
+func pageTitle(page []byte) string {
+	matches := titlePattern.FindSubmatch(page)
+
+	if matches == nil {
+		return ""
+	}
+
+	return strings.Join(strings.Fields(html.UnescapeString(string(matches[1]))), " ")
+}
+
+// fetchTitle reads the title of a web page
+func fetchTitle(link string) (string, error) {
+	page, err := fetchPage(link)
+
+	if err != nil {
+		return "", err
+	}
+
+	title := pageTitle(page)
+
+	if title == "" {
+		return "", fmt.Errorf("%s has no title", link)
+	}
+
+	return title, nil
+}
+
+// fetchLinkTitles gives untitled link posts the title of the page they point at, stored as plain
+// text like titles from front matter. Titles are kept in a cache file in the working directory,
+// so pages are only fetched once.
+func fetchLinkTitles(articles post.Articles) {
+	if !*linkTitles {
+		return
+	}
+
+	titles := map[string]string{}
+
+	if cached, err := ioutil.ReadFile(linkTitlesCacheFileName); err == nil {
+		if err := json.Unmarshal(cached, &titles); err != nil {
+			log.Printf("Ignoring link title cache: %v", err)
+		}
+	}
+
+	fetched := false
+
+	for _, article := range articles {
+		if article.Type != post.Link || article.Title != "" || article.Link == "" {
+			continue
+		}
+
+		title, found := titles[article.Link]
+
+		if !found {
+			var err error
+
+			if title, err = fetchTitle(article.Link); err != nil {
+				log.Printf("Could not fetch title of %v: %v", article.Link, err)
+				continue
+			}
+
+			titles[article.Link] = title
+			fetched = true
+		}
+
+		article.Title = title
+	}
+
+	if !fetched {
+		return
+	}
+
+	if cache, err := json.MarshalIndent(titles, "", "  "); err == nil {
+		if err := ioutil.WriteFile(linkTitlesCacheFileName, cache, 0644); err != nil {
+			log.Printf("Could not store link title cache: %v", err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var anchorTagPattern = regexp.MustCompile(`(?i)<a\s[^>]*>`)
+
+var hrefPattern = regexp.MustCompile(`(?i)\shref\s*=\s*"([^"]*)"`)
+
+// linkDecoration holds the options of the -external-links flag
+type linkDecoration struct {
+	class  bool
+	rel    []string
+	target string
+}
+
+func parseLinkDecoration(specification string) linkDecoration {
+	var decoration linkDecoration
+
+	for _, option := range strings.FieldsFunc(specification, func(r rune) bool { return r == ',' || r == ' ' }) {
+		switch option {
+		case "class":
+			decoration.class = true
+		case "blank":
+			decoration.target = "_blank"
+		case "noopener", "noreferrer", "nofollow", "external":
+			decoration.rel = append(decoration.rel, option)
+		}
+	}
+
+	return decoration
+}
+
+// setAttribute adds a value to an attribute of a tag, merging it with the values already there
+func setAttribute(tag, name, value string, merge bool) string {
+	pattern := regexp.MustCompile(`(?i)\s` + name + `\s*=\s*"([^"]*)"`)
+
+	if match := pattern.FindStringSubmatchIndex(tag); match != nil {
+		existing := tag[match[2]:match[3]]
+
+		if !merge {
+			return tag
+		}
+
+		for _, field := range strings.Fields(existing) {
+			if field == value {
+				return tag
+			}
+		}
+
+		return tag[:match[3]] + " " + value + tag[match[3]:]
+	}
+
+	return strings.TrimSuffix(tag, ">") + ` ` + name + `="` + value + `">`
+}
+
+// isExternalLink tells if a link points outside the site, based on the host of -root when it has one
+func isExternalLink(link string) bool {
+	target, err := url.Parse(link)
+
+	if err != nil || target.Host == "" || (target.Scheme != "" && target.Scheme != "http" && target.Scheme != "https") {
+		return false
+	}
+
+	if root, err := url.Parse(*siteRoot); err == nil && root.Host != "" {
+		return !strings.EqualFold(target.Host, root.Host)
+	}
+
+	return true
+}
+
+// decorateLinks marks outbound links in rendered content according to -external-links
+// and prefixes root-relative links left by raw HTML and shortcodes with the -root path, which
+// happens whether or not links are decorated
+func decorateLinks(content string, specification string) string {
+	decoration := parseLinkDecoration(specification)
+	root := rootPath()
+
+	if specification == "" && root == "/" {
+		return content
+	}
+
+	return anchorTagPattern.ReplaceAllStringFunc(content, func(tag string) string {
+		match := hrefPattern.FindStringSubmatchIndex(tag)
+
+		if match == nil {
+			return tag
+		}
+
+		link := tag[match[2]:match[3]]
+
+		if strings.HasPrefix(link, "/") && !strings.HasPrefix(link, "//") {
+			if root != "/" && link != root && !strings.HasPrefix(link, root+"/") {
+				tag = tag[:match[2]] + sitePath(link) + tag[match[3]:]
+			}
+
+			return tag
+		}
+
+		if specification == "" || !isExternalLink(link) {
+			return tag
+		}
+
+		if decoration.class {
+			tag = setAttribute(tag, "class", "external-link", true)
+		}
+
+		for _, rel := range decoration.rel {
+			tag = setAttribute(tag, "rel", rel, true)
+		}
+
+		if decoration.target != "" {
+			tag = setAttribute(tag, "target", decoration.target, false)
+		}
+
+		return tag
+	})
+}
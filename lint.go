@@ -0,0 +1,340 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"macbirdie.net/blogger/post"
+)
+
+// layoutMistakePattern matches date layout tokens of other languages, which Go prints verbatim
+var layoutMistakePattern = regexp.MustCompile(`YYYY|yyyy|YY|DD|dd|HH|hh|mm|ss|%[a-zA-Z]`)
+
+// dateLayoutFuncs are template functions taking a Go date layout as their first argument
+var dateLayoutFuncs = map[string]bool{"dateFormat": true}
+
+// LintIssue is a problem found in a template
+type LintIssue struct {
+	Template string
+	Context  string
+	Problem  string
+}
+
+func (l LintIssue) String() string {
+	if l.Context == "" {
+		return fmt.Sprintf("%s: %s", l.Template, l.Problem)
+	}
+
+	return fmt.Sprintf("%s (%s): %s", l.Template, l.Context, l.Problem)
+}
+
+// lintContext is a fake context a template is executed with, named for reporting
+type lintContext struct {
+	name    string
+	context PageContext
+}
+
+// lintArticles returns an article of every type, with the fields templates commonly use filled in
+func lintArticles() post.Articles {
+	now := time.Now().In(post.Location)
+	var articles post.Articles
+
+	for _, articleType := range []post.PageType{post.Post, post.Page, post.Snippet, post.Link} {
+		name := strings.ToLower(string(articleType))
+
+		articles = append(articles, &post.Article{
+			Author:       "Author",
+			DateModified: &now,
+			DateUpdated:  &now,
+			Title:        "Example " + name,
+			Content:      "<p>Example content</p>",
+			Description:  "Example description",
+			Filename:     "example-" + name + *destinationExt,
+			Identifier:   "example-" + name,
+			Type:         articleType,
+			Tags:         []post.Tag{post.MakeTag("example")},
+			Meta:         map[string]string{},
+			Taxonomies:   map[string][]post.Tag{},
+		})
+	}
+
+	articles[3].Link = "https://example.com/"
+
+	return articles
+}
+
+// lintContexts returns fake contexts of every kind of page a template is executed for
+func lintContexts(kinds []string, data map[string]interface{}) []lintContext {
+	now := time.Now().In(post.Location)
+	articles := lintArticles()
+	tag := articles[0].Tags[0]
+	cloud := tagCloud(articles)
+
+	var contexts []lintContext
+
+	for _, kind := range kinds {
+		switch kind {
+		case "index":
+			context := pageContext(data, now)
+			context.Home = true
+			context.Articles = articles
+			context.Tags = cloud
+			contexts = append(contexts, lintContext{"index", context})
+		case "article":
+			for _, article := range articles {
+				context := pageContext(data, now)
+				context.Article = article
+				context.Title = article.Title + " – " + *blogTitle
+				contexts = append(contexts, lintContext{strings.ToLower(string(article.Type)), context})
+			}
+		case "tag":
+			context := pageContext(data, now)
+			context.Articles = articles
+			context.Title = "Tag: " + tag.Name + " – " + *blogTitle
+			context.Tag = &tag
+			context.Tags = cloud
+			contexts = append(contexts, lintContext{"tag", context})
+		case "term":
+			context := pageContext(data, now)
+			context.Articles = articles
+			context.Title = "category: " + tag.Name + " – " + *blogTitle
+			context.Taxonomy = "category"
+			context.Term = &tag
+			contexts = append(contexts, lintContext{"taxonomy", context})
+		case "feed":
+			context := pageContext(data, now)
+			context.Home = true
+			context.File = "index.xml"
+			context.Articles = articles
+			contexts = append(contexts, lintContext{"feed", context})
+
+			context.File = "podcast.xml"
+			context.Podcast = true
+			contexts = append(contexts, lintContext{"podcast", context})
+		case "blogroll":
+			context := pageContext(data, now)
+			context.Title = "Blogroll – " + *blogTitle
+			context.Blogroll = []BlogrollEntry{{Title: "Example", Feed: "https://example.com/feed.xml", URL: "https://example.com/"}}
+			contexts = append(contexts, lintContext{"blogroll", context})
+		case "error":
+			context := pageContext(data, now)
+			context.Title = "Page not found – " + *blogTitle
+			contexts = append(contexts, lintContext{"error", context})
+		case "humans":
+			context := pageContext(data, now)
+			context.Authors = []string{"Author"}
+			contexts = append(contexts, lintContext{"humans", context})
+		}
+	}
+
+	return contexts
+}
+
+// dateLayoutIssues finds date layouts in a template which don't format anything
+func dateLayoutIssues(tree *parse.Tree) []string {
+	var problems []string
+	reference := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch node := node.(type) {
+		case *parse.ListNode:
+			if node == nil {
+				return
+			}
+			for _, child := range node.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(node.Pipe)
+		case *parse.IfNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		case *parse.RangeNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		case *parse.WithNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		case *parse.TemplateNode:
+			walk(node.Pipe)
+		case *parse.PipeNode:
+			if node == nil {
+				return
+			}
+			for _, command := range node.Cmds {
+				walk(command)
+			}
+		case *parse.CommandNode:
+			for _, arg := range node.Args {
+				walk(arg)
+			}
+
+			if len(node.Args) < 2 {
+				return
+			}
+
+			function, isFunction := node.Args[0].(*parse.IdentifierNode)
+			layout, isString := node.Args[1].(*parse.StringNode)
+
+			if !isFunction || !isString || !dateLayoutFuncs[function.Ident] {
+				return
+			}
+
+			if reference.Format(layout.Text) == layout.Text {
+				problems = append(problems, fmt.Sprintf("date layout %q of %s has no date fields, layouts are written as Mon Jan 2 15:04:05 2006", layout.Text, function.Ident))
+			} else if mistake := layoutMistakePattern.FindString(layout.Text); mistake != "" {
+				problems = append(problems, fmt.Sprintf("date layout %q of %s contains %q, which is printed as is", layout.Text, function.Ident, mistake))
+			}
+		}
+	}
+
+	walk(tree.Root)
+
+	return problems
+}
+
+// lintTemplate parses a template strictly and executes it with every context. Further files
+// are parsed into it, like the blocks of a page kind into the base layout.
+func lintTemplate(fileName string, funcMap template.FuncMap, contexts []lintContext, blockFileNames ...string) []LintIssue {
+	name := path.Base(fileName)
+	parsed, err := template.New(name).Funcs(funcMap).Option("missingkey=error").ParseFiles(append([]string{fileName}, blockFileNames...)...)
+
+	if len(blockFileNames) > 0 {
+		name = path.Base(blockFileNames[0])
+	}
+
+	if err != nil {
+		return []LintIssue{{Template: name, Problem: err.Error()}}
+	}
+
+	var issues []LintIssue
+
+	for _, tree := range parsed.Templates() {
+		for _, problem := range dateLayoutIssues(tree.Tree) {
+			issues = append(issues, LintIssue{Template: name, Problem: problem})
+		}
+	}
+
+	for _, context := range contexts {
+		if err := parsed.Execute(ioutil.Discard, context.context); err != nil {
+			issues = append(issues, LintIssue{Template: name, Context: context.name, Problem: err.Error()})
+		}
+	}
+
+	return issues
+}
+
+// lintTemplates checks every template in the templates directory, reporting templates which
+// aren't used by the generator too
+func lintTemplates() []LintIssue {
+	funcMap := templateFuncs()
+	data := loadData()
+
+	roles := map[string][]string{
+		templateFileName:         {"index", "article", "tag", "term"},
+		rssTemplateFileName:      {"feed"},
+		snippetTemplateFileName:  {"article"},
+		podcastTemplateFileName:  {"feed"},
+		humansTemplateFileName:   {"humans"},
+		blogrollTemplateFileName: {"blogroll"},
+	}
+
+	for _, errorPage := range errorPages {
+		roles[errorPage.Template] = []string{"error"}
+	}
+
+	for _, format := range parseOutputFormats(funcMap) {
+		roles["format-"+format.Name+".html"] = []string{"article"}
+	}
+
+	baseFileName := path.Join(*templatesPath, baseTemplateFileName)
+	_, baseErr := os.Stat(baseFileName)
+	blocks := baseErr == nil
+
+	if blocks {
+		// kinds without a template of their own are rendered with the base layout alone
+		delete(roles, templateFileName)
+		roles[baseTemplateFileName] = nil
+
+		for _, kind := range pageKinds {
+			if _, err := os.Stat(path.Join(*templatesPath, kind+".html")); err == nil {
+				roles[kind+".html"] = []string{kind}
+			} else {
+				roles[baseTemplateFileName] = append(roles[baseTemplateFileName], kind)
+			}
+		}
+	}
+
+	var issues []LintIssue
+	var names []string
+
+	for name := range roles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fileName := path.Join(*templatesPath, name)
+
+		if blocks && containsString(pageKinds, strings.TrimSuffix(name, ".html")) {
+			issues = append(issues, lintTemplate(baseFileName, funcMap, lintContexts(roles[name], data), fileName)...)
+			continue
+		}
+
+		if _, err := os.Stat(fileName); err != nil {
+			if (name == templateFileName && !blocks) || name == rssTemplateFileName || strings.HasPrefix(name, "format-") {
+				issues = append(issues, LintIssue{Template: name, Problem: "missing template"})
+			}
+			continue
+		}
+
+		issues = append(issues, lintTemplate(fileName, funcMap, lintContexts(roles[name], data))...)
+	}
+
+	files, _ := ioutil.ReadDir(*templatesPath)
+
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") || containsString(cardBackgroundFileNames, file.Name()) {
+			continue
+		}
+
+		if _, used := roles[file.Name()]; !used {
+			issues = append(issues, LintIssue{Template: file.Name(), Problem: "not used by any page"})
+		}
+	}
+
+	return issues
+}
+
+// lint reports problems in templates which would otherwise show up as missing output
+func lint(args []string) {
+	lintFlags := flag.NewFlagSet("lint", flag.ExitOnError)
+	lintFlags.Parse(args)
+
+	issues := lintTemplates()
+
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+
+	if len(issues) > 0 {
+		log.Fatalf("Found %d template problems", len(issues))
+	}
+
+	log.Println("No template problems found")
+}
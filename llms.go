@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html"
+	"log"
+	"path"
+	"regexp"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+var llmsIndex = flag.Bool("llms-txt", false, "Write llms.txt, a plain-text index of published articles for language models and text readers")
+var llmsArchive = flag.Bool("llms-full", false, "Write llms-full.txt, the plain text of all published articles in one file")
+
+var blockEndPattern = regexp.MustCompile(`(?i)</(p|h[1-6]|li|pre|blockquote|div|tr|table|figure)>|<br\s*/?>|<hr\s*/?>`)
+var listItemPattern = regexp.MustCompile(`(?i)<li[^>]*>`)
+var blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+
+// llmsSections are the headings of llms.txt, listing articles of each type in this order
+var llmsSections = []struct {
+	heading string
+	kind    post.PageType
+}{
+	{"Posts", post.Post},
+	{"Pages", post.Page},
+	{"Links", post.Link},
+	{"Snippets", post.Snippet},
+}
+
+// plainText turns rendered content into plain text, keeping paragraphs and list items on lines of
+// their own
+func plainText(content string) string {
+	text := listItemPattern.ReplaceAllString(content, "- ")
+	text = blockEndPattern.ReplaceAllString(text, "\n\n")
+	text = html.UnescapeString(tagPattern.ReplaceAllString(text, ""))
+
+	var lines []string
+
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// llmsArticles returns the articles readers can see, leaving out protected ones
+func llmsArticles(articles post.Articles) post.Articles {
+	var published post.Articles
+
+	for _, article := range articles {
+		if !article.Draft && !article.Unlisted && !expired(article) && article.Password == "" {
+			published = append(published, article)
+		}
+	}
+
+	return published
+}
+
+// llmsKind is the type an article is listed under, untyped ones being listed as posts
+func llmsKind(article *post.Article) post.PageType {
+	for _, section := range llmsSections {
+		if article.Type == section.kind {
+			return article.Type
+		}
+	}
+
+	return post.Post
+}
+
+// llmsTitle is the title of an article in plain text listings
+func llmsTitle(article *post.Article) string {
+	if article.Title != "" {
+		return article.Title
+	}
+
+	return article.Identifier
+}
+
+// writeLLMSText writes llms.txt and llms-full.txt for the published articles, linking the
+// Markdown sources when they are published
+func writeLLMSText(destinationDir string, articles post.Articles) {
+	if !*llmsIndex && !*llmsArchive {
+		return
+	}
+
+	published := llmsArticles(articles)
+	description, _ := currentSite.Params["description"].(string)
+
+	if *llmsIndex {
+		var buffer bytes.Buffer
+		fmt.Fprintf(&buffer, "# %s\n", *blogTitle)
+
+		if description != "" {
+			fmt.Fprintf(&buffer, "\n> %s\n", description)
+		}
+
+		for _, section := range llmsSections {
+			var entries []string
+
+			for _, article := range published {
+				if llmsKind(article) != section.kind {
+					continue
+				}
+
+				address := articleURL(article)
+
+				if hasPublishedSource(article) {
+					address = absURL(markdownSourcePath(article))
+				}
+
+				entry := fmt.Sprintf("- [%s](%s)", markdownLinkText.Replace(llmsTitle(article)), address)
+
+				if article.Description != "" {
+					entry += ": " + article.Description
+				}
+
+				entries = append(entries, entry)
+			}
+
+			if len(entries) > 0 {
+				fmt.Fprintf(&buffer, "\n## %s\n\n%s\n", section.heading, strings.Join(entries, "\n"))
+			}
+		}
+
+		writeTextOutput(path.Join(destinationDir, "llms.txt"), buffer.Bytes())
+	}
+
+	if *llmsArchive {
+		var buffer bytes.Buffer
+		fmt.Fprintf(&buffer, "# %s\n", *blogTitle)
+
+		for _, article := range published {
+			fmt.Fprintf(&buffer, "\n---\n\n# %s\n\n%s\n%s\n\n%s\n", llmsTitle(article), articleURL(article),
+				article.DateModified.In(post.Location).Format("2006-01-02"), plainText(article.Content))
+		}
+
+		writeTextOutput(path.Join(destinationDir, "llms-full.txt"), buffer.Bytes())
+	}
+}
+
+// writeTextOutput writes a generated text file, logging failures
+func writeTextOutput(fileName string, contents []byte) {
+	if writeErr := writeFile(fileName, contents); writeErr != nil {
+		log.Printf("Could not write file %v due to error: %v", fileName, writeErr)
+	}
+}
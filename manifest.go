@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var manifestFile = flag.String("manifest", "manifest.json", "file in the destination mapping sources to their outputs and content hashes, empty to skip")
+var changedList = flag.String("changed", "", "file listing outputs changed by the build, one per line, - for the standard output")
+
+// buildManifest maps source files to the paths of outputs generated from them, relative to the
+// destination, and their SHA-256 hashes. Outputs of the whole site, like the index, have no source.
+type buildManifest map[string]map[string]string
+
+// outputs collects the manifest of the build in progress
+var outputs struct {
+	sync.Mutex
+	root     string
+	source   string
+	manifest buildManifest
+}
+
+// startManifest starts collecting outputs written into a destination directory
+func startManifest(destination string) {
+	outputs.Lock()
+	defer outputs.Unlock()
+
+	outputs.root = destination
+	outputs.source = ""
+	outputs.manifest = buildManifest{}
+}
+
+// setOutputSource attributes outputs written from now on to a source file
+func setOutputSource(source string) {
+	outputs.Lock()
+	defer outputs.Unlock()
+
+	outputs.source = source
+}
+
+// outputSource is the source file outputs are currently attributed to
+func outputSource() string {
+	outputs.Lock()
+	defer outputs.Unlock()
+
+	return outputs.source
+}
+
+// recordOutput adds a written file to the manifest. Files outside the destination, like the ones
+// seeding a staging directory, are left out.
+func recordOutput(fileName, source, hash string) {
+	outputs.Lock()
+	defer outputs.Unlock()
+
+	if outputs.manifest == nil {
+		return
+	}
+
+	relative, err := filepath.Rel(outputs.root, fileName)
+
+	if err != nil || strings.HasPrefix(relative, "..") {
+		return
+	}
+
+	relative = filepath.ToSlash(relative)
+
+	if relative == *manifestFile {
+		return
+	}
+
+	if outputs.manifest[source] == nil {
+		outputs.manifest[source] = map[string]string{}
+	}
+
+	outputs.manifest[source][relative] = hash
+}
+
+// hashes flattens a manifest into content hashes by output path
+func (m buildManifest) hashes() map[string]string {
+	hashes := map[string]string{}
+
+	for _, sourceOutputs := range m {
+		for output, hash := range sourceOutputs {
+			hashes[output] = hash
+		}
+	}
+
+	return hashes
+}
+
+// changedOutputs lists outputs that are new, have different content or are gone since the previous build
+func changedOutputs(previous, current buildManifest) []string {
+	previousHashes := previous.hashes()
+	currentHashes := current.hashes()
+
+	var changed []string
+
+	for output, hash := range currentHashes {
+		if previousHashes[output] != hash {
+			changed = append(changed, output)
+		}
+	}
+
+	for output := range previousHashes {
+		if _, found := currentHashes[output]; !found {
+			changed = append(changed, output)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+// finishManifest writes the manifest of the build into the destination and lists the outputs
+// changed since the previous manifest was written
+func finishManifest() {
+	outputs.Lock()
+	root := outputs.root
+	current := outputs.manifest
+	outputs.Unlock()
+
+	if *manifestFile == "" {
+		if *changedList != "" {
+			log.Printf("Changed outputs can't be listed without -manifest")
+		}
+
+		if *signingKey != "" {
+			log.Printf("Outputs can't be signed without -manifest")
+		}
+
+		return
+	}
+
+	manifestFileName := path.Join(root, *manifestFile)
+	previous := buildManifest{}
+
+	if contents, err := ioutil.ReadFile(manifestFileName); err == nil {
+		if err := json.Unmarshal(contents, &previous); err != nil {
+			log.Printf("Ignoring unreadable manifest %v: %v", manifestFileName, err)
+		}
+	}
+
+	contents, err := json.MarshalIndent(current, "", "  ")
+
+	if err == nil {
+		err = writeFile(manifestFileName, contents)
+	}
+
+	if err != nil {
+		log.Printf("Could not write file %v due to error: %v", manifestFileName, err)
+	} else {
+		signManifest(manifestFileName, contents)
+	}
+
+	if *changedList == "" {
+		return
+	}
+
+	list := strings.Join(changedOutputs(previous, current), "\n")
+
+	if list != "" {
+		list += "\n"
+	}
+
+	if *changedList == "-" {
+		os.Stdout.WriteString(list)
+	} else if err := ioutil.WriteFile(*changedList, []byte(list), 0644); err != nil {
+		log.Printf("Could not write file %v due to error: %v", *changedList, err)
+	}
+}
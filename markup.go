@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// markupTranslators turn sources in other lightweight markup languages into Markdown, so they are
+// rendered by the same renderer as Markdown posts. They are keyed by renderer name and cover the
+// commonly used subset of each language; converters given with -converters take precedence.
+var markupTranslators = map[string]func([]byte) []byte{
+	"rst":      rstToMarkdown,
+	"asciidoc": asciidocToMarkdown,
+}
+
+var rstDirectivePattern = regexp.MustCompile(`^\.\.\s+([\w-]+)::\s*(.*)$`)
+var rstOptionPattern = regexp.MustCompile(`^:([\w-]+):\s*(.*)$`)
+var rstLinkPattern = regexp.MustCompile("`([^`<]+?)\\s*<([^>`]+)>`__?")
+var rstRolePattern = regexp.MustCompile(":[\\w-]+:`([^`]+)`")
+var rstLiteralPattern = regexp.MustCompile("``([^`]+)``")
+
+var asciidocHeadingPattern = regexp.MustCompile(`^(={1,6})\s+(.*)$`)
+var asciidocLinkPattern = regexp.MustCompile(`(?:link:)?((?:https?://|mailto:)?[^\s\[\]]+)\[([^\]]*)\]`)
+var asciidocImagePattern = regexp.MustCompile(`image::?([^\s\[\]]+)\[([^\]]*)\]`)
+var asciidocStrongPattern = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*([^\w*]|$)`)
+var asciidocEmphasisPattern = regexp.MustCompile(`(^|[^\w_])_([^_\s](?:[^_]*[^_\s])?)_([^\w_]|$)`)
+var asciidocSourcePattern = regexp.MustCompile(`^\[source(?:,\s*([\w+-]+))?.*\]$`)
+var asciidocAdmonitionPattern = regexp.MustCompile(`^(NOTE|TIP|IMPORTANT|WARNING|CAUTION):\s+(.*)$`)
+var asciidocListPattern = regexp.MustCompile(`^(\*+|\.+)\s+(.*)$`)
+
+// rstUnderline tells whether a line adorns a section title, repeating one punctuation character
+func rstUnderline(line string) bool {
+	line = strings.TrimRight(line, " \t")
+
+	if len(line) < 2 || !strings.ContainsRune("=-~^\"'`#*+:.", rune(line[0])) {
+		return false
+	}
+
+	return strings.Count(line, line[:1]) == len(line)
+}
+
+// outsideCode applies a replacement to the parts of a line which are not inline code spans
+func outsideCode(line string, replace func(string) string) string {
+	parts := strings.Split(line, "`")
+
+	for index := range parts {
+		if index%2 == 0 {
+			parts[index] = replace(parts[index])
+		}
+	}
+
+	return strings.Join(parts, "`")
+}
+
+// indentedBlock returns the lines of a block indented below a line, and the index past it
+func indentedBlock(lines []string, start int) ([]string, int) {
+	var block []string
+	index := start
+
+	for ; index < len(lines); index++ {
+		line := lines[index]
+
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			break
+		}
+
+		block = append(block, line)
+	}
+
+	for len(block) > 0 && strings.TrimSpace(block[len(block)-1]) == "" {
+		block = block[:len(block)-1]
+		index--
+	}
+
+	indent := -1
+
+	for _, line := range block {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if lineIndent := len(line) - len(strings.TrimLeft(line, " \t")); indent < 0 || lineIndent < indent {
+			indent = lineIndent
+		}
+	}
+
+	for lineIndex, line := range block {
+		if len(line) >= indent && indent > 0 {
+			block[lineIndex] = line[indent:]
+		}
+	}
+
+	return block, index
+}
+
+// fence wraps lines in a fenced code block
+func fence(language string, lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+
+	return append(append([]string{"```" + language}, lines...), "```", "")
+}
+
+// rstInline translates reStructuredText inline markup
+func rstInline(line string) string {
+	line = rstLinkPattern.ReplaceAllString(line, "[$1]($2)")
+	line = rstRolePattern.ReplaceAllString(line, "`$1`")
+	line = rstLiteralPattern.ReplaceAllString(line, "`$1`")
+
+	return line
+}
+
+// rstToMarkdown translates reStructuredText sections, paragraphs, lists, literal blocks, code,
+// image and comment directives, and inline markup
+func rstToMarkdown(source []byte) []byte {
+	lines := strings.Split(strings.Replace(string(source), "\r\n", "\n", -1), "\n")
+	var output []string
+	levels := map[string]int{}
+
+	headingLevel := func(style string) int {
+		if level, found := levels[style]; found {
+			return level
+		}
+
+		levels[style] = len(levels) + 1
+
+		return levels[style]
+	}
+
+	for index := 0; index < len(lines); index++ {
+		line := lines[index]
+		trimmed := strings.TrimSpace(line)
+
+		// a title with an overline
+		if rstUnderline(line) && index+2 < len(lines) && strings.TrimSpace(lines[index+1]) != "" && strings.TrimSpace(lines[index+2]) == trimmed {
+			output = append(output, strings.Repeat("#", headingLevel("over"+trimmed[:1]))+" "+rstInline(strings.TrimSpace(lines[index+1])), "")
+			index += 2
+			continue
+		}
+
+		// a title with an underline
+		if trimmed != "" && index+1 < len(lines) && rstUnderline(lines[index+1]) && len(strings.TrimSpace(lines[index+1])) >= len(trimmed) && !strings.HasPrefix(line, " ") {
+			output = append(output, strings.Repeat("#", headingLevel(strings.TrimSpace(lines[index+1])[:1]))+" "+rstInline(trimmed), "")
+			index++
+			continue
+		}
+
+		if match := rstDirectivePattern.FindStringSubmatch(trimmed); match != nil && !strings.HasPrefix(line, " ") {
+			block, next := indentedBlock(lines, index+1)
+			options := map[string]string{}
+			var content []string
+
+			for blockIndex, blockLine := range block {
+				if option := rstOptionPattern.FindStringSubmatch(blockLine); option != nil && len(content) == 0 {
+					options[option[1]] = option[2]
+					continue
+				}
+
+				content = block[blockIndex:]
+				break
+			}
+
+			switch match[1] {
+			case "code", "code-block", "sourcecode":
+				output = append(output, fence(match[2], content)...)
+			case "image", "figure":
+				output = append(output, "!["+options["alt"]+"]("+match[2]+")", "")
+			case "note", "tip", "important", "warning", "caution", "attention", "danger", "hint":
+				quoted := []string{"> **" + strings.Title(match[1]) + ":** " + rstInline(match[2])}
+				for _, contentLine := range content {
+					quoted = append(quoted, "> "+rstInline(contentLine))
+				}
+				output = append(output, append(quoted, "")...)
+			}
+
+			index = next - 1
+			continue
+		}
+
+		// comments and other explicit markup
+		if strings.HasPrefix(trimmed, "..") && !strings.HasPrefix(line, " ") {
+			_, next := indentedBlock(lines, index+1)
+			index = next - 1
+			continue
+		}
+
+		// a paragraph introducing a literal block
+		if strings.HasSuffix(trimmed, "::") && !strings.HasPrefix(line, " ") {
+			if paragraph := strings.TrimSuffix(line, "::"); strings.TrimSpace(paragraph) != "" {
+				output = append(output, rstInline(strings.TrimRight(paragraph, " ")+":"), "")
+			}
+
+			block, next := indentedBlock(lines, index+1)
+			output = append(output, fence("", block)...)
+			index = next - 1
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "#. ") {
+			line = strings.Replace(line, "#. ", "1. ", 1)
+		}
+
+		output = append(output, rstInline(line))
+	}
+
+	return []byte(strings.Join(output, "\n"))
+}
+
+// asciidocInline translates AsciiDoc inline markup
+func asciidocInline(line string) string {
+	return outsideCode(line, func(text string) string {
+		text = asciidocImagePattern.ReplaceAllString(text, "![$2]($1)")
+		text = asciidocLinkPattern.ReplaceAllStringFunc(text, func(link string) string {
+			if strings.HasPrefix(link, "![") {
+				return link
+			}
+
+			match := asciidocLinkPattern.FindStringSubmatch(link)
+
+			if !strings.HasPrefix(link, "link:") && !strings.Contains(match[1], "://") && !strings.HasPrefix(match[1], "mailto:") {
+				return link
+			}
+
+			title := match[2]
+
+			if title == "" {
+				title = match[1]
+			}
+
+			return "[" + title + "](" + match[1] + ")"
+		})
+		text = asciidocStrongPattern.ReplaceAllString(text, "$1**$2**$3")
+		text = asciidocEmphasisPattern.ReplaceAllString(text, "$1*$2*$3")
+
+		return text
+	})
+}
+
+// asciidocToMarkdown translates AsciiDoc sections, paragraphs, lists, listing, literal and quote
+// blocks, admonitions, images, comments and inline markup. Attribute entries are left out.
+func asciidocToMarkdown(source []byte) []byte {
+	lines := strings.Split(strings.Replace(string(source), "\r\n", "\n", -1), "\n")
+	var output []string
+	language := ""
+	inList := false
+
+	for index := 0; index < len(lines); index++ {
+		line := lines[index]
+		trimmed := strings.TrimSpace(line)
+
+		// blocks after a list would otherwise continue its last item
+		if inList && trimmed != "" && trimmed != "+" && !asciidocListPattern.MatchString(line) {
+			output = append(output, "", "<!-- -->", "")
+			inList = false
+		}
+
+		switch {
+		case trimmed == "----" || trimmed == "....":
+			end := index + 1
+
+			for end < len(lines) && strings.TrimSpace(lines[end]) != trimmed {
+				end++
+			}
+
+			output = append(output, fence(language, append([]string{}, lines[index+1:minInt(end, len(lines))]...))...)
+			language = ""
+			index = end
+		case trimmed == "____":
+			end := index + 1
+
+			for end < len(lines) && strings.TrimSpace(lines[end]) != trimmed {
+				output = append(output, "> "+asciidocInline(lines[end]))
+				end++
+			}
+
+			output = append(output, "")
+			index = end
+		case trimmed == "////":
+			for index++; index < len(lines) && strings.TrimSpace(lines[index]) != "////"; index++ {
+			}
+		case strings.HasPrefix(trimmed, "//"):
+		case asciidocSourcePattern.MatchString(trimmed):
+			language = asciidocSourcePattern.FindStringSubmatch(trimmed)[1]
+		case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") && !strings.Contains(trimmed, "]("):
+		case strings.HasPrefix(line, ":") && rstOptionPattern.MatchString(line):
+		case asciidocHeadingPattern.MatchString(line):
+			match := asciidocHeadingPattern.FindStringSubmatch(line)
+			output = append(output, strings.Repeat("#", len(match[1]))+" "+asciidocInline(match[2]), "")
+		case asciidocAdmonitionPattern.MatchString(line):
+			match := asciidocAdmonitionPattern.FindStringSubmatch(line)
+			output = append(output, "> **"+strings.Title(strings.ToLower(match[1]))+":** "+asciidocInline(match[2]))
+		case strings.HasPrefix(line, "image::"):
+			output = append(output, asciidocInline(line), "")
+		case len(trimmed) > 1 && trimmed[0] == '.' && trimmed[1] != '.' && trimmed[1] != ' ':
+			output = append(output, "*"+asciidocInline(trimmed[1:])+"*", "")
+		case asciidocListPattern.MatchString(line):
+			match := asciidocListPattern.FindStringSubmatch(line)
+			marker := "*"
+
+			if match[1][0] == '.' {
+				marker = "1."
+			}
+
+			output = append(output, strings.Repeat("    ", len(match[1])-1)+marker+" "+asciidocInline(match[2]))
+			inList = true
+		case trimmed == "+":
+			output = append(output, "")
+		default:
+			output = append(output, asciidocInline(line))
+		}
+	}
+
+	return bytes.TrimRight([]byte(strings.Join(output, "\n")), "\n")
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var fencePattern = regexp.MustCompile("^\\s*(```|~~~)")
+
+// mathSegment is a piece of TeX found in Markdown
+type mathSegment struct {
+	TeX     string
+	Display bool
+}
+
+// renderMath returns HTML for a TeX segment, either delimited for client-side KaTeX or MathJax,
+// or rendered by an external command reading TeX from standard input. The command gets
+// BLOGGER_MATH_DISPLAY=true in its environment for display math.
+func renderMath(segment mathSegment, mode string) string {
+	element, class := "span", "math inline"
+	open, close := `\(`, `\)`
+
+	if segment.Display {
+		element, class = "div", "math display"
+		open, close = `\[`, `\]`
+	}
+
+	if !strings.HasPrefix(mode, "command:") {
+		return fmt.Sprintf(`<%s class="%s">%s%s%s</%s>`, element, class, open, html.EscapeString(segment.TeX), close, element)
+	}
+
+	command := exec.Command("sh", "-c", strings.TrimPrefix(mode, "command:"))
+	command.Stdin = strings.NewReader(segment.TeX)
+	command.Env = append(os.Environ(), fmt.Sprintf("BLOGGER_MATH_DISPLAY=%v", segment.Display))
+	command.Stderr = os.Stderr
+
+	output, err := command.Output()
+
+	if err != nil {
+		log.Printf("Could not render math %q, leaving it for the client: %v", segment.TeX, err)
+		return renderMath(segment, "client")
+	}
+
+	return fmt.Sprintf(`<%s class="%s">%s</%s>`, element, class, strings.TrimSpace(string(output)), element)
+}
+
+// findMathEnd returns the index of the closing delimiter, skipping escaped dollar signs
+func findMathEnd(text string, delimiter string) int {
+	for index := 0; index < len(text); index++ {
+		if text[index] == '\\' {
+			index++
+			continue
+		}
+
+		if strings.HasPrefix(text[index:], delimiter) {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// protectMath replaces $...$ and $$...$$ in Markdown with placeholders so the renderer
+// leaves TeX alone. Code blocks and code spans are not touched. Inline math can't start
+// or end with a space, or be followed by a digit, so prices like $5 stay as they are.
+func protectMath(content []byte, mode string) ([]byte, map[string]string) {
+	rendered := map[string]string{}
+
+	if mode == "" || !bytes.Contains(content, []byte("$")) {
+		return content, rendered
+	}
+
+	var result bytes.Buffer
+	text := string(content)
+	inFence := ""
+
+	placeholder := func(segment mathSegment) string {
+		name := fmt.Sprintf("BLOGGERMATH%dEND", len(rendered))
+		rendered[name] = renderMath(segment, mode)
+		return name
+	}
+
+	for len(text) > 0 {
+		lineEnd := strings.IndexByte(text, '\n')
+
+		if lineEnd < 0 {
+			lineEnd = len(text) - 1
+		}
+
+		line := text[:lineEnd+1]
+
+		if fence := fencePattern.FindStringSubmatch(line); fence != nil {
+			if inFence == "" {
+				inFence = fence[1]
+			} else if fence[1] == inFence {
+				inFence = ""
+			}
+		}
+
+		if inFence != "" || strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") || !strings.Contains(line, "$") {
+			result.WriteString(line)
+			text = text[lineEnd+1:]
+			continue
+		}
+
+		// display math can span lines, so it's searched in the rest of the text
+		index := 0
+
+		for index < len(text) && index <= lineEnd {
+			switch {
+			case text[index] == '\\' && index+1 < len(text):
+				result.WriteString(text[index : index+2])
+				index += 2
+			case text[index] == '`':
+				ticks := len(text[index:]) - len(strings.TrimLeft(text[index:], "`"))
+				end := strings.Index(text[index+ticks:], strings.Repeat("`", ticks))
+
+				if end < 0 {
+					result.WriteString(text[index : index+ticks])
+					index += ticks
+					continue
+				}
+
+				end += index + 2*ticks
+				result.WriteString(text[index:end])
+				index = end
+			case strings.HasPrefix(text[index:], "$$"):
+				end := findMathEnd(text[index+2:], "$$")
+
+				if end < 0 {
+					result.WriteString("$$")
+					index += 2
+					continue
+				}
+
+				result.WriteString(placeholder(mathSegment{TeX: strings.TrimSpace(text[index+2 : index+2+end]), Display: true}))
+				index += end + 4
+
+				if index > lineEnd {
+					lineEnd = index + strings.IndexByte(text[index:]+"\n", '\n')
+				}
+			case text[index] == '$':
+				end := findMathEnd(text[index+1:lineEnd+1], "$")
+				closing := index + 1 + end
+
+				if end <= 0 || text[index+1] == ' ' || text[closing-1] == ' ' ||
+					(closing+1 < len(text) && text[closing+1] >= '0' && text[closing+1] <= '9') {
+					result.WriteByte('$')
+					index++
+					continue
+				}
+
+				result.WriteString(placeholder(mathSegment{TeX: text[index+1 : closing]}))
+				index = closing + 1
+			default:
+				result.WriteByte(text[index])
+				index++
+			}
+		}
+
+		text = text[index:]
+	}
+
+	return result.Bytes(), rendered
+}
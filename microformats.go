@@ -0,0 +1,120 @@
+package main
+
+import (
+	"html"
+	"log"
+	"os"
+	"path"
+	"text/template"
+
+	"macbirdie.net/blogger/post"
+)
+
+const snippetTemplateFileName = "snippettemplate.html"
+
+// loadSnippetTemplate returns the template snippet pages are rendered with, when the templates directory has one
+func loadSnippetTemplate(funcMap template.FuncMap) *template.Template {
+	fileName := path.Join(*templatesPath, snippetTemplateFileName)
+
+	if _, err := os.Stat(fileName); err != nil {
+		return nil
+	}
+
+	snippetTemplate, err := template.New(snippetTemplateFileName).Funcs(funcMap).ParseFiles(fileName)
+
+	if err != nil {
+		log.Printf("Could not parse snippet template, using the main template: %v", err)
+		return nil
+	}
+
+	return snippetTemplate
+}
+
+// articleURL is the absolute address of an article, used as its identity by IndieWeb readers
+func articleURL(article *post.Article) string {
+	return absURL(article.FullPath())
+}
+
+// pName marks up the article title, empty for untitled snippets so readers don't take the content for a name
+func pName(article *post.Article) string {
+	if article.Title == "" {
+		return ""
+	}
+
+	return `<span class="p-name">` + html.EscapeString(article.Title) + `</span>`
+}
+
+// dtPublished marks up the publication date, displayed with an optional layout
+func dtPublished(article *post.Article, layout ...string) string {
+	display := "Jan _2 2006, 15:04"
+
+	if len(layout) > 0 {
+		display = layout[0]
+	}
+
+	date := article.DateModified.In(post.Location)
+
+	return `<time class="dt-published" datetime="` + date.Format(post.DefaultDateFormat) + `">` + html.EscapeString(date.Format(display)) + `</time>`
+}
+
+// uURL links to the article permalink with the given HTML as the link content
+func uURL(article *post.Article, content string) string {
+	return `<a class="u-url" href="` + html.EscapeString(articleURL(article)) + `">` + content + `</a>`
+}
+
+// eContent wraps the rendered article content
+func eContent(article *post.Article) string {
+	return `<div class="e-content">` + article.Content + `</div>`
+}
+
+// responseClasses are the microformats2 properties of each response kind
+var responseClasses = map[string]string{
+	"reply":    "u-in-reply-to",
+	"like":     "u-like-of",
+	"repost":   "u-repost-of",
+	"bookmark": "u-bookmark-of",
+}
+
+// responseLink links to the page an article responds to, labelled with its fetched title when known
+func responseLink(article *post.Article) string {
+	link := article.ResponseURL()
+
+	if link == "" {
+		return ""
+	}
+
+	label := html.EscapeString(link)
+
+	if article.Context != nil && article.Context.Title != "" {
+		label = article.Context.Title
+	}
+
+	return `<a class="` + responseClasses[article.Kind()] + `" href="` + html.EscapeString(link) + `">` + label + `</a>`
+}
+
+// hCite marks up the fetched context of a response as an h-cite, falling back to a plain response link
+func hCite(article *post.Article) string {
+	if article.Context == nil {
+		return responseLink(article)
+	}
+
+	context := article.Context
+	title := context.Title
+
+	if title == "" {
+		title = html.EscapeString(context.URL)
+	}
+
+	cite := `<div class="h-cite ` + responseClasses[article.Kind()] + `">`
+	cite += `<a class="u-url p-name" href="` + html.EscapeString(context.URL) + `">` + title + `</a>`
+
+	if context.Author != "" {
+		cite += ` <span class="p-author">` + context.Author + `</span>`
+	}
+
+	if context.Summary != "" {
+		cite += `<blockquote class="p-summary">` + context.Summary + `</blockquote>`
+	}
+
+	return cite + `</div>`
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"text/template"
+)
+
+// atomicFile is a buffered writer to a temporary file, which replaces the destination file on
+// commit, so readers never see a partially written page. The content is hashed for the manifest
+// on the way, and attributed to the source the file is generated from.
+type atomicFile struct {
+	*bufio.Writer
+	file   *os.File
+	name   string
+	hash   hash.Hash
+	source string
+}
+
+// createAtomicFile starts writing a file, next to it so that renaming stays on one file system
+func createAtomicFile(name string) (*atomicFile, error) {
+	file, err := ioutil.TempFile(path.Dir(name), "."+path.Base(name)+".*")
+
+	if err != nil {
+		return nil, err
+	}
+
+	contentHash := sha256.New()
+
+	return &atomicFile{Writer: bufio.NewWriter(io.MultiWriter(file, contentHash)), file: file, name: name, hash: contentHash, source: outputSource()}, nil
+}
+
+// Commit flushes the written content and moves the file in place
+func (a *atomicFile) Commit() error {
+	if err := a.Flush(); err != nil {
+		a.Abort()
+		return err
+	}
+
+	if err := a.file.Chmod(0644); err != nil {
+		a.Abort()
+		return err
+	}
+
+	if err := a.file.Close(); err != nil {
+		os.Remove(a.file.Name())
+		return err
+	}
+
+	logPageChange(a.name, a.file.Name())
+
+	if err := os.Rename(a.file.Name(), a.name); err != nil {
+		os.Remove(a.file.Name())
+		return err
+	}
+
+	recordOutput(a.name, a.source, hex.EncodeToString(a.hash.Sum(nil)))
+
+	return nil
+}
+
+// Abort drops the written content, leaving the destination file as it was
+func (a *atomicFile) Abort() {
+	a.file.Close()
+	os.Remove(a.file.Name())
+}
+
+// writeFile replaces a file with the given content atomically
+func writeFile(fileName string, content []byte) error {
+	content = relativizeLinks(fileName, content)
+	file, err := createAtomicFile(fileName)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(content); err != nil {
+		file.Abort()
+		return err
+	}
+
+	return file.Commit()
+}
+
+// executeToFile renders a template straight into a file, through a buffer when links are made
+// relative. When rendering fails, the file is left as it was.
+func executeToFile(fileName string, pageTemplate *template.Template, context interface{}) {
+	if *relativeURLs && isPageFile(fileName) {
+		var buffer bytes.Buffer
+		err := pageTemplate.Execute(&buffer, context)
+
+		if err == nil {
+			err = writeFile(fileName, buffer.Bytes())
+		}
+
+		if err != nil {
+			log.Printf("Could not write file %v due to error: %v", fileName, err)
+		}
+
+		return
+	}
+
+	file, err := createAtomicFile(fileName)
+
+	if err == nil {
+		if err = pageTemplate.Execute(file, context); err != nil {
+			file.Abort()
+		} else {
+			err = file.Commit()
+		}
+	}
+
+	if err != nil {
+		log.Printf("Could not write file %v due to error: %v", fileName, err)
+	}
+}
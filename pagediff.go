@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"html"
+	"io/ioutil"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// maxDiffWords limits the words shown of a change, and maxDiffCells the size of the comparison
+// of changed regions, beyond which they are shown as replaced as a whole
+const maxDiffWords = 12
+const maxDiffCells = 1000000
+
+// pageChanges is set while watching, when rewritten pages are compared with their previous content
+var pageChanges int32
+
+// comparePageChanges starts logging changes of rewritten pages
+func comparePageChanges() {
+	atomic.StoreInt32(&pageChanges, 1)
+}
+
+// pageWords returns the words of the text of a page
+func pageWords(page []byte) []string {
+	return strings.Fields(html.UnescapeString(tagPattern.ReplaceAllString(string(page), " ")))
+}
+
+// shortenWords joins words, leaving out the middle of long runs
+func shortenWords(words []string) string {
+	if len(words) <= maxDiffWords {
+		return strings.Join(words, " ")
+	}
+
+	return strings.Join(words[:maxDiffWords/2], " ") + " … " + strings.Join(words[len(words)-maxDiffWords/2:], " ")
+}
+
+// wordDiff describes the difference of two word lists like wdiff, with removed words in [- -]
+// and added words in {+ +}, and a few words of unchanged context
+func wordDiff(before, after []string) string {
+	prefix := 0
+
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	removed, added := before[prefix:len(before)-suffix], after[prefix:len(after)-suffix]
+
+	// operations are ' ' for kept, '-' for removed and '+' for added words
+	type operation struct {
+		kind byte
+		word string
+	}
+
+	var operations []operation
+
+	if len(removed)*len(added) > maxDiffCells {
+		for _, word := range removed {
+			operations = append(operations, operation{'-', word})
+		}
+
+		for _, word := range added {
+			operations = append(operations, operation{'+', word})
+		}
+	} else {
+		// lengths of the longest common subsequences of the remaining words
+		common := make([][]int, len(removed)+1)
+
+		for index := range common {
+			common[index] = make([]int, len(added)+1)
+		}
+
+		for i := len(removed) - 1; i >= 0; i-- {
+			for j := len(added) - 1; j >= 0; j-- {
+				if removed[i] == added[j] {
+					common[i][j] = common[i+1][j+1] + 1
+				} else if common[i+1][j] >= common[i][j+1] {
+					common[i][j] = common[i+1][j]
+				} else {
+					common[i][j] = common[i][j+1]
+				}
+			}
+		}
+
+		i, j := 0, 0
+
+		for i < len(removed) || j < len(added) {
+			switch {
+			case i < len(removed) && j < len(added) && removed[i] == added[j]:
+				operations = append(operations, operation{' ', removed[i]})
+				i++
+				j++
+			case j < len(added) && (i == len(removed) || common[i][j+1] >= common[i+1][j]):
+				operations = append(operations, operation{'+', added[j]})
+				j++
+			default:
+				operations = append(operations, operation{'-', removed[i]})
+				i++
+			}
+		}
+	}
+
+	var parts []string
+
+	if prefix > 0 {
+		parts = append(parts, "…")
+	}
+
+	for start := 0; start < len(operations); {
+		end := start
+		var words []string
+
+		for end < len(operations) && operations[end].kind == operations[start].kind {
+			words = append(words, operations[end].word)
+			end++
+		}
+
+		switch operations[start].kind {
+		case '-':
+			parts = append(parts, "[-"+shortenWords(words)+"-]")
+		case '+':
+			parts = append(parts, "{+"+shortenWords(words)+"+}")
+		default:
+			if len(words) > 6 {
+				parts = append(parts, strings.Join(words[:3], " ")+" … "+strings.Join(words[len(words)-3:], " "))
+			} else {
+				parts = append(parts, strings.Join(words, " "))
+			}
+		}
+
+		start = end
+	}
+
+	if suffix > 0 {
+		parts = append(parts, "…")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// logPageChange logs how a page about to be replaced with newly written content changes, staying
+// quiet when the content is the same
+func logPageChange(fileName, writtenFileName string) {
+	if atomic.LoadInt32(&pageChanges) == 0 || !isPageFile(fileName) {
+		return
+	}
+
+	written, err := ioutil.ReadFile(writtenFileName)
+
+	if err != nil {
+		return
+	}
+
+	previous, err := ioutil.ReadFile(fileName)
+
+	if err != nil {
+		log.Printf("New page %v", fileName)
+		return
+	}
+
+	if bytes.Equal(previous, written) {
+		return
+	}
+
+	before, after := pageWords(previous), pageWords(written)
+
+	if strings.Join(before, " ") == strings.Join(after, " ") {
+		log.Printf("Changed page %v: markup only", fileName)
+		return
+	}
+
+	log.Printf("Changed page %v: %s", fileName, wordDiff(before, after))
+}
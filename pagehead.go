@@ -0,0 +1,30 @@
+package main
+
+import (
+	"html"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+// pageHead renders the stylesheets, scripts and head lines an article asks for in its front
+// matter. Paths which aren't URLs are relative to the site root.
+func pageHead(article *post.Article) string {
+	if article == nil {
+		return ""
+	}
+
+	var lines []string
+
+	for _, stylesheet := range article.CSS {
+		lines = append(lines, `<link rel="stylesheet" href="`+html.EscapeString(sitePath(stylesheet))+`">`)
+	}
+
+	for _, script := range article.JS {
+		lines = append(lines, `<script src="`+html.EscapeString(sitePath(script))+`" defer></script>`)
+	}
+
+	lines = append(lines, article.Head...)
+
+	return strings.Join(lines, "\n")
+}
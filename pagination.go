@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"macbirdie.net/blogger/post"
+)
+
+var tagPageSize = flag.Int("tag-page-size", 0, "Number of articles on each page of a tag index, 0 for all on one page")
+var tagSort = flag.String("tag-sort", "date", "Order of articles on tag indexes: date, updated or title")
+
+// Pagination links the pages of an index split into several files
+type Pagination struct {
+	Page     int
+	Pages    int
+	First    string
+	Previous string
+	Next     string
+	Last     string
+}
+
+// tagPageName is the file name of a page of a tag index, the first page being the tag index itself
+// and further pages numbered next to it, like tag-go-page-2.html or tags/go/index-page-2.html
+func tagPageName(tag post.Tag, page int) string {
+	indexName := tagIndexName(tag)
+
+	if page <= 1 {
+		return indexName
+	}
+
+	extension := path.Ext(indexName)
+
+	return strings.TrimSuffix(indexName, extension) + "-page-" + strconv.Itoa(page) + extension
+}
+
+// sortArticles returns articles in an order: date, updated (falling back to the date) or title
+func sortArticles(articles post.Articles, order string) post.Articles {
+	sorted := append(post.Articles{}, articles...)
+
+	switch order {
+	case "", "date":
+		sort.Stable(sorted)
+	case "updated":
+		lastChange := func(article *post.Article) int64 {
+			if article.DateUpdated != nil {
+				return article.DateUpdated.Unix()
+			}
+			return article.DateModified.Unix()
+		}
+
+		sort.SliceStable(sorted, func(i, j int) bool { return lastChange(sorted[i]) > lastChange(sorted[j]) })
+	case "title":
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return strings.ToLower(sorted[i].Title) < strings.ToLower(sorted[j].Title)
+		})
+	default:
+		log.Printf("Unknown article order %q, keeping articles by date", order)
+	}
+
+	return sorted
+}
+
+// writeTagPages writes the index of a tag, split into pages of -tag-page-size articles
+func writeTagPages(destinationDir string, mainTemplate *template.Template, tag post.Tag, articles post.Articles, context PageContext) {
+	articles = sortArticles(articles, *tagSort)
+	pageSize := *tagPageSize
+
+	os.MkdirAll(path.Dir(path.Join(destinationDir, tagPageName(tag, 1))), os.ModePerm)
+
+	if pageSize <= 0 || len(articles) <= pageSize {
+		context.Articles = articles
+		executeToFile(path.Join(destinationDir, tagPageName(tag, 1)), mainTemplate, context)
+		return
+	}
+
+	pages := (len(articles) + pageSize - 1) / pageSize
+
+	for page := 1; page <= pages; page++ {
+		end := page * pageSize
+
+		if end > len(articles) {
+			end = len(articles)
+		}
+
+		pagination := &Pagination{Page: page, Pages: pages, First: tagPageName(tag, 1), Last: tagPageName(tag, pages)}
+
+		if page > 1 {
+			pagination.Previous = tagPageName(tag, page-1)
+		}
+
+		if page < pages {
+			pagination.Next = tagPageName(tag, page+1)
+		}
+
+		context.Articles = articles[(page-1)*pageSize : end]
+		context.Pagination = pagination
+		executeToFile(path.Join(destinationDir, tagPageName(tag, page)), mainTemplate, context)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import "bytes"
+
+// htmlFrontMatter turns the front matter of a pre-rendered HTML source, kept in a leading comment, into the
+// usual front matter block. The comment may hold the block with or without its --- lines.
+func htmlFrontMatter(source []byte) []byte {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(source, []byte("\ufeff")), " \t\r\n")
+
+	if !bytes.HasPrefix(trimmed, []byte("<!--")) {
+		return source
+	}
+
+	end := bytes.Index(trimmed, []byte("-->"))
+
+	if end < 0 {
+		return source
+	}
+
+	header := bytes.TrimSpace(trimmed[len("<!--"):end])
+	content := bytes.TrimLeft(trimmed[end+len("-->"):], "\r\n")
+
+	if !bytes.HasPrefix(header, []byte("---")) {
+		header = append(append([]byte("---\n"), header...), []byte("\n---")...)
+	}
+
+	var converted bytes.Buffer
+	converted.Write(header)
+	converted.WriteString("\n")
+	converted.Write(content)
+
+	return converted.Bytes()
+}
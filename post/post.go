@@ -5,13 +5,45 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 	"unicode"
 )
 
+// DateFormats are layouts accepted in date front matter fields, tried in order
+var DateFormats = []string{
+	DefaultDateFormat,
+	IFTTTDateFormat,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Location is the time zone used for dates without an offset and for displaying dates
+var Location = time.Local
+
+// Taxonomies are front matter keys holding lists of terms, grouping articles the way tags do
+var Taxonomies []string
+
+// DraftsPath is the directory drafts of posts and snippets are placed in, relative to blog root path
+var DraftsPath = "drafts"
+
+// TypePaths are the directories articles of each type are placed in, relative to blog root path.
+// :year, :month and :day are replaced with the article date. Types without an entry are placed in
+// year and month directories, except pages, which are placed in the root.
+var TypePaths = map[PageType]string{}
+
+// UglyURLs places articles in files named after them. Otherwise articles are placed in index.html
+// files of directories named after them, and their paths end with a slash.
+var UglyURLs = true
+
 // PageType is a convenience type alias for page type
 type PageType string
 
@@ -22,6 +54,8 @@ const (
 	Page = "Page"
 	// Snippet - Twitter-like short blog post
 	Snippet = "Snippet"
+	// Link - A link blog entry pointing at an external page
+	Link = "Link"
 )
 
 const (
@@ -37,11 +71,30 @@ type Tag struct {
 	Hidden bool
 }
 
+// NormalizeTag, when set, rewrites tag names after lowercasing, e.g. to transliterate them
+var NormalizeTag func(string) string
+
+// TagAliases map normalized tag names to the tag names they are merged into
+var TagAliases = map[string]string{}
+
 func MakeTag(tag string) Tag {
 	prepared := strings.ToLower(tag)
 	trimmed := strings.TrimPrefix(prepared, "-")
 	hidden := trimmed != prepared
 
+	if NormalizeTag != nil {
+		trimmed = NormalizeTag(trimmed)
+	}
+
+	if alias, found := TagAliases[trimmed]; found {
+		trimmed = alias
+		tag = alias
+
+		if hidden {
+			tag = "-" + alias
+		}
+	}
+
 	return Tag{Name: trimmed, OriginalName: tag, Hidden: hidden}
 }
 
@@ -68,9 +121,156 @@ type Article struct {
 	Snippet      bool
 	Type         PageType
 	Draft        bool
+	// Unlisted articles are generated but left out of indexes, tags, feeds and the sitemap
+	Unlisted     bool
+	// Expires is when the article drops out of indexes, feeds and the sitemap
+	Expires      *time.Time
+	// ExpiredRedirect is where the article redirects to once it expired
+	ExpiredRedirect string
+	// Edits is the changelog of the article, newest first
+	Edits        []Edit
 	Tags         []Tag
 	AppID        string
 	Meta         map[string]string
+	Outputs      []string
+	Series       string
+	Aliases      []string
+	Canonical    string
+	Cover        string
+	// Image is the address of the image representing the article, its cover or first image
+	Image        string
+	Syndication  []string
+	Password     string
+	Comments     []Comment
+	Undated      bool
+	Sanitize     string
+	References   []string
+	Enclosure    *Enclosure
+	InReplyTo    string
+	LikeOf       string
+	RepostOf     string
+	BookmarkOf   string
+	Context      *Citation
+	Taxonomies   map[string][]Tag
+	Pinned       bool
+	Weight       int
+	Bundle       string
+	Attachments  []Attachment
+	// CSS and JS are stylesheets and scripts added to the head of the article's page
+	CSS          []string
+	JS           []string
+	// Head are raw lines added to the head of the article's page, separated with | in front matter
+	Head         []string
+	Source       string
+	// Section is the section of the posts directory the article comes from, if any
+	Section      string
+	FrontMatter  map[string]string
+	// FrontMatterLines are line numbers of the front matter keys in the source
+	FrontMatterLines map[string]int
+}
+
+// Attachment is a downloadable file published with an article. Name is the file path relative
+// to the article source, the remaining fields are filled in when the article is generated.
+type Attachment struct {
+	Name   string
+	URL    string
+	Size   int64
+	SHA256 string
+	Source string
+}
+
+// Citation describes a page an article responds to, such as the post a reply is written to. The
+// title, author and summary come from the page and are HTML-escaped.
+type Citation struct {
+	URL     string
+	Title   string
+	Author  string
+	Summary string
+}
+
+// Enclosure is a media file attached to an article, such as a podcast episode
+type Enclosure struct {
+	URL      string
+	Length   int64
+	Type     string
+	Duration string
+	Explicit bool
+}
+
+// Comment is a reader comment attached to an article at build time. Content is rendered HTML,
+// Author and AuthorURL are HTML-escaped and AuthorURL is empty unless it's an http or https link.
+type Comment struct {
+	Author    string
+	AuthorURL string
+	Date      *time.Time
+	Content   string
+}
+
+// Edit is an entry of the changelog of an article. Major edits bring the article back into feeds.
+type Edit struct {
+	Date  *time.Time
+	Note  string
+	Major bool
+}
+
+// parseEdits reads an edits list of "date [major:] note" entries separated by semicolons,
+// where the date is in any of the DateFormats
+func parseEdits(value string) ([]Edit, error) {
+	var edits []Edit
+
+	for _, entry := range strings.Split(value, ";") {
+		fields := strings.Fields(entry)
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		var edit *Edit
+
+		// dates can have a time separated by a space, so the longest date prefix is taken
+		count := len(fields)
+
+		if count > 3 {
+			count = 3
+		}
+
+		for ; count > 0 && edit == nil; count-- {
+			if date, err := ParseDate(strings.Join(fields[:count], " ")); err == nil {
+				edit = &Edit{Date: &date, Note: strings.Join(fields[count:], " ")}
+			}
+		}
+
+		if edit == nil {
+			return nil, fmt.Errorf("edit %q does not start with a date", strings.TrimSpace(entry))
+		}
+
+		if strings.HasPrefix(edit.Note, "major:") {
+			edit.Major = true
+			edit.Note = strings.TrimSpace(strings.TrimPrefix(edit.Note, "major:"))
+		}
+
+		edits = append(edits, *edit)
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool { return edits[j].Date.Before(*edits[i].Date) })
+
+	return edits, nil
+}
+
+// Changelog lists the edits of an article, newest first
+func (a Article) Changelog() []Edit {
+	return a.Edits
+}
+
+// LastMajorEdit is the date of the newest major edit, nil when there is none
+func (a Article) LastMajorEdit() *time.Time {
+	for _, edit := range a.Edits {
+		if edit.Major {
+			return edit.Date
+		}
+	}
+
+	return nil
 }
 
 // HasTag checks if the given article contains a certain tag
@@ -84,6 +284,26 @@ func (a Article) HasTag(aTag string) bool {
 	return false
 }
 
+// Terms returns the terms of an article in a taxonomy, tags included
+func (a Article) Terms(taxonomy string) []Tag {
+	if taxonomy == "tags" {
+		return a.Tags
+	}
+
+	return a.Taxonomies[taxonomy]
+}
+
+// HasTerm checks if the given article is classified with a term of a taxonomy
+func (a Article) HasTerm(taxonomy, name string) bool {
+	for _, term := range a.Terms(taxonomy) {
+		if name == term.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (a Article) VisibleTags() []Tag {
 	tags := make([]Tag, 0)
 	for _, tag := range a.Tags {
@@ -94,26 +314,83 @@ func (a Article) VisibleTags() []Tag {
 	return tags
 }
 
+// Kind returns the IndieWeb response kind of an article: reply, like, repost, bookmark, or an empty string
+func (a Article) Kind() string {
+	switch {
+	case a.InReplyTo != "":
+		return "reply"
+	case a.LikeOf != "":
+		return "like"
+	case a.RepostOf != "":
+		return "repost"
+	case a.BookmarkOf != "":
+		return "bookmark"
+	}
+
+	return ""
+}
+
+// ResponseURL returns the address of the page an article responds to, if any
+func (a Article) ResponseURL() string {
+	switch a.Kind() {
+	case "reply":
+		return a.InReplyTo
+	case "like":
+		return a.LikeOf
+	case "repost":
+		return a.RepostOf
+	case "bookmark":
+		return a.BookmarkOf
+	}
+
+	return ""
+}
+
 // BasePath returns a base path for the given article, relative to blog root path
 func (a Article) BasePath() string {
 	switch a.Type {
-	case Post, Snippet:
+	case Post, Snippet, Link:
 		if a.Draft {
-			return "drafts"
+			return DraftsPath
 		}
 
 	case Page:
-		return ""
+		if _, found := TypePaths[a.Type]; !found {
+			return ""
+		}
+	}
+
+	layout, found := TypePaths[a.Type]
+
+	if !found {
+		layout = ":year/:month"
 	}
 
-	return path.Join(strconv.Itoa(a.DateModified.Year()), fmt.Sprintf("%02d", int(a.DateModified.Month())))
+	return path.Clean("/" + strings.NewReplacer(
+		":year", strconv.Itoa(a.DateModified.Year()),
+		":month", fmt.Sprintf("%02d", int(a.DateModified.Month())),
+		":day", fmt.Sprintf("%02d", a.DateModified.Day()),
+	).Replace(layout))[1:]
 }
 
 // FullPath combines BasePath with articles file name
 func (a Article) FullPath() string {
+	if !UglyURLs {
+		return path.Join(a.BasePath(), a.Filename) + "/"
+	}
+
 	return path.Join(a.BasePath(), a.Filename)
 }
 
+// OutputPath returns the file an article is written to, relative to blog root path
+func (a Article) OutputPath() string {
+	if !UglyURLs {
+		return path.Join(a.BasePath(), a.Filename, "index.html")
+	}
+
+	return a.FullPath()
+}
+
 // Articles is an convenience type alias for article slice
 type Articles []*Article
 
@@ -132,9 +409,30 @@ func (a Articles) Less(i, j int) bool {
 	return right.Before(left)
 }
 
+// Pinned returns articles with pinned ones first, heavier first, keeping the order of the rest
+func (a Articles) Pinned() Articles {
+	pinned := append(Articles{}, a...)
+
+	sort.SliceStable(pinned, func(i, j int) bool {
+		if pinned[i].Pinned != pinned[j].Pinned {
+			return pinned[i].Pinned
+		}
+
+		return pinned[i].Weight > pinned[j].Weight
+	})
+
+	return pinned
+}
+
 // Print sends an article header in plain text to standard output
 func (a Article) Print() {
-	fmt.Println("---")
+	a.WriteHeader(os.Stdout)
+	fmt.Println("")
+}
+
+// WriteHeader writes an article front matter header, in a format ReadArticle understands
+func (a Article) WriteHeader(w io.Writer) {
+	fmt.Fprintln(w, "---")
 
 	var articleType string
 
@@ -145,54 +443,338 @@ func (a Article) Print() {
 		articleType = "Snippet"
 	case Page:
 		articleType = "Page"
+	case Link:
+		articleType = "Link"
 	}
 
-	if a.Type != Snippet {
-		fmt.Printf("title: %s\n", a.Title)
+	if (a.Type != Snippet && a.Type != Link) || len(a.Title) > 0 {
+		fmt.Fprintf(w, "title: %s\n", a.Title)
+	}
+
+	fmt.Fprintf(w, "author: %s\n", a.Author)
+	fmt.Fprintf(w, "type: %s\n", articleType)
+
+	var tags []string
+	for _, tag := range a.Tags {
+		tags = append(tags, tag.OriginalName)
+	}
+
+	fmt.Fprintf(w, "tags: %s\n", strings.Join(tags, ", "))
+
+	for _, taxonomy := range Taxonomies {
+		var terms []string
+		for _, term := range a.Taxonomies[taxonomy] {
+			terms = append(terms, term.OriginalName)
+		}
+
+		if len(terms) > 0 {
+			fmt.Fprintf(w, "%s: %s\n", taxonomy, strings.Join(terms, ", "))
+		}
 	}
 
-	fmt.Printf("author: %s\n", a.Author)
-	fmt.Printf("type: %s\n", articleType)
-	fmt.Println("tags: ")
 	if a.DateModified != nil {
-		fmt.Printf("date: %v\n", a.DateModified.Format(DefaultDateFormat))
+		fmt.Fprintf(w, "date: %v\n", a.DateModified.Format(DefaultDateFormat))
 	}
 
 	if a.DateUpdated != nil {
-		fmt.Printf("date: %v\n", a.DateUpdated.Format(DefaultDateFormat))
+		fmt.Fprintf(w, "updated: %v\n", a.DateUpdated.Format(DefaultDateFormat))
+	}
+
+	if len(a.Description) > 0 {
+		fmt.Fprintf(w, "description: %v\n", a.Description)
+	}
+
+	if len(a.Link) > 0 {
+		fmt.Fprintf(w, "link: %v\n", a.Link)
+	}
+
+	if len(a.Series) > 0 {
+		fmt.Fprintf(w, "series: %v\n", a.Series)
+	}
+
+	if a.Outputs != nil {
+		fmt.Fprintf(w, "outputs: %v\n", strings.Join(a.Outputs, ", "))
+	}
+
+	if len(a.Canonical) > 0 {
+		fmt.Fprintf(w, "canonical: %v\n", a.Canonical)
+	}
+
+	if len(a.Syndication) > 0 {
+		fmt.Fprintf(w, "syndication: %v\n", strings.Join(a.Syndication, ", "))
+	}
+
+	if len(a.Aliases) > 0 {
+		fmt.Fprintf(w, "aliases: %v\n", strings.Join(a.Aliases, ", "))
+	}
+
+	if len(a.References) > 0 {
+		fmt.Fprintf(w, "references: %v\n", strings.Join(a.References, " | "))
+	}
+
+	if len(a.CSS) > 0 {
+		fmt.Fprintf(w, "css: %v\n", strings.Join(a.CSS, ", "))
+	}
+
+	if len(a.JS) > 0 {
+		fmt.Fprintf(w, "js: %v\n", strings.Join(a.JS, ", "))
+	}
+
+	if len(a.Head) > 0 {
+		fmt.Fprintf(w, "head: %v\n", strings.Join(a.Head, " | "))
+	}
+
+	if a.Enclosure != nil {
+		fmt.Fprintf(w, "enclosure: %v %d %v\n", a.Enclosure.URL, a.Enclosure.Length, a.Enclosure.Type)
+
+		if len(a.Enclosure.Duration) > 0 {
+			fmt.Fprintf(w, "duration: %v\n", a.Enclosure.Duration)
+		}
+
+		if a.Enclosure.Explicit {
+			fmt.Fprintf(w, "explicit: true\n")
+		}
+	}
+
+	if len(a.InReplyTo) > 0 {
+		fmt.Fprintf(w, "reply-to: %v\n", a.InReplyTo)
+	}
+
+	if len(a.LikeOf) > 0 {
+		fmt.Fprintf(w, "like-of: %v\n", a.LikeOf)
+	}
+
+	if len(a.RepostOf) > 0 {
+		fmt.Fprintf(w, "repost-of: %v\n", a.RepostOf)
+	}
+
+	if len(a.BookmarkOf) > 0 {
+		fmt.Fprintf(w, "bookmark-of: %v\n", a.BookmarkOf)
+	}
+
+	if len(a.Attachments) > 0 {
+		var names []string
+		for _, attachment := range a.Attachments {
+			names = append(names, attachment.Name)
+		}
+
+		fmt.Fprintf(w, "attachments: %v\n", strings.Join(names, ", "))
 	}
 
 	if len(a.AppID) > 0 {
-		fmt.Printf("appid: %v\n", a.AppID)
+		fmt.Fprintf(w, "appid: %v\n", a.AppID)
+	}
+
+	if len(a.Sanitize) > 0 {
+		fmt.Fprintf(w, "sanitize: %v\n", a.Sanitize)
+	}
+
+	if len(a.Password) > 0 {
+		fmt.Fprintf(w, "password: %v\n", a.Password)
+	}
+
+	if a.Weight != 0 {
+		fmt.Fprintf(w, "weight: %d\n", a.Weight)
+	} else if a.Pinned {
+		fmt.Fprintf(w, "pinned: true\n")
 	}
 
 	if a.Draft {
-		fmt.Printf("draft: true\n")
+		fmt.Fprintf(w, "draft: true\n")
 	}
 
-	if a.Meta != nil {
+	if a.Unlisted {
+		fmt.Fprintf(w, "unlisted: true\n")
 	}
 
-	fmt.Println("---")
-	fmt.Println("")
+	if a.Expires != nil {
+		fmt.Fprintf(w, "expires: %v\n", a.Expires.Format(DefaultDateFormat))
+	}
+
+	if len(a.ExpiredRedirect) > 0 {
+		fmt.Fprintf(w, "expired-redirect: %v\n", a.ExpiredRedirect)
+	}
+
+	if len(a.Edits) > 0 {
+		var edits []string
+		for _, edit := range a.Edits {
+			entry := edit.Date.Format(DefaultDateFormat)
+			if edit.Major {
+				entry += " major:"
+			}
+			edits = append(edits, strings.TrimSpace(entry+" "+edit.Note))
+		}
+
+		fmt.Fprintf(w, "edits: %s\n", strings.Join(edits, "; "))
+	}
+
+	var metaNames []string
+	for name := range a.Meta {
+		metaNames = append(metaNames, name)
+	}
+
+	sort.Strings(metaNames)
+
+	for _, name := range metaNames {
+		fmt.Fprintf(w, "meta-%s: %v\n", name, a.Meta[name])
+	}
+
+	fmt.Fprintln(w, "---")
+}
+
+// ParseDate reads a date in any of the DateFormats, using Location for dates without an offset
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	for _, layout := range DateFormats {
+		if date, err := time.ParseInLocation(layout, value, Location); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date %q, expected one of: %s", value, strings.Join(DateFormats, "; "))
+}
+
+// SplitDatedName splits a Jekyll-style 2006-01-02-slug file name into the slug and the date,
+// returning the name unchanged and no date for other names
+func SplitDatedName(name string) (string, *time.Time) {
+	if len(name) < 12 || name[10] != '-' {
+		return name, nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", name[:10], Location)
+
+	if err != nil {
+		return name, nil
+	}
+
+	return name[11:], &date
+}
+
+// parseEnclosure reads a "URL [length] [MIME type]" enclosure value, keeping details set by other keys
+func parseEnclosure(value string, enclosure *Enclosure) *Enclosure {
+	if enclosure == nil {
+		enclosure = &Enclosure{}
+	}
+
+	for index, field := range strings.Fields(value) {
+		if index == 0 {
+			enclosure.URL = field
+			continue
+		}
+
+		if length, err := strconv.ParseInt(field, 10, 64); err == nil {
+			enclosure.Length = length
+		} else if strings.Contains(field, "/") {
+			enclosure.Type = field
+		}
+	}
+
+	return enclosure
+}
+
+// listSeparator splits front matter list values on whitespace, commas and semicolons
+func listSeparator(divider rune) bool {
+	return unicode.IsSpace(divider) || divider == ',' || divider == ';'
+}
+
+// listValues splits a front matter list value, unquoting its items
+func listValues(value string) []string {
+	values := strings.FieldsFunc(value, listSeparator)
+
+	for index, item := range values {
+		values[index] = unquoteValue(item)
+	}
+
+	return values
+}
+
+// unquoteValue removes quotes around a front matter value, so values can contain colons and
+// surrounding spaces the YAML way. Values made of several quoted strings, like lists, are kept.
+func unquoteValue(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+
+	inner := value[1 : len(value)-1]
+
+	switch {
+	case value[0] == '"' && value[len(value)-1] == '"':
+		for index := 0; index < len(inner); index++ {
+			if inner[index] == '\\' {
+				index++
+			} else if inner[index] == '"' {
+				return value
+			}
+		}
+
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		return inner
+	case value[0] == '\'' && value[len(value)-1] == '\'':
+		for index := 0; index < len(inner); index++ {
+			if inner[index] != '\'' {
+				continue
+			}
+
+			if index+1 == len(inner) || inner[index+1] != '\'' {
+				return value
+			}
+
+			index++
+		}
+
+		return strings.Replace(inner, "''", "'", -1)
+	}
+
+	return value
+}
+
+// FrontMatterError is an invalid front matter header or value, located by its line in the source
+type FrontMatterError struct {
+	Line int
+	Key  string
+	Err  error
+}
+
+func (e *FrontMatterError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("line %d: %s: %v", e.Line, e.Key, e.Err)
+}
+
+func (e *FrontMatterError) Unwrap() error {
+	return e.Err
 }
 
-// ParseFrontMatter reads the front matter-type article header
+// ParseFrontMatter reads the front matter-type article header. A byte order mark, CRLF line
+// endings and a missing newline after the last line are tolerated.
 func ParseFrontMatter(reader *bufio.Reader) (map[string]string, error) {
+	data, _, err := parseFrontMatter(reader)
+
+	return data, err
+}
+
+// parseFrontMatter reads the article header along with the line number of each key
+func parseFrontMatter(reader *bufio.Reader) (map[string]string, map[string]int, error) {
 
 	data := make(map[string]string)
+	lines := make(map[string]int)
 
 	line, lineErr := reader.ReadString('\n')
+	line = strings.TrimPrefix(line, "\ufeff")
+	lineNumber := 1
 
 	if !strings.HasPrefix(line, "---") {
-		return data, errors.New("Invalid front matter header")
+		return data, lines, &FrontMatterError{Line: 1, Err: errors.New("Invalid front matter header")}
 	}
 
-	for {
+	for lineErr == nil {
 		line, lineErr = reader.ReadString('\n')
-		if lineErr != nil {
-			break
-		}
+		lineNumber++
 
 		if strings.HasPrefix(line, "---") {
 			break
@@ -207,24 +789,49 @@ func ParseFrontMatter(reader *bufio.Reader) (map[string]string, error) {
 		key, value := values[0], values[1]
 
 		key = strings.Trim(key, " \t\r\n")
-		value = strings.Trim(value, " \t\r\n")
+		value = unquoteValue(strings.Trim(value, " \t\r\n"))
 
 		data[key] = value
+		lines[key] = lineNumber
 	}
 
-	return data, nil
+	return data, lines, nil
+}
+
+// FrontMatterKeys are front matter keys read into articles, besides meta- prefixed keys and taxonomies
+var FrontMatterKeys = []string{
+	"title", "author", "description", "link", "date", "updated", "series", "canonical", "syndication",
+	"sanitize", "password", "references", "enclosure", "audio", "duration", "explicit", "reply-to",
+	"in-reply-to", "like-of", "repost-of", "bookmark-of", "attachments", "aliases", "appid", "draft",
+	"unlisted", "expires", "unpublish", "expired-redirect", "edits", "pinned", "weight", "type",
+	"outputs", "tags", "cover", "css", "js", "head",
 }
 
 // ReadArticle returns an article read from a Reader
 func ReadArticle(reader *bufio.Reader) (Article, error) {
+	return ReadArticleWithDefaults(reader, nil)
+}
+
+// ReadArticleWithDefaults returns an article read from a Reader, taking front matter keys it
+// lacks from defaults. Default keys have no line number.
+func ReadArticleWithDefaults(reader *bufio.Reader, defaults map[string]string) (Article, error) {
 	article := Article{}
 
-	frontMatter, matterErr := ParseFrontMatter(reader)
+	frontMatter, lines, matterErr := parseFrontMatter(reader)
 
 	if matterErr != nil {
-		return article, errors.New("Invalid article header")
+		return article, matterErr
+	}
+
+	for key, value := range defaults {
+		if _, found := frontMatter[key]; !found {
+			frontMatter[key] = value
+		}
 	}
 
+	article.FrontMatter = frontMatter
+	article.FrontMatterLines = lines
+
 	for key, value := range frontMatter {
 
 		if strings.HasPrefix(key, "meta-") {
@@ -246,45 +853,105 @@ func ReadArticle(reader *bufio.Reader) (Article, error) {
 		case "link":
 			article.Link = value
 		case "date":
-			dateStr := value
-			modTime, timeErr := time.Parse(DefaultDateFormat, dateStr)
-			if timeErr == nil {
-				article.DateModified = &modTime
-				break
+			modTime, timeErr := ParseDate(value)
+			if timeErr != nil {
+				return article, &FrontMatterError{Line: lines[key], Key: key, Err: timeErr}
 			}
+			article.DateModified = &modTime
 
-			modTime, timeErr = time.Parse(IFTTTDateFormat, dateStr)
-			if timeErr == nil {
-				article.DateModified = &modTime
-				break
+		case "updated":
+			modTime, timeErr := ParseDate(value)
+			if timeErr != nil {
+				return article, &FrontMatterError{Line: lines[key], Key: key, Err: timeErr}
 			}
+			article.DateUpdated = &modTime
 
+		case "expires", "unpublish":
+			expires, timeErr := ParseDate(value)
 			if timeErr != nil {
-				return article, timeErr
+				return article, &FrontMatterError{Line: lines[key], Key: key, Err: timeErr}
 			}
+			article.Expires = &expires
 
-		case "updated":
-			dateStr := value
-			modTime, timeErr := time.Parse(DefaultDateFormat, dateStr)
-			if timeErr == nil {
-				article.DateUpdated = &modTime
-				break
-			}
+		case "expired-redirect":
+			article.ExpiredRedirect = value
 
-			modTime, timeErr = time.Parse(IFTTTDateFormat, dateStr)
-			if timeErr == nil {
-				article.DateUpdated = &modTime
-				break
+		case "edits":
+			edits, editsErr := parseEdits(value)
+			if editsErr != nil {
+				return article, &FrontMatterError{Line: lines[key], Key: key, Err: editsErr}
 			}
-
-			if timeErr != nil {
-				return article, timeErr
+			article.Edits = edits
+
+		case "series":
+			article.Series = value
+		case "canonical":
+			article.Canonical = value
+		case "cover":
+			article.Cover = value
+		case "syndication":
+			article.Syndication = append(article.Syndication, listValues(value)...)
+		case "sanitize":
+			article.Sanitize = value
+		case "password":
+			article.Password = value
+		case "references":
+			for _, reference := range strings.Split(value, "|") {
+				if reference = strings.TrimSpace(reference); reference != "" {
+					article.References = append(article.References, reference)
+				}
+			}
+		case "enclosure", "audio":
+			article.Enclosure = parseEnclosure(value, article.Enclosure)
+		case "duration":
+			if article.Enclosure == nil {
+				article.Enclosure = &Enclosure{}
+			}
+			article.Enclosure.Duration = value
+		case "explicit":
+			if article.Enclosure == nil {
+				article.Enclosure = &Enclosure{}
+			}
+			article.Enclosure.Explicit = (value == "true" || value == "yes")
+		case "reply-to", "in-reply-to":
+			article.InReplyTo = value
+		case "like-of":
+			article.LikeOf = value
+		case "repost-of":
+			article.RepostOf = value
+		case "bookmark-of":
+			article.BookmarkOf = value
+		case "attachments":
+			for _, name := range listValues(value) {
+				article.Attachments = append(article.Attachments, Attachment{Name: name})
+			}
+		case "aliases":
+			article.Aliases = append(article.Aliases, listValues(value)...)
+		case "css":
+			article.CSS = append(article.CSS, listValues(value)...)
+		case "js":
+			article.JS = append(article.JS, listValues(value)...)
+		case "head":
+			for _, line := range strings.Split(value, "|") {
+				if line = strings.TrimSpace(line); line != "" {
+					article.Head = append(article.Head, line)
+				}
 			}
-
 		case "appid":
 			article.AppID = value
 		case "draft":
 			article.Draft = (value == "true")
+		case "unlisted":
+			article.Unlisted = (value == "true")
+		case "pinned":
+			article.Pinned = (value == "true")
+		case "weight":
+			weight, weightErr := strconv.Atoi(value)
+			if weightErr != nil {
+				return article, &FrontMatterError{Line: lines[key], Key: key, Err: weightErr}
+			}
+			article.Weight = weight
+			article.Pinned = weight > 0
 		case "type":
 			switch value {
 			case "Post":
@@ -293,22 +960,49 @@ func ReadArticle(reader *bufio.Reader) (Article, error) {
 				article.Type = Page
 			case "Snippet":
 				article.Type = Snippet
+			case "Link":
+				article.Type = Link
 			}
 
-		case "tags":
-			fieldsFunc := func(divider rune) bool {
-				return unicode.IsSpace(divider) || divider == ',' || divider == ';'
-			}
+		case "outputs":
+			article.Outputs = []string{}
 
-			for _, tag := range strings.FieldsFunc(value, fieldsFunc) {
+			article.Outputs = append(article.Outputs, listValues(value)...)
+
+		case "tags":
+			for _, tag := range listValues(value) {
 				article.Tags = append(article.Tags, MakeTag(tag))
 			}
+		default:
+			for _, taxonomy := range Taxonomies {
+				if key != taxonomy {
+					continue
+				}
+
+				if article.Taxonomies == nil {
+					article.Taxonomies = make(map[string][]Tag)
+				}
+
+				for _, term := range listValues(value) {
+					article.Taxonomies[key] = append(article.Taxonomies[key], MakeTag(term))
+				}
+			}
 		}
 	}
 
+	if article.Enclosure != nil && article.Enclosure.URL == "" {
+		article.Enclosure = nil
+	}
+
+	// the newest edit dates the update, unless it is dated later
+	if len(article.Edits) > 0 && (article.DateUpdated == nil || article.DateUpdated.Before(*article.Edits[0].Date)) {
+		article.DateUpdated = article.Edits[0].Date
+	}
+
 	if article.DateModified == nil {
-		now := time.Now()
+		now := time.Now().In(Location)
 		article.DateModified = &now
+		article.Undated = true
 	}
 
 	var contentBuffer bytes.Buffer
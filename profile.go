@@ -0,0 +1,76 @@
+package main
+
+import (
+	"log"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"time"
+)
+
+// phaseTimer adds up time spent in generation phases
+type phaseTimer struct {
+	order     []string
+	durations map[string]time.Duration
+}
+
+var phases = newPhaseTimer()
+
+func newPhaseTimer() *phaseTimer {
+	return &phaseTimer{durations: map[string]time.Duration{}}
+}
+
+// measure starts timing a phase, adding to its total when the returned function is called
+func (p *phaseTimer) measure(phase string) func() {
+	started := time.Now()
+
+	return func() {
+		if _, found := p.durations[phase]; !found {
+			p.order = append(p.order, phase)
+		}
+
+		p.durations[phase] += time.Since(started)
+	}
+}
+
+// report logs the time spent in each phase, in the order phases first finished
+func (p *phaseTimer) report() {
+	for _, phase := range p.order {
+		log.Printf("%-10s %v", phase, p.durations[phase].Round(time.Microsecond))
+	}
+}
+
+// startProfiling writes a CPU profile to <prefix>.cpu until the returned function is called,
+// which then writes a heap profile to <prefix>.heap and reports phase timings
+func startProfiling(prefix string) func() {
+	cpuFile, err := os.Create(prefix + ".cpu")
+
+	if err != nil {
+		log.Fatalf("Could not create CPU profile: %v", err)
+	}
+
+	if err := pprof.StartCPUProfile(cpuFile); err != nil {
+		log.Fatalf("Could not start CPU profile: %v", err)
+	}
+
+	return func() {
+		pprof.StopCPUProfile()
+		cpuFile.Close()
+
+		heapFile, err := os.Create(prefix + ".heap")
+
+		if err != nil {
+			log.Printf("Could not create heap profile: %v", err)
+		} else {
+			runtime.GC()
+
+			if err := pprof.WriteHeapProfile(heapFile); err != nil {
+				log.Printf("Could not write heap profile: %v", err)
+			}
+
+			heapFile.Close()
+		}
+
+		phases.report()
+	}
+}
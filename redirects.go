@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+const redirectStub = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Redirecting…</title>
+<link rel="canonical" href="%[1]s">
+<meta name="robots" content="noindex">
+<meta http-equiv="refresh" content="0; url=%[1]s">
+</head>
+<body>
+<p>This page has moved to <a href="%[1]s">%[1]s</a>.</p>
+</body>
+</html>
+`
+
+// Redirect maps an old URL path to its new location
+type Redirect struct {
+	From string
+	To   string
+}
+
+// articleRedirects returns redirects from all article aliases to article paths
+func articleRedirects(articles post.Articles) []Redirect {
+	var redirects []Redirect
+
+	for _, article := range articles {
+		for _, alias := range article.Aliases {
+			redirects = append(redirects, Redirect{
+				From: sitePath(alias),
+				To:   sitePath(article.FullPath()),
+			})
+		}
+	}
+
+	return redirects
+}
+
+// rootPath returns the path part of the site root, which can also be an absolute URL
+func rootPath() string {
+	if root, err := url.Parse(*siteRoot); err == nil && root.Host != "" {
+		return path.Join("/", root.Path)
+	}
+
+	return path.Join("/", *siteRoot)
+}
+
+// sitePath turns a path relative to the site root into an absolute URL path
+func sitePath(relative string) string {
+	if target, err := url.Parse(relative); err == nil && target.IsAbs() {
+		return relative
+	}
+
+	trailingSlash := strings.HasSuffix(relative, "/")
+	joined := path.Join(rootPath(), relative)
+
+	if trailingSlash && joined != "/" {
+		joined += "/"
+	}
+
+	return joined
+}
+
+// absURL turns a path relative to the site root into a URL including the site's host, when the root has one
+func absURL(relative string) string {
+	if target, err := url.Parse(relative); err == nil && target.IsAbs() {
+		return relative
+	}
+
+	root, err := url.Parse(*siteRoot)
+
+	if err != nil || root.Host == "" {
+		return sitePath(relative)
+	}
+
+	root.Path = sitePath(relative)
+
+	return root.String()
+}
+
+// writeRedirectStubs writes meta refresh pages at the old locations of articles
+func writeRedirectStubs(destinationDir string, redirects []Redirect) {
+	root := rootPath()
+
+	for _, redirect := range redirects {
+		stubPath := strings.TrimPrefix(redirect.From, root)
+
+		if strings.HasSuffix(stubPath, "/") {
+			stubPath = path.Join(stubPath, "index.html")
+		}
+
+		stubFileName := path.Join(destinationDir, stubPath)
+
+		// stubs from earlier runs are replaced, generated pages are not
+		if existing, err := ioutil.ReadFile(stubFileName); err == nil && !bytes.Contains(existing, []byte(`http-equiv="refresh"`)) {
+			log.Printf("Not writing redirect %v over an existing file", stubFileName)
+			continue
+		}
+
+		os.MkdirAll(path.Dir(stubFileName), os.ModePerm)
+
+		stub := fmt.Sprintf(redirectStub, html.EscapeString(redirect.To))
+
+		if writeErr := writeFile(stubFileName, []byte(stub)); writeErr != nil {
+			log.Printf("Could not write file %v due to error: %v", stubFileName, writeErr)
+		}
+	}
+}
+
+// writeRedirectMaps writes server-side redirect files in the requested formats: netlify for
+// a _redirects file and nginx for a redirects.map file to be included in a map block
+func writeRedirectMaps(destinationDir string, redirects []Redirect, formats string) {
+	for _, format := range strings.Split(formats, ",") {
+		var buffer bytes.Buffer
+		var fileName string
+
+		switch strings.TrimSpace(format) {
+		case "":
+			continue
+		case "netlify":
+			fileName = "_redirects"
+			for _, redirect := range redirects {
+				fmt.Fprintf(&buffer, "%s %s 301\n", redirect.From, redirect.To)
+			}
+		case "nginx":
+			fileName = "redirects.map"
+			for _, redirect := range redirects {
+				fmt.Fprintf(&buffer, "%s %s;\n", redirect.From, redirect.To)
+			}
+		default:
+			log.Printf("Unknown redirect map format %q", format)
+			continue
+		}
+
+		mapFileName := path.Join(destinationDir, fileName)
+
+		if writeErr := writeFile(mapFileName, buffer.Bytes()); writeErr != nil {
+			log.Printf("Could not write file %v due to error: %v", mapFileName, writeErr)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/russross/blackfriday"
+
+	"macbirdie.net/blogger/post"
+)
+
+var citationPattern = regexp.MustCompile(`\[@(\d+)\]`)
+
+// referenceAnchor is the anchor of a bibliography entry, unique across articles on one page
+func referenceAnchor(article *post.Article, number int) string {
+	return fmt.Sprintf("ref:%s-%d", article.Identifier, number)
+}
+
+// addBibliography links [@N] citations to the article's references front matter list and
+// appends the list to the article content as a numbered bibliography
+func addBibliography(article *post.Article, renderer blackfriday.Renderer, extensions int) {
+	if len(article.References) == 0 {
+		return
+	}
+
+	article.Content = citationPattern.ReplaceAllStringFunc(article.Content, func(match string) string {
+		number, _ := strconv.Atoi(citationPattern.FindStringSubmatch(match)[1])
+
+		if number < 1 || number > len(article.References) {
+			return match
+		}
+
+		return fmt.Sprintf(`<a class="citation" href="#%s">[%d]</a>`, referenceAnchor(article, number), number)
+	})
+
+	var bibliography bytes.Buffer
+	bibliography.WriteString("<section class=\"references\">\n<h2>References</h2>\n<ol>\n")
+
+	for index, reference := range article.References {
+		rendered := string(blackfriday.Markdown([]byte(reference), renderer, extensions))
+		rendered = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(rendered), "<p>"), "</p>")
+
+		fmt.Fprintf(&bibliography, "<li id=\"%s\">%s</li>\n", referenceAnchor(article, index+1), rendered)
+	}
+
+	bibliography.WriteString("</ol>\n</section>\n")
+
+	article.Content += bibliography.String()
+}
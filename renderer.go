@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"html"
+	"net/url"
+	"strconv"
+
+	"github.com/russross/blackfriday"
+)
+
+// articleRenderer extends the blackfriday HTML renderer with blogger's own markup
+type articleRenderer struct {
+	blackfriday.Renderer
+
+	// footnotePrefix keeps footnote anchors of articles shown together on one page apart
+	footnotePrefix string
+	footnoteCount  int
+
+	headingIDs map[string]int
+
+	// articleDir is the directory of the article source, which relative image links start from
+	articleDir string
+
+	// figures are figures of titled images by their plain rendering, used when an image is the
+	// only content of its paragraph
+	figures map[string][]byte
+}
+
+// startArticle prepares the renderer for the next article
+func (r *articleRenderer) startArticle(identifier, articleDir string) {
+	r.articleDir = articleDir
+	r.footnotePrefix = identifier + "-"
+	r.footnoteCount = 0
+	r.headingIDs = map[string]int{}
+}
+
+// BlockCode renders diagram blocks, leaving other code blocks to the HTML renderer
+func (r *articleRenderer) BlockCode(out *bytes.Buffer, text []byte, info string) {
+	if renderDiagram(out, text, info) {
+		return
+	}
+
+	r.Renderer.BlockCode(out, text, info)
+}
+
+// Header renders headings with identifiers following the -heading-ids scheme and optional anchor
+// links. Identifiers given explicitly with {#id} are kept.
+func (r *articleRenderer) Header(out *bytes.Buffer, text func() bool, level int, id string) {
+	if *headingIDs == "none" && *headingAnchors == "" {
+		r.Renderer.Header(out, text, level, id)
+		return
+	}
+
+	marker := out.Len()
+
+	if !text() {
+		out.Truncate(marker)
+		return
+	}
+
+	content := append([]byte{}, out.Bytes()[marker:]...)
+	out.Truncate(marker)
+
+	if r.headingIDs == nil {
+		r.headingIDs = map[string]int{}
+	}
+
+	if id == "" {
+		id = headingID(content, r.headingIDs)
+	} else {
+		r.headingIDs[id]++
+	}
+
+	if out.Len() > 0 {
+		out.WriteByte('\n')
+	}
+
+	tag := "h" + strconv.Itoa(level)
+	out.WriteString("<" + tag)
+
+	if id != "" {
+		out.WriteString(` id="` + html.EscapeString(id) + `"`)
+	}
+
+	out.WriteString(">")
+	out.Write(content)
+
+	if id != "" && *headingAnchors != "" {
+		out.WriteString(` <a class="heading-anchor" href="#` + html.EscapeString(id) + `" aria-label="Link to this section">` + html.EscapeString(*headingAnchors) + `</a>`)
+	}
+
+	out.WriteString("</" + tag + ">\n")
+}
+
+// Image renders images with loading hints and, with -figures, with presentation hints. Titled
+// images alone in their paragraph become figures captioned with the title, see Paragraph.
+func (r *articleRenderer) Image(out *bytes.Buffer, link []byte, title []byte, alt []byte) {
+	cleanLink, hints := string(link), url.Values(nil)
+
+	if *figures {
+		cleanLink, hints = splitImageHints(cleanLink)
+	}
+
+	sized := hints.Get("width") != "" || hints.Get("height") != ""
+	attributes := lazyImageAttributes(cleanLink, r.articleDir, sized)
+
+	var image bytes.Buffer
+	r.Renderer.Image(&image, []byte(cleanLink), title, alt)
+	tag := bytes.Replace(image.Bytes(), []byte("<img "), []byte("<img"+attributes+" "), 1)
+
+	if !*figures {
+		out.Write(tag)
+		return
+	}
+
+	var plain bytes.Buffer
+	renderFigure(&plain, tag, "", hints)
+	out.Write(plain.Bytes())
+
+	if len(title) == 0 {
+		return
+	}
+
+	var untitled, figure bytes.Buffer
+	r.Renderer.Image(&untitled, []byte(cleanLink), nil, alt)
+	renderFigure(&figure, bytes.Replace(untitled.Bytes(), []byte("<img "), []byte("<img"+attributes+" "), 1), string(title), hints)
+
+	if r.figures == nil {
+		r.figures = map[string][]byte{}
+	}
+
+	r.figures[plain.String()] = figure.Bytes()
+}
+
+// Paragraph turns a titled image alone in its paragraph into a figure, leaving out the paragraph,
+// which cannot hold one. Images in running text stay plain images.
+func (r *articleRenderer) Paragraph(out *bytes.Buffer, text func() bool) {
+	marker := out.Len()
+	r.Renderer.Paragraph(out, text)
+
+	rendered := bytes.TrimSpace(out.Bytes()[marker:])
+	figures := r.figures
+	r.figures = nil
+
+	if !bytes.HasPrefix(rendered, []byte("<p>")) || !bytes.HasSuffix(rendered, []byte("</p>")) {
+		return
+	}
+
+	if figure, found := figures[string(rendered[len("<p>"):len(rendered)-len("</p>")])]; found {
+		out.Truncate(marker)
+
+		if marker > 0 {
+			out.WriteByte('\n')
+		}
+
+		out.Write(figure)
+		out.WriteByte('\n')
+	}
+}
+
+// FootnoteRef links to a footnote by its number within the article
+func (r *articleRenderer) FootnoteRef(out *bytes.Buffer, ref []byte, id int) {
+	number := strconv.Itoa(id)
+
+	out.WriteString(`<sup class="footnote-ref" id="fnref:` + r.footnotePrefix + number + `">`)
+	out.WriteString(`<a href="#fn:` + r.footnotePrefix + number + `">` + number + `</a></sup>`)
+}
+
+// FootnoteItem renders a footnote with a link back to where it is referenced. Footnotes
+// are listed in the order of their references, so counting them gives their numbers.
+func (r *articleRenderer) FootnoteItem(out *bytes.Buffer, name, text []byte, flags int) {
+	if flags&(blackfriday.LIST_ITEM_CONTAINS_BLOCK|blackfriday.LIST_ITEM_BEGINNING_OF_LIST) != 0 && out.Len() > 0 {
+		out.WriteByte('\n')
+	}
+
+	r.footnoteCount++
+	number := strconv.Itoa(r.footnoteCount)
+
+	out.WriteString(`<li id="fn:` + r.footnotePrefix + number + `">`)
+	out.Write(bytes.TrimRight(text, "\n"))
+	out.WriteString(` <a class="footnote-return" href="#fnref:` + r.footnotePrefix + number + `" aria-label="Back to reference ` + number + `">↩</a>`)
+	out.WriteString("</li>\n")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"macbirdie.net/blogger/post"
+)
+
+var reproducible = flag.Bool("reproducible", false, "Make builds of the same input byte-identical, dating the build by $SOURCE_DATE_EPOCH or the newest article")
+
+// reproducibleTime is what a reproducible build is dated with: $SOURCE_DATE_EPOCH when set,
+// otherwise the newest publication or update date of the dated articles
+func reproducibleTime(articles post.Articles) time.Time {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		if seconds, err := strconv.ParseInt(epoch, 10, 64); err == nil {
+			return time.Unix(seconds, 0).In(post.Location)
+		}
+
+		log.Printf("Ignoring invalid SOURCE_DATE_EPOCH %q", epoch)
+	}
+
+	latest := time.Unix(0, 0).In(post.Location)
+
+	for _, article := range articles {
+		if article.Undated {
+			continue
+		}
+
+		if article.DateModified.After(latest) {
+			latest = *article.DateModified
+		}
+
+		if article.DateUpdated != nil && article.DateUpdated.After(latest) {
+			latest = *article.DateUpdated
+		}
+	}
+
+	return latest
+}
+
+// pinBuildTime dates the build and undated articles with the reproducible time and orders articles
+// of the same date by identifier, returning a function restoring the clock
+func pinBuildTime(articles post.Articles) (time.Time, func()) {
+	pinned := reproducibleTime(articles)
+
+	for _, article := range articles {
+		if article.Undated {
+			date := pinned
+			article.DateModified = &date
+		}
+	}
+
+	sort.SliceStable(articles, func(i, j int) bool { return articles[i].Identifier < articles[j].Identifier })
+	sort.Stable(articles)
+
+	previousBuildTime := buildTime
+	buildTime = func() time.Time { return pinned }
+
+	return pinned, func() { buildTime = previousBuildTime }
+}
+
+// fixModificationTimes sets the modification time of every file in the destination to the build
+// time, so archives and caches of two builds match
+func fixModificationTimes(destinationDir string, date time.Time) {
+	filepath.Walk(destinationDir, func(fileName string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if chtimesErr := os.Chtimes(fileName, date, date); chtimesErr != nil {
+			log.Printf("Could not set modification time of %v: %v", fileName, chtimesErr)
+		}
+
+		return nil
+	})
+}
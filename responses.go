@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"html"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+const responseContextsCacheFileName = ".blogger-contexts.json"
+
+var metaTagPattern = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+
+var metaAttributePattern = regexp.MustCompile(`(?is)(name|property|content)\s*=\s*("[^"]*"|'[^']*')`)
+
+// pageMeta collects meta tag values of a page by name or property
+func pageMeta(page []byte) map[string]string {
+	meta := map[string]string{}
+
+	for _, tag := range metaTagPattern.FindAll(page, -1) {
+		var name, content string
+
+		for _, attribute := range metaAttributePattern.FindAllSubmatch(tag, -1) {
+			value := html.UnescapeString(strings.Trim(string(attribute[2]), `"'`))
+
+			if strings.EqualFold(string(attribute[1]), "content") {
+				content = value
+			} else {
+				name = strings.ToLower(value)
+			}
+		}
+
+		if name != "" && meta[name] == "" {
+			meta[name] = strings.Join(strings.Fields(content), " ")
+		}
+	}
+
+	return meta
+}
+
+// fetchCitation describes a page from its title and Open Graph or standard meta tags
+func fetchCitation(link string) (post.Citation, error) {
+	citation := post.Citation{URL: link}
+
+	page, err := fetchPage(link)
+
+	if err != nil {
+		return citation, err
+	}
+
+	meta := pageMeta(page)
+
+	firstOf := func(values ...string) string {
+		for _, value := range values {
+			if value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	citation.Title = firstOf(meta["og:title"], meta["twitter:title"], pageTitle(page))
+	citation.Author = firstOf(meta["author"], meta["article:author"], meta["og:site_name"])
+	citation.Summary = firstOf(meta["og:description"], meta["description"], meta["twitter:description"])
+
+	return citation, nil
+}
+
+// escapedCitation returns a citation with its third-party title, author and summary HTML-escaped,
+// as templates may print them as they are
+func escapedCitation(citation post.Citation) *post.Citation {
+	citation.Title = html.EscapeString(citation.Title)
+	citation.Author = html.EscapeString(citation.Author)
+	citation.Summary = html.EscapeString(citation.Summary)
+
+	return &citation
+}
+
+// fetchResponseContexts attaches a description of the page each reply, like, repost or
+// bookmark responds to. Descriptions are cached in the working directory between runs.
+func fetchResponseContexts(articles post.Articles) {
+	if !*responseContexts {
+		return
+	}
+
+	citations := map[string]post.Citation{}
+
+	if cached, err := ioutil.ReadFile(responseContextsCacheFileName); err == nil {
+		if err := json.Unmarshal(cached, &citations); err != nil {
+			log.Printf("Ignoring response context cache: %v", err)
+		}
+	}
+
+	fetched := false
+
+	for _, article := range articles {
+		link := article.ResponseURL()
+
+		if link == "" {
+			continue
+		}
+
+		citation, found := citations[link]
+
+		if !found {
+			var err error
+
+			if citation, err = fetchCitation(link); err != nil {
+				log.Printf("Could not fetch context of %v: %v", link, err)
+				continue
+			}
+
+			citations[link] = citation
+			fetched = true
+		}
+
+		article.Context = escapedCitation(citation)
+	}
+
+	if !fetched {
+		return
+	}
+
+	if cache, err := json.MarshalIndent(citations, "", "  "); err == nil {
+		if err := ioutil.WriteFile(responseContextsCacheFileName, cache, 0644); err != nil {
+			log.Printf("Could not store response context cache: %v", err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"macbirdie.net/blogger/post"
+)
+
+const humansTemplateFileName = "humans.txt"
+
+// writeRobots writes robots.txt with disallow rules relative to the site root and a sitemap reference
+func writeRobots(destinationDir string) {
+	var buffer bytes.Buffer
+
+	fmt.Fprintln(&buffer, "User-agent: *")
+
+	disallowed := false
+
+	for _, rule := range strings.Split(*robotsDisallow, ",") {
+		rule = strings.TrimSpace(rule)
+
+		if rule == "" {
+			continue
+		}
+
+		fmt.Fprintf(&buffer, "Disallow: %s\n", sitePath(rule))
+		disallowed = true
+	}
+
+	if !disallowed {
+		fmt.Fprintln(&buffer, "Disallow:")
+	}
+
+	sitemap := *sitemapURL
+
+	if sitemap == "" && (strings.HasPrefix(*siteRoot, "http://") || strings.HasPrefix(*siteRoot, "https://")) {
+		sitemap = strings.TrimSuffix(*siteRoot, "/") + "/sitemap.xml"
+	}
+
+	if sitemap != "" {
+		fmt.Fprintf(&buffer, "\nSitemap: %s\n", sitemap)
+	}
+
+	robotsFileName := path.Join(destinationDir, "robots.txt")
+
+	if writeErr := writeFile(robotsFileName, buffer.Bytes()); writeErr != nil {
+		log.Printf("Could not write file %v due to error: %v", robotsFileName, writeErr)
+	}
+}
+
+// writeHumans renders humans.txt from the templates directory, when the site has one
+func writeHumans(destinationDir string, funcMap template.FuncMap, articles post.Articles, data map[string]interface{}, now time.Time) {
+	templateFileName := path.Join(*templatesPath, humansTemplateFileName)
+
+	if _, err := os.Stat(templateFileName); err != nil {
+		return
+	}
+
+	humansTemplate, parseErr := template.New(humansTemplateFileName).Funcs(funcMap).ParseFiles(templateFileName)
+
+	if parseErr != nil {
+		log.Printf("Could not parse %v: %v", templateFileName, parseErr)
+		return
+	}
+
+	authorSet := map[string]bool{}
+
+	for _, article := range articles {
+		if article.Author != "" && !article.Draft {
+			authorSet[article.Author] = true
+		}
+	}
+
+	var authors []string
+
+	for author := range authorSet {
+		authors = append(authors, author)
+	}
+
+	sort.Strings(authors)
+
+	var buffer bytes.Buffer
+
+	context := pageContext(data, now)
+	context.Authors = authors
+
+	executeErr := humansTemplate.Execute(&buffer, context)
+
+	if executeErr != nil {
+		log.Printf("Could not render %v: %v", templateFileName, executeErr)
+		return
+	}
+
+	humansFileName := path.Join(destinationDir, humansTemplateFileName)
+
+	if writeErr := writeFile(humansFileName, buffer.Bytes()); writeErr != nil {
+		log.Printf("Could not write file %v due to error: %v", humansFileName, writeErr)
+	}
+}
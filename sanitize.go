@@ -0,0 +1,93 @@
+package main
+
+import (
+	"html"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+
+	"macbirdie.net/blogger/post"
+)
+
+// sanitizePolicies are the policies articles can be sanitized with
+var sanitizePolicies = map[string]*bluemonday.Policy{
+	"strict": bluemonday.StrictPolicy(),
+	"ugc":    bluemonday.UGCPolicy(),
+}
+
+// parseSanitizeSources reads the -sanitize flag, a comma-separated list of directory=policy entries
+func parseSanitizeSources(value string) map[string]string {
+	sources := map[string]string{}
+
+	for _, entry := range strings.Split(value, ",") {
+		values := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+
+		if len(values) != 2 {
+			if entry != "" {
+				log.Printf("Ignoring sanitize entry %q, expected directory=policy", entry)
+			}
+			continue
+		}
+
+		sources[path.Clean(values[0])] = values[1]
+	}
+
+	return sources
+}
+
+// policyStrictness orders sanitize policies, unknown ones counting as strict
+func policyStrictness(policyName string) int {
+	switch policyName {
+	case "", "none":
+		return 0
+	case "ugc":
+		return 1
+	}
+
+	return 2
+}
+
+// sanitizeArticle runs rendered content through the policy of its post directory, or a stricter
+// one the article asks for. Front matter can't relax the directory's policy, so ingested
+// content can't turn sanitizing off.
+func sanitizeArticle(article *post.Article, sourceDir string, sources map[string]string) {
+	policyName := sources[path.Clean(sourceDir)]
+
+	if policyStrictness(article.Sanitize) > policyStrictness(policyName) {
+		policyName = article.Sanitize
+	}
+
+	if policyStrictness(policyName) == 0 {
+		return
+	}
+
+	// stylesheets, scripts and head lines bypass the policy, so sanitized articles can't add them
+	article.CSS = nil
+	article.JS = nil
+	article.Head = nil
+
+	policy, found := sanitizePolicies[policyName]
+
+	if !found {
+		log.Printf("Unknown sanitize policy %q for %v, using strict", policyName, article.Identifier)
+		policy = sanitizePolicies["strict"]
+	}
+
+	article.Content = policy.Sanitize(article.Content)
+
+	// front matter values go into titles, meta tags and feeds as they are
+	article.Title = escapedText(article.Title)
+	article.Description = escapedText(article.Description)
+
+	for name, value := range article.Meta {
+		article.Meta[name] = escapedText(value)
+	}
+}
+
+// escapedText strips markup from a front matter value and HTML-escapes what's left, so it's safe in
+// element content and attribute values
+func escapedText(value string) string {
+	return html.EscapeString(html.UnescapeString(sanitizePolicies["strict"].Sanitize(value)))
+}
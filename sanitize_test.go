@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"macbirdie.net/blogger/post"
+)
+
+func TestSanitizeArticleFrontMatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources map[string]string
+		title   string
+		want    string
+	}{
+		{
+			name:    "script in title",
+			sources: map[string]string{"inbox": "ugc"},
+			title:   `<script>alert(1)</script>Hello`,
+			want:    "Hello",
+		},
+		{
+			name:    "quotes and ampersands escaped",
+			sources: map[string]string{"inbox": "strict"},
+			title:   `Fish & "chips"`,
+			want:    "Fish &amp; &#34;chips&#34;",
+		},
+		{
+			name:    "escaped markup stays text",
+			sources: map[string]string{"inbox": "ugc"},
+			title:   `&lt;script&gt;`,
+			want:    "&lt;script&gt;",
+		},
+		{
+			name:    "no policy",
+			sources: map[string]string{},
+			title:   `<em>Trusted</em>`,
+			want:    "<em>Trusted</em>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			article := &post.Article{
+				Title:       test.title,
+				Description: test.title,
+				Meta:        map[string]string{"og:title": test.title},
+			}
+
+			sanitizeArticle(article, "inbox", test.sources)
+
+			for field, value := range map[string]string{"title": article.Title, "description": article.Description, "meta": article.Meta["og:title"]} {
+				if value != test.want {
+					t.Errorf("%s = %q, want %q", field, value, test.want)
+				}
+			}
+
+			if len(test.sources) > 0 && strings.Contains(article.Title, "<") {
+				t.Errorf("title %q still holds markup", article.Title)
+			}
+		})
+	}
+}
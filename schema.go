@@ -0,0 +1,174 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+var frontMatterCheck = flag.String("front-matter-check", "warn", "What to do with front matter violating the schema: warn, fail or off")
+
+// typeSchema lists front matter keys an article type requires, allows or forbids. When keys are
+// allowed explicitly, any other key is a violation.
+type typeSchema struct {
+	Required  []string
+	Allowed   []string
+	Forbidden []string
+}
+
+// frontMatterSchema reads the schema table of the configuration, keyed by article type, e.g.
+//
+//	[schema.Post]
+//	required = ["title", "date"]
+//	[schema.Snippet]
+//	forbidden = ["title"]
+func frontMatterSchema() map[post.PageType]typeSchema {
+	schema := map[post.PageType]typeSchema{}
+	types, _ := siteConfig["schema"].(map[string]interface{})
+
+	for typeName, value := range types {
+		table, _ := value.(map[string]interface{})
+		schema[post.PageType(strings.Title(strings.ToLower(typeName)))] = typeSchema{
+			Required:  schemaKeys(table["required"]),
+			Allowed:   schemaKeys(table["allowed"]),
+			Forbidden: schemaKeys(table["forbidden"]),
+		}
+	}
+
+	return schema
+}
+
+// schemaKeys turns a configured list of keys into strings
+func schemaKeys(value interface{}) []string {
+	var keys []string
+
+	switch list := value.(type) {
+	case []interface{}:
+		for _, key := range list {
+			keys = append(keys, fmt.Sprint(key))
+		}
+	case string:
+		keys = strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+
+	return keys
+}
+
+// knownFrontMatterKey tells whether a key is read into articles at all
+func knownFrontMatterKey(key string) bool {
+	if strings.HasPrefix(key, "meta-") {
+		return true
+	}
+
+	for _, known := range post.FrontMatterKeys {
+		if key == known {
+			return true
+		}
+	}
+
+	for _, taxonomy := range post.Taxonomies {
+		if key == taxonomy {
+			return true
+		}
+	}
+
+	return false
+}
+
+// frontMatterViolations lists front matter keys of an article which are unknown, or break the
+// schema of its type, located by their lines
+func frontMatterViolations(article *post.Article, schema map[post.PageType]typeSchema) []*SourceError {
+	var violations []*SourceError
+	var keys []string
+
+	for key := range article.FrontMatter {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	// articles without a type are generated like posts
+	articleType := article.Type
+
+	if articleType == "" {
+		articleType = post.Post
+	}
+
+	typeRules, hasRules := schema[articleType]
+
+	for _, key := range keys {
+		if !knownFrontMatterKey(key) {
+			violations = append(violations, &SourceError{File: article.Source, Line: article.FrontMatterLines[key], Err: fmt.Errorf("unknown key %q", key)})
+			continue
+		}
+
+		if !hasRules {
+			continue
+		}
+
+		if containsString(typeRules.Forbidden, key) {
+			violations = append(violations, &SourceError{File: article.Source, Line: article.FrontMatterLines[key], Err: fmt.Errorf("key %q is not allowed in a %v", key, articleType)})
+		} else if len(typeRules.Allowed) > 0 && !containsString(typeRules.Allowed, key) && !containsString(typeRules.Required, key) {
+			violations = append(violations, &SourceError{File: article.Source, Line: article.FrontMatterLines[key], Err: fmt.Errorf("key %q is not allowed in a %v", key, articleType)})
+		}
+	}
+
+	for _, key := range typeRules.Required {
+		// a date in the file name stands for the date key
+		if key == "date" && !article.Undated {
+			continue
+		}
+
+		// so does a title taken from the first heading
+		if key == "title" && article.Title != "" {
+			continue
+		}
+
+		if _, found := article.FrontMatter[key]; !found {
+			violations = append(violations, &SourceError{File: article.Source, Err: fmt.Errorf("a %v requires key %q", articleType, key)})
+		}
+	}
+
+	return violations
+}
+
+// checkFrontMatter reports front matter violations of the read articles. Violations fail the
+// build with -front-matter-check fail, except while watching, where violating articles are left out.
+func checkFrontMatter(articles post.Articles) post.Articles {
+	if *frontMatterCheck == "off" {
+		return articles
+	}
+
+	schema := frontMatterSchema()
+	var valid post.Articles
+	failed := 0
+
+	for _, article := range articles {
+		violations := frontMatterViolations(article, schema)
+
+		if len(violations) > 0 && *frontMatterCheck == "fail" {
+			for _, violation := range violations {
+				reportError(violation)
+			}
+
+			failed++
+			continue
+		}
+
+		for _, violation := range violations {
+			log.Print(violation)
+		}
+
+		valid = append(valid, article)
+	}
+
+	if failed > 0 && !*listen {
+		failBuild("Front matter of %d articles violates the schema", failed)
+	}
+
+	return valid
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"macbirdie.net/blogger/post"
+)
+
+var sectionsList = flag.String("sections", "", "Posts directories with their own templates and index, as dir=templates (comma-separated), e.g. photos=templates-photos")
+
+// Section is a posts directory rendered with its own templates. Its articles are listed on the
+// section's index and feed at <name>/index.html and <name>/index.xml instead of the home page.
+type Section struct {
+	Name      string
+	Templates string
+}
+
+// sections returns the configured sections keyed by their cleaned posts directory
+func sections() map[string]Section {
+	configured := map[string]Section{}
+
+	for _, entry := range strings.Split(*sectionsList, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			log.Printf("Ignoring section %q, expected dir=templates", entry)
+			continue
+		}
+
+		dir := filepath.Clean(strings.TrimSpace(parts[0]))
+		configured[dir] = Section{Name: filepath.Base(dir), Templates: strings.TrimSpace(parts[1])}
+	}
+
+	return configured
+}
+
+// sectionName is the section a posts directory belongs to, empty when it has none
+func sectionName(postDir string) string {
+	return sections()[filepath.Clean(postDir)].Name
+}
+
+// sectionTemplates are the page and feed templates of a section
+type sectionTemplates struct {
+	pages pageTemplates
+	rss   *template.Template
+}
+
+// loadSectionTemplates parses the templates of every section, using the main templates for ones
+// a section doesn't have
+func loadSectionTemplates(funcMap template.FuncMap, pages pageTemplates, rssTemplate *template.Template) map[string]sectionTemplates {
+	loaded := map[string]sectionTemplates{}
+
+	for _, section := range sections() {
+		templates := sectionTemplates{pages: pages, rss: rssTemplate}
+
+		if sectionPages, err := parsePageTemplates(os.DirFS(section.Templates), funcMap); err != nil {
+			log.Printf("Could not parse page templates of %v, using the main templates: %v", section.Templates, err)
+		} else if sectionPages != nil {
+			templates.pages = sectionPages
+		}
+
+		fileName := path.Join(section.Templates, rssTemplateFileName)
+
+		if _, err := os.Stat(fileName); err == nil {
+			if parsed, err := template.New(rssTemplateFileName).Funcs(funcMap).ParseFiles(fileName); err != nil {
+				log.Printf("Could not parse %v, using the main template: %v", fileName, err)
+			} else {
+				templates.rss = parsed
+			}
+		}
+
+		loaded[section.Name] = templates
+	}
+
+	return loaded
+}
+
+// writeSectionIndexes writes the index page and feed of every section
+func writeSectionIndexes(destinationDir string, templates map[string]sectionTemplates, indexArticles, feedArticles map[string]post.Articles, context PageContext, cloud []TagCount) {
+	for name, sectionTemplate := range templates {
+		sort.Sort(indexArticles[name])
+		sortByFeedUpdate(feedArticles[name])
+
+		indexContext := context
+		indexContext.Section = name
+		indexContext.Title = strings.Title(name) + " – " + *blogTitle
+		indexContext.Articles = indexArticles[name].Pinned()
+		indexContext.Tags = cloud
+
+		os.MkdirAll(path.Join(destinationDir, name), os.ModePerm)
+		executeToFile(path.Join(destinationDir, name, "index.html"), sectionTemplate.pages.kind("index"), indexContext)
+
+		feedContext := context
+		feedContext.Section = name
+		feedContext.Title = indexContext.Title
+		feedContext.Home = true
+		feedContext.File = path.Join(name, "index.xml")
+		feedContext.Articles = feedArticles[name]
+		executeToFile(path.Join(destinationDir, name, "index.xml"), sectionTemplate.rss, feedContext)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+var readyOnce sync.Once
+
+// reloadAndRebuild rereads the configuration and rebuilds all sites, keeping the previous
+// configuration when the new one can't be read
+func reloadAndRebuild() {
+	if err := loadConfig(); err != nil {
+		log.Printf("Keeping previous configuration: %v", err)
+		return
+	}
+
+	configure()
+	configureDrafts()
+	forEachSite(build)
+}
+
+// signalReady logs that the generator is watching or serving, and tells systemd when it runs as
+// a notify service
+func signalReady() {
+	readyOnce.Do(func() {
+		log.Println("Ready")
+
+		socket := os.Getenv("NOTIFY_SOCKET")
+
+		if socket == "" {
+			return
+		}
+
+		// abstract sockets are given with a leading @
+		if strings.HasPrefix(socket, "@") {
+			socket = "\x00" + socket[1:]
+		}
+
+		connection, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+
+		if err != nil {
+			log.Printf("Could not notify systemd: %v", err)
+			return
+		}
+
+		defer connection.Close()
+
+		if _, err := connection.Write([]byte("READY=1")); err != nil {
+			log.Printf("Could not notify systemd: %v", err)
+		}
+	})
+}
+
+// handleSignals reloads the configuration and rebuilds on SIGHUP, and on SIGTERM or an interrupt
+// waits for the build in progress before exiting, removing the control socket
+func handleSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGTERM, os.Interrupt)
+
+	go func() {
+		for received := range signals {
+			if received == syscall.SIGHUP {
+				log.Println("Reloading configuration")
+				lockedBuild(reloadAndRebuild)
+				continue
+			}
+
+			log.Printf("Stopping on %v", received)
+
+			buildLock.Lock()
+
+			if strings.Contains(*controlAddress, "/") {
+				removeStaleSocket(*controlAddress)
+			}
+
+			os.Exit(0)
+		}
+	}()
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const shortcodesDirName = "shortcodes"
+
+// Shortcode is a single {{< name args >}} occurrence found in an article
+type Shortcode struct {
+	Name   string
+	Args   []string
+	Params map[string]string
+	Root   string
+}
+
+// Get returns a positional argument or a named parameter, depending on the key type
+func (s Shortcode) Get(key interface{}) string {
+	switch k := key.(type) {
+	case int:
+		if k >= 0 && k < len(s.Args) {
+			return s.Args[k]
+		}
+	case string:
+		return s.Params[k]
+	}
+
+	return ""
+}
+
+var shortcodePattern = regexp.MustCompile(`\{\{<\s*([A-Za-z0-9_-]+)((?:[^>]|>[^}])*?)\s*>\}\}`)
+
+var defaultShortcodes = map[string]string{
+	"youtube": `<div class="embed youtube"><iframe src="https://www.youtube-nocookie.com/embed/{{.Get 0}}" frameborder="0" allowfullscreen></iframe></div>`,
+	"figure":  `<figure><img src="{{.Get 0}}" alt="{{.Get 1}}">{{if .Get 1}}<figcaption>{{.Get 1}}</figcaption>{{end}}</figure>`,
+}
+
+// loadShortcodes reads built-in shortcodes and overrides them with *.html files
+// found in the shortcodes subdirectory of the templates directory
+func loadShortcodes(funcMap template.FuncMap) map[string]*template.Template {
+	shortcodes := map[string]*template.Template{}
+
+	for name, text := range defaultShortcodes {
+		shortcodes[name] = template.Must(template.New(name).Funcs(funcMap).Parse(text))
+	}
+
+	shortcodesDir := path.Join(*templatesPath, shortcodesDirName)
+	files, _ := ioutil.ReadDir(shortcodesDir)
+
+	for _, file := range files {
+		if file.IsDir() || path.Ext(file.Name()) != ".html" {
+			continue
+		}
+
+		name := strings.TrimSuffix(file.Name(), ".html")
+		shortcodeTemplate, err := template.New(file.Name()).Funcs(funcMap).ParseFiles(path.Join(shortcodesDir, file.Name()))
+
+		if err != nil {
+			log.Printf("Skipping shortcode %v due to error: %v", name, err)
+			continue
+		}
+
+		shortcodes[name] = shortcodeTemplate
+	}
+
+	return shortcodes
+}
+
+// splitShortcodeArgs splits shortcode arguments on whitespace, keeping double-quoted values together
+func splitShortcodeArgs(input string) []string {
+	var args []string
+	var current bytes.Buffer
+	quoted := false
+	started := false
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case unicode.IsSpace(r) && !quoted:
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+
+	if started {
+		args = append(args, current.String())
+	}
+
+	return args
+}
+
+func parseShortcode(name, argString string) Shortcode {
+	shortcode := Shortcode{Name: name, Params: map[string]string{}, Root: *siteRoot}
+
+	for _, arg := range splitShortcodeArgs(argString) {
+		if values := strings.SplitN(arg, "=", 2); len(values) == 2 && !strings.ContainsAny(values[0], "/:") {
+			shortcode.Params[values[0]] = values[1]
+			continue
+		}
+
+		shortcode.Args = append(shortcode.Args, arg)
+	}
+
+	return shortcode
+}
+
+// expandShortcodes replaces shortcodes in raw markdown with placeholders, so that the
+// markdown renderer leaves them alone. The returned map holds rendered HTML for each placeholder.
+func expandShortcodes(content []byte, shortcodes map[string]*template.Template) ([]byte, map[string]string) {
+	rendered := map[string]string{}
+
+	expanded := shortcodePattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		groups := shortcodePattern.FindSubmatch(match)
+		name := string(groups[1])
+
+		shortcodeTemplate, found := shortcodes[name]
+
+		if !found {
+			log.Printf("Unknown shortcode %q, leaving as is", name)
+			return match
+		}
+
+		buffer := bytes.NewBufferString("")
+
+		if err := shortcodeTemplate.Execute(buffer, parseShortcode(name, string(groups[2]))); err != nil {
+			log.Printf("Could not render shortcode %q: %v", name, err)
+			return match
+		}
+
+		placeholder := fmt.Sprintf("BLOGGERSHORTCODE%dEND", len(rendered))
+		rendered[placeholder] = buffer.String()
+
+		return []byte(placeholder)
+	})
+
+	return expanded, rendered
+}
+
+// replacePlaceholders puts rendered shortcodes and other protected content back in place of their placeholders
+func replacePlaceholders(html string, rendered map[string]string) string {
+	for placeholder, renderedHTML := range rendered {
+		html = strings.Replace(html, "<p>"+placeholder+"</p>", renderedHTML, -1)
+		html = strings.Replace(html, placeholder, renderedHTML, -1)
+	}
+
+	return html
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path"
+	"time"
+)
+
+// A key for signing is made with: openssl genpkey -algorithm ed25519 -out blogger-key.pem
+var signingKey = flag.String("sign-key", "", "PEM Ed25519 private key signing the manifest, writing a minisign signature next to it and the public key as minisign.pub, which readers should get out of band")
+
+const minisignPublicKeyFileName = "minisign.pub"
+
+// loadSigningKey reads a PKCS#8 Ed25519 private key
+func loadSigningKey(fileName string) (ed25519.PrivateKey, error) {
+	contents, err := ioutil.ReadFile(fileName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(contents)
+
+	if block == nil {
+		return nil, errors.New("no PEM key found")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, isEd25519 := key.(ed25519.PrivateKey)
+
+	if !isEd25519 {
+		return nil, errors.New("not an Ed25519 key")
+	}
+
+	return privateKey, nil
+}
+
+// minisignKeyID identifies a public key in minisign files, derived from the key itself
+func minisignKeyID(publicKey ed25519.PublicKey) []byte {
+	hash := sha256.Sum256(publicKey)
+	return hash[:8]
+}
+
+// minisignPublicKey formats a public key the way minisign -p reads it
+func minisignPublicKey(publicKey ed25519.PublicKey) string {
+	keyID := minisignKeyID(publicKey)
+	var printedID uint64
+
+	// minisign prints the little-endian key number
+	for index := len(keyID) - 1; index >= 0; index-- {
+		printedID = printedID<<8 | uint64(keyID[index])
+	}
+
+	encoded := append(append([]byte("Ed"), keyID...), publicKey...)
+
+	return fmt.Sprintf("untrusted comment: minisign public key %016X\n%s\n", printedID, base64.StdEncoding.EncodeToString(encoded))
+}
+
+// minisignSignature signs a file's contents in the minisign format, with a trusted comment naming
+// the file and when it was signed
+func minisignSignature(privateKey ed25519.PrivateKey, fileName string, contents []byte, date time.Time) string {
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	signature := ed25519.Sign(privateKey, contents)
+	trustedComment := fmt.Sprintf("timestamp:%d\tfile:%s", date.Unix(), fileName)
+	globalSignature := ed25519.Sign(privateKey, append(append([]byte{}, signature...), trustedComment...))
+
+	encoded := append(append([]byte("Ed"), minisignKeyID(publicKey)...), signature...)
+
+	return fmt.Sprintf("untrusted comment: signature from blogger\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(encoded), trustedComment, base64.StdEncoding.EncodeToString(globalSignature))
+}
+
+// signManifest signs the written manifest and writes the public key next to it. A key served by
+// the same site proves nothing to readers, who verify against a copy they got out of band, like
+// one published in the blog's repository or announced elsewhere:
+//
+//	minisign -Vm manifest.json -p trusted-minisign.pub
+func signManifest(manifestFileName string, contents []byte) {
+	if *signingKey == "" {
+		return
+	}
+
+	privateKey, err := loadSigningKey(*signingKey)
+
+	if err != nil {
+		log.Printf("Could not read signing key %v: %v", *signingKey, err)
+		return
+	}
+
+	signature := minisignSignature(privateKey, path.Base(manifestFileName), contents, buildTime())
+	publicKey := minisignPublicKey(privateKey.Public().(ed25519.PublicKey))
+
+	if err := writeFile(manifestFileName+".minisig", []byte(signature)); err != nil {
+		log.Printf("Could not write file %v due to error: %v", manifestFileName+".minisig", err)
+	}
+
+	publicKeyFileName := path.Join(path.Dir(manifestFileName), minisignPublicKeyFileName)
+
+	if err := writeFile(publicKeyFileName, []byte(publicKey)); err != nil {
+		log.Printf("Could not write file %v due to error: %v", publicKeyFileName, err)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+// Site describes the whole site, available as .Site in every template. Params holds the params
+// table of the configuration file, like the author, social links or an analytics ID.
+type Site struct {
+	Title  string
+	Root   string
+	Params map[string]interface{}
+}
+
+// currentSite is the site being generated
+var currentSite Site
+
+// configureSite collects the site description from flags and the configuration file
+func configureSite() {
+	params, _ := siteConfig["params"].(map[string]interface{})
+
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	currentSite = Site{Title: *blogTitle, Root: *siteRoot, Params: params}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"path"
+
+	"macbirdie.net/blogger/post"
+)
+
+// writeSitemap lists the home page, published articles and their attachments in sitemap.xml.
+// Sitemaps need absolute URLs, so it is only written when the site root has a host.
+func writeSitemap(destinationDir string, articles post.Articles) {
+	if absURL("/") == sitePath("/") {
+		return
+	}
+
+	var buffer bytes.Buffer
+
+	fmt.Fprintln(&buffer, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(&buffer, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	fmt.Fprintf(&buffer, "<url><loc>%s</loc></url>\n", xmlEscape(absURL("/")))
+
+	for _, article := range articles {
+		if article.Draft || article.Unlisted || expired(article) {
+			continue
+		}
+
+		modified := article.DateModified
+
+		if article.DateUpdated != nil {
+			modified = article.DateUpdated
+		}
+
+		fmt.Fprintf(&buffer, "<url><loc>%s</loc><lastmod>%s</lastmod></url>\n",
+			xmlEscape(absURL(article.FullPath())), modified.Format(post.DefaultDateFormat))
+
+		for _, attachment := range article.Attachments {
+			fmt.Fprintf(&buffer, "<url><loc>%s</loc></url>\n", xmlEscape(absURL(attachmentPath(article, attachment))))
+		}
+	}
+
+	fmt.Fprintln(&buffer, `</urlset>`)
+
+	sitemapFileName := path.Join(destinationDir, "sitemap.xml")
+
+	if writeErr := writeFile(sitemapFileName, buffer.Bytes()); writeErr != nil {
+		log.Printf("Could not write file %v due to error: %v", sitemapFileName, writeErr)
+	}
+}
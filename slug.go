@@ -0,0 +1,226 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+
+	"macbirdie.net/blogger/post"
+)
+
+// transliterations cover letters that do not decompose into a base letter and a diacritic
+var transliterations = strings.NewReplacer(
+	"ł", "l", "Ł", "L",
+	"ß", "ss",
+	"æ", "ae", "Æ", "AE",
+	"ø", "o", "Ø", "O",
+	"œ", "oe", "Œ", "OE",
+	"đ", "d", "Đ", "D",
+	"þ", "th", "Þ", "TH",
+	"ð", "d", "Ð", "D",
+	"ı", "i",
+)
+
+// scriptLetters romanize Cyrillic and Greek letters, given in lowercase
+var scriptLetters = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+	'ђ': "dj", 'ј': "j", 'љ': "lj", 'њ': "nj", 'ћ': "c", 'џ': "dz", 'ѓ': "gj", 'ќ': "kj", 'ѕ': "dz",
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i",
+	'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s",
+	'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+	'ά': "a", 'έ': "e", 'ή': "i", 'ί': "i", 'ό': "o", 'ύ': "y", 'ώ': "o", 'ϊ': "i", 'ϋ': "y", 'ΐ': "i", 'ΰ': "y",
+}
+
+// kana romanizes hiragana in Hepburn; katakana is mapped onto hiragana first
+var kana = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko", 'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so", 'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to", 'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho", 'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'を': "wo", 'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+}
+
+// smallKana combine with a preceding -i syllable, as in きゃ kya or しょ sho
+var smallKana = map[rune]string{'ゃ': "a", 'ゅ': "u", 'ょ': "o"}
+
+// Revised Romanization of the initial, medial and final parts of Hangul syllables
+var (
+	hangulInitials = []string{"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s", "ss", "", "j", "jj", "ch", "k", "t", "p", "h"}
+	hangulMedials  = []string{"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i"}
+	hangulFinals   = []string{"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l", "p", "l", "m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t"}
+)
+
+// hiragana maps katakana onto the matching hiragana
+func hiragana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヶ' {
+		return r - 0x60
+	}
+
+	return r
+}
+
+// romanize spells Cyrillic, Greek, kana and Hangul letters with Latin ones. Chinese characters
+// have no reading without a dictionary and are left as they are.
+func romanize(text string) string {
+	letters := []rune(text)
+	var romanized strings.Builder
+
+	for index := 0; index < len(letters); index++ {
+		r := letters[index]
+		lower := unicode.ToLower(r)
+
+		if latin, found := scriptLetters[lower]; found {
+			if lower != r && latin != "" {
+				latin = strings.ToUpper(latin[:1]) + latin[1:]
+			}
+
+			romanized.WriteString(latin)
+			continue
+		}
+
+		if r >= 0xAC00 && r <= 0xD7A3 {
+			syllable := int(r - 0xAC00)
+			romanized.WriteString(hangulInitials[syllable/588] + hangulMedials[syllable%588/28] + hangulFinals[syllable%28])
+			continue
+		}
+
+		switch hiragana(r) {
+		case 'っ':
+			// a small tsu doubles the next consonant
+			if index+1 < len(letters) {
+				if next := kana[hiragana(letters[index+1])]; len(next) > 1 {
+					romanized.WriteString(strings.Replace(next[:1], "c", "t", 1))
+				}
+			}
+			continue
+		case 'ー':
+			continue
+		}
+
+		syllable, found := kana[hiragana(r)]
+
+		if !found {
+			romanized.WriteRune(r)
+			continue
+		}
+
+		if index+1 < len(letters) && len(syllable) > 1 && strings.HasSuffix(syllable, "i") {
+			if vowel, small := smallKana[hiragana(letters[index+1])]; small {
+				syllable = strings.TrimSuffix(syllable, "i")
+
+				if syllable != "sh" && syllable != "ch" && syllable != "j" {
+					syllable += "y"
+				}
+
+				syllable += vowel
+				index++
+			}
+		}
+
+		romanized.WriteString(syllable)
+	}
+
+	return romanized.String()
+}
+
+// transliterate approximates text with ASCII letters, dropping diacritics
+func transliterate(text string) string {
+	stripDiacritics := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(stripDiacritics, transliterations.Replace(romanize(text)))
+
+	if err != nil {
+		return text
+	}
+
+	return result
+}
+
+// slugify turns text into a lowercase, hyphen-separated identifier, optionally limited to ASCII
+func slugify(text string, ascii bool) string {
+	if ascii {
+		text = transliterate(text)
+	}
+
+	var slug strings.Builder
+	pendingHyphen := false
+
+	for _, r := range strings.ToLower(text) {
+		keep := unicode.IsLetter(r) || unicode.IsDigit(r)
+
+		if ascii && r > unicode.MaxASCII {
+			keep = false
+		}
+
+		if !keep {
+			pendingHyphen = slug.Len() > 0
+			continue
+		}
+
+		if pendingHyphen {
+			slug.WriteByte('-')
+			pendingHyphen = false
+		}
+
+		slug.WriteRune(r)
+	}
+
+	return slug.String()
+}
+
+// articleSlug returns the file name an article is published under according to the -slugs
+// scheme. Names with nothing to transliterate, like ones written in Chinese characters, keep
+// their Unicode letters under the ascii scheme.
+func articleSlug(name string) string {
+	switch *slugScheme {
+	case "ascii":
+		if slug := slugify(name, true); slug != "" {
+			return slug
+		}
+
+		fallthrough
+	case "unicode":
+		if slug := slugify(name, false); slug != "" {
+			return slug
+		}
+	}
+
+	return name
+}
+
+// articleStem is the slugged name an article's page is written under, without the extension,
+// for files published next to the page
+func articleStem(article *post.Article) string {
+	if article.Filename == "" {
+		return article.Identifier
+	}
+
+	if *uglyURLs {
+		return strings.TrimSuffix(article.Filename, *destinationExt)
+	}
+
+	return article.Filename
+}
+
+// configureSlugs checks the -slugs scheme
+func configureSlugs() {
+	switch *slugScheme {
+	case "", "ascii", "unicode":
+	default:
+		log.Fatalf("Unknown slug scheme %q, expected ascii or unicode", *slugScheme)
+	}
+}
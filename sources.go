@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+var publishSources = flag.Bool("publish-sources", false, "Publish the Markdown source of each article next to its page, and an index.md listing them")
+
+// markdownLinkText escapes brackets in the text of Markdown links
+var markdownLinkText = strings.NewReplacer("[", "\\[", "]", "\\]")
+
+// hasPublishedSource tells whether the Markdown source of an article is published. Protected
+// articles keep their source private.
+func hasPublishedSource(article *post.Article) bool {
+	return *publishSources && article.Password == "" && sourceRenderer(path.Ext(article.Source)) == "markdown"
+}
+
+// markdownSourcePath is where the source of an article is published, relative to the site root
+func markdownSourcePath(article *post.Article) string {
+	return path.Join(article.BasePath(), articleStem(article)+".md")
+}
+
+// sourceLink is the address of the published source of an article, empty when it has none, for
+// links like <link rel="alternate" type="text/markdown" href="{{sourceLink .Article}}">
+func sourceLink(article *post.Article) string {
+	if !hasPublishedSource(article) {
+		return ""
+	}
+
+	return sitePath(markdownSourcePath(article))
+}
+
+// writeMarkdownSource copies the source of an article next to its page
+func writeMarkdownSource(destinationDir string, article *post.Article) {
+	if !hasPublishedSource(article) {
+		return
+	}
+
+	source, err := ioutil.ReadFile(article.Source)
+
+	if err != nil {
+		log.Printf("Could not publish the source of %v: %v", article.Identifier, err)
+		return
+	}
+
+	fileName := path.Join(destinationDir, markdownSourcePath(article))
+
+	if writeErr := writeFile(fileName, source); writeErr != nil {
+		log.Printf("Could not write file %v due to error: %v", fileName, writeErr)
+	}
+}
+
+// writeMarkdownIndex writes index.md listing the published sources of listed articles, newest first
+func writeMarkdownIndex(destinationDir string, articles post.Articles) {
+	if !*publishSources {
+		return
+	}
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "# %s\n\n", *blogTitle)
+
+	for _, article := range articles {
+		if !hasPublishedSource(article) {
+			continue
+		}
+
+		title := article.Title
+
+		if title == "" {
+			title = article.Identifier
+		}
+
+		fmt.Fprintf(&buffer, "- %s [%s](%s)\n", article.DateModified.In(post.Location).Format("2006-01-02"), markdownLinkText.Replace(title), sourceLink(article))
+	}
+
+	fileName := path.Join(destinationDir, "index.md")
+
+	if writeErr := writeFile(fileName, buffer.Bytes()); writeErr != nil {
+		log.Printf("Could not write file %v due to error: %v", fileName, writeErr)
+	}
+}
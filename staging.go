@@ -0,0 +1,81 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"macbirdie.net/blogger/hooks"
+)
+
+// prepareStaging creates a staging directory next to the destination, seeded with hard links to
+// the files already published, so files the generator does not produce are kept. Generated files
+// replace the links instead of writing through them, leaving the destination untouched.
+func prepareStaging(destination string) (string, error) {
+	staging := filepath.Clean(destination) + ".staging"
+
+	if err := os.RemoveAll(staging); err != nil {
+		return "", err
+	}
+
+	err := filepath.Walk(destination, func(sourcePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relative, _ := filepath.Rel(destination, sourcePath)
+		target := filepath.Join(staging, relative)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+
+		if linkErr := os.Link(sourcePath, target); linkErr != nil {
+			return copyFile(sourcePath, target)
+		}
+
+		return nil
+	})
+
+	return staging, err
+}
+
+// swapStaging replaces the destination with a completely generated staging directory
+func swapStaging(staging, destination string) error {
+	previous := filepath.Clean(destination) + ".previous"
+
+	if err := os.RemoveAll(previous); err != nil {
+		return err
+	}
+
+	if err := os.Rename(destination, previous); err != nil {
+		return err
+	}
+
+	if err := os.Rename(staging, destination); err != nil {
+		os.Rename(previous, destination)
+		return err
+	}
+
+	return os.RemoveAll(previous)
+}
+
+// build generates the site, through a staging directory swapped in at the end when -staging is set,
+// and runs after-build hooks on the result
+func build() {
+	if !*staging {
+		generate(*destinationPath)
+	} else if stagingDir, err := prepareStaging(*destinationPath); err != nil {
+		log.Fatalf("Could not prepare staging directory: %v", err)
+	} else {
+		generate(stagingDir)
+
+		if err := swapStaging(stagingDir, *destinationPath); err != nil {
+			log.Printf("Could not replace destination with the staging directory %v: %v", stagingDir, err)
+		}
+	}
+
+	if err := runHooks(hooks.AfterBuild, nil, *destinationPath); err != nil {
+		log.Printf("After-build hook failed: %v", err)
+	}
+}
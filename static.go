@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// staticDirs lists the directories of the -static flag
+func staticDirs() []string {
+	var dirs []string
+
+	for _, dir := range strings.Split(*staticPaths, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
+// copyStatic copies files of static directories into the destination, skipping files that are up to
+// date but keeping them in the manifest
+func copyStatic(destination string) {
+	for _, dir := range staticDirs() {
+		filepath.Walk(dir, func(sourcePath string, info os.FileInfo, err error) error {
+			if err != nil {
+				log.Printf("Skipping static %v due to error: %v", sourcePath, err)
+				return nil
+			}
+
+			relative, _ := filepath.Rel(dir, sourcePath)
+			target := filepath.Join(destination, relative)
+
+			if info.IsDir() {
+				os.MkdirAll(target, os.ModePerm)
+				return nil
+			}
+
+			// scrubbed images are copied again, so turning -strip-exif on scrubs published ones too
+			if existing, err := os.Stat(target); err == nil && existing.Size() == info.Size() && !existing.ModTime().Before(info.ModTime()) && !scrubsImage(sourcePath) {
+				// files left in place are still outputs of the build
+				if hash, err := fileHash(target); err == nil {
+					recordOutput(target, sourcePath, hash)
+					return nil
+				}
+			}
+
+			if err := copyFile(sourcePath, target); err != nil {
+				log.Printf("Could not copy static %v: %v", sourcePath, err)
+			}
+
+			return nil
+		})
+	}
+}
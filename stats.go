@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"macbirdie.net/blogger/post"
+)
+
+const accessLogDateFormat = "02/Jan/2006:15:04:05 -0700"
+
+var accessLogPattern = regexp.MustCompile(`^\S+ \S+ \S+ \[([^\]]+)\] "(\S+) (\S+)[^"]*" (\d{3}) \S+(?: "[^"]*" "([^"]*)")?`)
+
+var botPattern = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|fetch|monitor|preview`)
+
+var statsReportTemplate = template.Must(template.New("stats").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Title}} – views</title></head>
+<body>
+<h1>{{.Title}} – views</h1>
+{{if .From}}<p>{{.From.Format "Jan _2, 2006"}} – {{.To.Format "Jan _2, 2006"}}</p>{{end}}
+<table>
+<thead><tr><th>Article</th><th>Path</th><th>Views</th></tr></thead>
+<tbody>
+{{range .Articles}}<tr><td>{{.Title}}</td><td><a href="{{.Path}}">{{.Path}}</a></td><td>{{.Views}}</td></tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
+
+// ArticleStats is a view count of a single article
+type ArticleStats struct {
+	Identifier string `json:"identifier"`
+	Title      string `json:"title"`
+	Type       string `json:"type"`
+	Path       string `json:"path"`
+	Views      int    `json:"views"`
+}
+
+// StatsReport summarises views of all articles found in access logs
+type StatsReport struct {
+	Title    string          `json:"title"`
+	From     *time.Time      `json:"from,omitempty"`
+	To       *time.Time      `json:"to,omitempty"`
+	Articles []*ArticleStats `json:"articles"`
+}
+
+// articleURLs maps every URL path an article can be requested at to its stats entry
+func articleURLs(articles post.Articles) (map[string]*ArticleStats, []*ArticleStats) {
+	urls := map[string]*ArticleStats{}
+	var entries []*ArticleStats
+
+	for _, article := range articles {
+		if article.Draft {
+			continue
+		}
+
+		entry := &ArticleStats{
+			Identifier: article.Identifier,
+			Title:      article.Title,
+			Type:       string(article.Type),
+			Path:       sitePath(article.FullPath()),
+		}
+
+		entries = append(entries, entry)
+		urls[entry.Path] = entry
+		urls[strings.TrimSuffix(entry.Path, *destinationExt)] = entry
+
+		for _, alias := range article.Aliases {
+			urls[sitePath(alias)] = entry
+		}
+	}
+
+	return urls, entries
+}
+
+func openLog(fileName string) (io.ReadCloser, error) {
+	file, err := os.Open(fileName)
+
+	if err != nil || !strings.HasSuffix(fileName, ".gz") {
+		return file, err
+	}
+
+	reader, err := gzip.NewReader(file)
+
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{reader, file}, nil
+}
+
+// countViews adds successful, non-bot page requests from a common or combined format log
+func countViews(reader io.Reader, urls map[string]*ArticleStats, report *StatsReport) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		matches := accessLogPattern.FindStringSubmatch(scanner.Text())
+
+		if matches == nil || matches[2] != "GET" || (matches[4] != "200" && matches[4] != "304") {
+			continue
+		}
+
+		if botPattern.MatchString(matches[5]) {
+			continue
+		}
+
+		requestPath := strings.SplitN(strings.SplitN(matches[3], "?", 2)[0], "#", 2)[0]
+		entry, found := urls[requestPath]
+
+		if !found {
+			entry, found = urls[strings.TrimSuffix(requestPath, "/")]
+		}
+
+		if !found {
+			continue
+		}
+
+		entry.Views++
+
+		if date, err := time.Parse(accessLogDateFormat, matches[1]); err == nil {
+			if report.From == nil || date.Before(*report.From) {
+				report.From = &date
+			}
+
+			if report.To == nil || date.After(*report.To) {
+				report.To = &date
+			}
+		}
+	}
+}
+
+// stats builds a per-article view count report from web server access logs
+func stats(args []string) {
+	statsFlags := flag.NewFlagSet("stats", flag.ExitOnError)
+	format := statsFlags.String("format", "json", "Report format: json, html")
+	output := statsFlags.String("output", "", "Report file, standard output when empty")
+	statsFlags.Parse(args)
+
+	if statsFlags.NArg() == 0 {
+		log.Fatal("Pass access log files to the stats command")
+	}
+
+	articles := readArticles(findSourceFiles(), loadShortcodes(templateFuncs()), markdownRenderer(0))
+	urls, entries := articleURLs(articles)
+	report := StatsReport{Title: *blogTitle, Articles: entries}
+
+	for _, fileName := range statsFlags.Args() {
+		reader, err := openLog(fileName)
+
+		if err != nil {
+			log.Printf("Skipping log %v due to error: %v", fileName, err)
+			continue
+		}
+
+		countViews(reader, urls, &report)
+		reader.Close()
+	}
+
+	sort.SliceStable(report.Articles, func(i, j int) bool {
+		return report.Articles[i].Views > report.Articles[j].Views
+	})
+
+	writer := io.Writer(os.Stdout)
+
+	if *output != "" {
+		file, err := os.Create(*output)
+
+		if err != nil {
+			log.Fatalf("Could not create %v: %v", *output, err)
+		}
+
+		defer file.Close()
+		writer = file
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	case "html":
+		statsReportTemplate.Execute(writer, report)
+	default:
+		log.Fatalf("Unknown report format %q", *format)
+	}
+}
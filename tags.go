@@ -0,0 +1,97 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+// TagCount is a tag with the number of listed articles it is used in
+type TagCount struct {
+	Tag   post.Tag
+	Count int
+}
+
+// tagPath returns the file of a tag page, relative to the destination, according to -tag-path
+func tagPath(fileName string) string {
+	return strings.NewReplacer("{name}", fileName, "{ext}", *destinationExt).Replace(*tagPathPattern)
+}
+
+// tagIndexName returns the file name of a tag page. Templates may pass a tag, which keeps the
+// underscore prefix of hidden tags, or a tag's file name.
+func tagIndexName(tag interface{}) string {
+	switch t := tag.(type) {
+	case post.Tag:
+		return tagPath(t.FileName())
+	case *post.Tag:
+		return tagPath(t.FileName())
+	case string:
+		return tagPath(t)
+	}
+
+	return ""
+}
+
+// tagCloud counts visible tags of articles, leaving hidden tags out, ordered by name
+func tagCloud(articles post.Articles) []TagCount {
+	counts := map[string]*TagCount{}
+
+	for _, article := range articles {
+		for _, tag := range article.VisibleTags() {
+			if count, found := counts[tag.Name]; found {
+				count.Count++
+				continue
+			}
+
+			counts[tag.Name] = &TagCount{Tag: tag, Count: 1}
+		}
+	}
+
+	cloud := make([]TagCount, 0, len(counts))
+
+	for _, count := range counts {
+		cloud = append(cloud, *count)
+	}
+
+	sort.Slice(cloud, func(i, j int) bool { return cloud[i].Tag.Name < cloud[j].Tag.Name })
+
+	return cloud
+}
+
+// configureTags sets up tag normalization and aliases applied while reading articles
+func configureTags(normalization, aliases string) {
+	post.NormalizeTag = nil
+	post.TagAliases = map[string]string{}
+
+	switch normalization {
+	case "ascii":
+		post.NormalizeTag = func(name string) string { return slugify(name, true) }
+	case "slug":
+		post.NormalizeTag = func(name string) string { return slugify(name, false) }
+	case "":
+	default:
+		log.Fatalf("Unknown tag normalization %q, expected ascii or slug", normalization)
+	}
+
+	if !strings.Contains(*tagPathPattern, "{name}") {
+		log.Fatalf("Tag path %q has no {name} placeholder", *tagPathPattern)
+	}
+
+	for _, alias := range strings.Split(aliases, ",") {
+		names := strings.SplitN(alias, "=", 2)
+
+		if len(names) != 2 {
+			continue
+		}
+
+		from, to := strings.ToLower(strings.TrimSpace(names[0])), strings.ToLower(strings.TrimSpace(names[1]))
+
+		if post.NormalizeTag != nil {
+			from, to = post.NormalizeTag(from), post.NormalizeTag(to)
+		}
+
+		post.TagAliases[from] = to
+	}
+}
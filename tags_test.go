@@ -0,0 +1,97 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"macbirdie.net/blogger/post"
+)
+
+// taggedArticle makes an article with tags given the way front matter lists them
+func taggedArticle(tags ...string) *post.Article {
+	article := &post.Article{}
+
+	for _, tag := range tags {
+		article.Tags = append(article.Tags, post.MakeTag(tag))
+	}
+
+	return article
+}
+
+func TestTagCloud(t *testing.T) {
+	tests := []struct {
+		name     string
+		articles post.Articles
+		want     map[string]int
+	}{
+		{
+			name:     "no articles",
+			articles: post.Articles{},
+			want:     map[string]int{},
+		},
+		{
+			name:     "counts articles per tag",
+			articles: post.Articles{taggedArticle("go", "web"), taggedArticle("go"), taggedArticle("go", "travel")},
+			want:     map[string]int{"go": 3, "web": 1, "travel": 1},
+		},
+		{
+			name:     "merges tags differing in case",
+			articles: post.Articles{taggedArticle("Go"), taggedArticle("go")},
+			want:     map[string]int{"go": 2},
+		},
+		{
+			name:     "leaves hidden tags out",
+			articles: post.Articles{taggedArticle("go", "-draft"), taggedArticle("-draft")},
+			want:     map[string]int{"go": 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cloud := tagCloud(test.articles)
+			counts := map[string]int{}
+
+			for index, count := range cloud {
+				counts[count.Tag.Name] = count.Count
+
+				if index > 0 && cloud[index-1].Tag.Name >= count.Tag.Name {
+					t.Errorf("tag %q is listed after %q", count.Tag.Name, cloud[index-1].Tag.Name)
+				}
+			}
+
+			if !reflect.DeepEqual(counts, test.want) {
+				t.Errorf("got counts %v, want %v", counts, test.want)
+			}
+		})
+	}
+}
+
+func TestTagIndexName(t *testing.T) {
+	defer func(pattern, ext string) { *tagPathPattern, *destinationExt = pattern, ext }(*tagPathPattern, *destinationExt)
+
+	hidden := post.MakeTag("-draft")
+
+	tests := []struct {
+		name    string
+		pattern string
+		ext     string
+		tag     interface{}
+		want    string
+	}{
+		{name: "tag", pattern: "tag-{name}{ext}", ext: ".html", tag: post.MakeTag("go"), want: "tag-go.html"},
+		{name: "tag pointer", pattern: "tag-{name}{ext}", ext: ".html", tag: &hidden, want: "tag-_draft.html"},
+		{name: "hidden tag", pattern: "tag-{name}{ext}", ext: "", tag: hidden, want: "tag-_draft"},
+		{name: "file name", pattern: "tags/{name}/index.html", ext: "", tag: "go", want: "tags/go/index.html"},
+		{name: "other value", pattern: "tag-{name}{ext}", ext: "", tag: 1, want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			*tagPathPattern, *destinationExt = test.pattern, test.ext
+
+			if got := tagIndexName(test.tag); got != test.want {
+				t.Errorf("tagIndexName(%v) = %q, want %q", test.tag, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"path"
+	"text/template"
+	"time"
+
+	"macbirdie.net/blogger/post"
+)
+
+// termIndexName is the file name of the index page of a taxonomy term
+func termIndexName(taxonomy, term string) string {
+	return taxonomy + "-" + term + *destinationExt
+}
+
+// termFeedName is the file name of the feed of a taxonomy term
+func termFeedName(taxonomy, term string) string {
+	return "index-" + taxonomy + "-" + term + ".xml"
+}
+
+// writeTaxonomyPages writes an index page and a feed for each term of a taxonomy
+func writeTaxonomyPages(destinationDir, taxonomy string, mainTemplate, rssTemplate *template.Template, articles post.Articles, data map[string]interface{}, now time.Time) {
+	// terms spelled differently share a page, named after their first spelling
+	terms := map[string]post.Tag{}
+	articlesByTerm := map[string]post.Articles{}
+
+	for _, article := range articles {
+		for _, term := range article.Terms(taxonomy) {
+			fileName := term.FileName()
+
+			if _, found := terms[fileName]; !found {
+				terms[fileName] = term
+			}
+
+			if listed := articlesByTerm[fileName]; len(listed) == 0 || listed[len(listed)-1] != article {
+				articlesByTerm[fileName] = append(listed, article)
+			}
+		}
+	}
+
+	for fileName, term := range terms {
+		pageTerm := term
+		termArticles := articlesByTerm[fileName]
+
+		termContext := pageContext(data, now)
+		termContext.Articles = termArticles
+		termContext.Title = taxonomy + ": " + term.OriginalName + " – " + *blogTitle
+		termContext.Taxonomy = taxonomy
+		termContext.Term = &pageTerm
+		executeToFile(path.Join(destinationDir, termIndexName(taxonomy, fileName)), mainTemplate, termContext)
+
+		feedContext := pageContext(data, now)
+		feedContext.Home = true
+		feedContext.File = termFeedName(taxonomy, fileName)
+		feedContext.Articles = termArticles
+		feedContext.Taxonomy = taxonomy
+		feedContext.Term = &pageTerm
+		executeToFile(path.Join(destinationDir, feedContext.File), rssTemplate, feedContext)
+	}
+}
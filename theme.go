@@ -0,0 +1,39 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"log"
+	"os"
+	"text/template"
+)
+
+// defaultTheme holds the page and feed templates used when the templates directory is missing,
+// so a bare posts directory can be built. Pages share the base layout, article, tag and term
+// pages overriding its blocks.
+//
+//go:embed theme/*.html
+var defaultTheme embed.FS
+
+var defaultThemeNotice bool
+
+// templateFiles returns the templates directory, or the default theme when it doesn't exist
+func templateFiles() fs.FS {
+	if info, err := os.Stat(*templatesPath); err == nil && info.IsDir() {
+		return os.DirFS(*templatesPath)
+	}
+
+	if !defaultThemeNotice {
+		log.Printf("No templates directory %v, using the default theme", *templatesPath)
+		defaultThemeNotice = true
+	}
+
+	theme, _ := fs.Sub(defaultTheme, "theme")
+
+	return theme
+}
+
+// parseTemplate parses a template of the templates directory or of the default theme
+func parseTemplate(name string, funcMap template.FuncMap) (*template.Template, error) {
+	return template.New(name).Funcs(funcMap).ParseFS(templateFiles(), name)
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+const manageHelp = `Commands:
+  N                   show the front matter of post N
+  d N                 toggle post N between draft and published
+  e N key [value]     set a front matter field of post N, removing it without a value
+  b                   build the site
+  p                   deploy the site with the -deploy command
+  r                   reload the list
+  q                   quit`
+
+// ManagedPost is a source file listed in the management UI
+type ManagedPost struct {
+	File        string
+	FrontMatter map[string]string
+}
+
+// Draft tells whether the post is a draft
+func (m ManagedPost) Draft() bool {
+	return m.FrontMatter["draft"] == "true"
+}
+
+// managedPosts reads the front matter of all source files, newest first
+func managedPosts() []ManagedPost {
+	var posts []ManagedPost
+
+	for _, sourceFile := range findSourceFiles() {
+		file, err := os.Open(sourceFile.Path)
+
+		if err != nil {
+			continue
+		}
+
+		frontMatter, err := post.ParseFrontMatter(bufio.NewReader(file))
+		file.Close()
+
+		if err != nil {
+			continue
+		}
+
+		if _, found := frontMatter["date"]; !found {
+			if _, date := post.SplitDatedName(sourceFile.Name); date != nil {
+				frontMatter["date"] = date.Format(post.DefaultDateFormat)
+			}
+		}
+
+		posts = append(posts, ManagedPost{File: sourceFile.Path, FrontMatter: frontMatter})
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].FrontMatter["date"] > posts[j].FrontMatter["date"]
+	})
+
+	return posts
+}
+
+// setFrontMatterValue changes a field in the front matter of a source file, adding it when it's
+// missing and removing it when the value is empty. The rest of the file is kept as it is.
+func setFrontMatterValue(fileName, key, value string) error {
+	contents, err := ioutil.ReadFile(fileName)
+
+	if err != nil {
+		return err
+	}
+
+	lines := strings.SplitAfter(string(contents), "\n")
+
+	if len(lines) == 0 || !strings.HasPrefix(strings.TrimPrefix(lines[0], "\ufeff"), "---") {
+		return fmt.Errorf("%v has no front matter", fileName)
+	}
+
+	end := -1
+
+	for index := 1; index < len(lines); index++ {
+		if strings.HasPrefix(lines[index], "---") {
+			end = index
+			break
+		}
+	}
+
+	if end < 0 {
+		return fmt.Errorf("%v has unterminated front matter", fileName)
+	}
+
+	field := key + ": " + value + "\n"
+	replaced := false
+	var result []string
+
+	for index, line := range lines {
+		if index > 0 && index < end && !replaced {
+			if name := strings.SplitN(line, ":", 2); len(name) == 2 && strings.TrimSpace(name[0]) == key {
+				replaced = true
+
+				if value != "" {
+					result = append(result, field)
+				}
+
+				continue
+			}
+		}
+
+		if index == end && !replaced && value != "" {
+			result = append(result, field)
+		}
+
+		result = append(result, line)
+	}
+
+	return ioutil.WriteFile(fileName, []byte(strings.Join(result, "")), 0644)
+}
+
+// isTerminal tells whether a file is an interactive terminal
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// printManagedPosts lists posts with their number, draft marker, date, type and title
+func printManagedPosts(posts []ManagedPost) {
+	if isTerminal(os.Stdout) {
+		fmt.Print("\x1b[H\x1b[2J")
+	}
+
+	fmt.Printf("%s: %d posts\n\n", *blogTitle, len(posts))
+
+	for index, managed := range posts {
+		marker := " "
+
+		if managed.Draft() {
+			marker = "D"
+		}
+
+		date := managed.FrontMatter["date"]
+
+		if len(date) > 10 {
+			date = date[:10]
+		}
+
+		title := managed.FrontMatter["title"]
+
+		if title == "" {
+			title = "(" + managed.File + ")"
+		}
+
+		fmt.Printf("%4d %s %-10s %-8s %s\n", index+1, marker, date, managed.FrontMatter["type"], title)
+	}
+
+	fmt.Println()
+}
+
+// managedPost returns the post numbered in a command argument
+func managedPost(posts []ManagedPost, argument string) (ManagedPost, error) {
+	number, err := strconv.Atoi(argument)
+
+	if err != nil || number < 1 || number > len(posts) {
+		return ManagedPost{}, fmt.Errorf("no post %q", argument)
+	}
+
+	return posts[number-1], nil
+}
+
+// manage runs an interactive terminal UI listing posts and drafts, editing their front matter and
+// building or deploying the site
+func manage(args []string) {
+	manageFlags := flag.NewFlagSet("manage", flag.ExitOnError)
+	manageFlags.Parse(args)
+
+	configureDrafts()
+
+	input := bufio.NewScanner(os.Stdin)
+	posts := managedPosts()
+	printManagedPosts(posts)
+	fmt.Println(manageHelp)
+
+	for {
+		fmt.Print("> ")
+
+		if !input.Scan() {
+			fmt.Println()
+			return
+		}
+
+		fields := strings.Fields(input.Text())
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		var err error
+		listChanged := false
+
+		switch command := fields[0]; {
+		case command == "q":
+			return
+		case command == "r":
+			listChanged = true
+		case command == "b":
+			lockedBuild(func() { forEachSite(build) })
+			status := currentStatus()
+			fmt.Printf("Built in %s with %d errors\n", status.Duration, status.Errors)
+		case command == "p":
+			var output string
+			output, err = deploy()
+			fmt.Print(output)
+		case command == "d" && len(fields) == 2:
+			var managed ManagedPost
+
+			if managed, err = managedPost(posts, fields[1]); err == nil {
+				draft := "true"
+
+				if managed.Draft() {
+					draft = ""
+				}
+
+				err = setFrontMatterValue(managed.File, "draft", draft)
+				listChanged = err == nil
+			}
+		case command == "e" && len(fields) >= 3:
+			var managed ManagedPost
+
+			if managed, err = managedPost(posts, fields[1]); err == nil {
+				value := strings.Join(fields[3:], " ")
+				err = setFrontMatterValue(managed.File, fields[2], value)
+				listChanged = err == nil
+			}
+		case len(fields) == 1:
+			var managed ManagedPost
+
+			if managed, err = managedPost(posts, command); err == nil {
+				var keys []string
+
+				for key := range managed.FrontMatter {
+					keys = append(keys, key)
+				}
+
+				sort.Strings(keys)
+				fmt.Println(managed.File)
+
+				for _, key := range keys {
+					fmt.Printf("  %s: %s\n", key, managed.FrontMatter[key])
+				}
+			} else {
+				err = nil
+				fmt.Println(manageHelp)
+			}
+		default:
+			fmt.Println(manageHelp)
+		}
+
+		if err != nil {
+			fmt.Println("Error:", err)
+		}
+
+		if listChanged {
+			posts = managedPosts()
+			printManagedPosts(posts)
+		}
+	}
+}
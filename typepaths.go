@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+
+	"macbirdie.net/blogger/post"
+)
+
+var typePaths = flag.String("type-paths", "", "Directories articles of each type are placed in, as a comma-separated list of type=directory with :year, :month and :day placeholders, e.g. post=blog/:year/:month,snippet=micro,page=")
+var uglyURLs = flag.Bool("ugly-urls", true, "Write articles to files named after them, otherwise to index.html in directories named after them, with paths ending in a slash")
+
+// configureTypePaths reads -type-paths, leaving unlisted types in their default directories, and -ugly-urls
+func configureTypePaths() {
+	post.UglyURLs = *uglyURLs
+	post.TypePaths = map[post.PageType]string{}
+
+	for _, entry := range strings.Split(*typePaths, ",") {
+		values := strings.SplitN(entry, "=", 2)
+
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		articleType := post.PageType(strings.Title(strings.ToLower(strings.TrimSpace(values[0]))))
+
+		switch articleType {
+		case post.Post, post.Page, post.Snippet, post.Link:
+		default:
+			log.Printf("Ignoring path of unknown type %q", values[0])
+			continue
+		}
+
+		if len(values) < 2 {
+			log.Printf("Ignoring invalid type path %q, expected type=directory", entry)
+			continue
+		}
+
+		post.TypePaths[articleType] = strings.Trim(strings.TrimSpace(values[1]), "/")
+	}
+}
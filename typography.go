@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/russross/blackfriday"
+
+	"macbirdie.net/blogger/funcs"
+)
+
+var smartypants = flag.String("smartypants", "full", "Typography of rendered Markdown: off, quotes (curly quotes only), full (quotes and fractions), dashes (full, with -- and --- turned into en and em dashes), guillemets (« » quotes) or french (guillemets with non-breaking spaces)")
+
+// fullTypography are the smartypants flags articles were always rendered with. Blackfriday turns
+// dashes only with HTML_SMARTYPANTS_DASHES, so they are left alone unless asked for.
+const fullTypography = blackfriday.HTML_USE_SMARTYPANTS | blackfriday.HTML_SMARTYPANTS_FRACTIONS | blackfriday.HTML_SMARTYPANTS_LATEX_DASHES
+
+// typographyModes are the smartypants flags of each -smartypants mode
+var typographyModes = map[string]int{
+	"off":        0,
+	"quotes":     blackfriday.HTML_USE_SMARTYPANTS,
+	"full":       fullTypography,
+	"dashes":     fullTypography | blackfriday.HTML_SMARTYPANTS_DASHES,
+	"guillemets": fullTypography | blackfriday.HTML_SMARTYPANTS_ANGLED_QUOTES,
+	"french":     fullTypography | blackfriday.HTML_SMARTYPANTS_ANGLED_QUOTES | blackfriday.HTML_SMARTYPANTS_QUOTES_NBSP,
+}
+
+// typographyFlags returns the smartypants renderer flags of the configured mode
+func typographyFlags() int {
+	if modeFlags, found := typographyModes[*smartypants]; found {
+		return modeFlags
+	}
+
+	log.Printf("Unknown smartypants mode %q, using full", *smartypants)
+
+	return typographyModes["full"]
+}
+
+// configureTypography applies the typography mode to Markdown rendered in templates, so it matches articles
+func configureTypography() {
+	funcs.TypographyFlags = typographyFlags()
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/net/html"
+
+	"macbirdie.net/blogger/post"
+)
+
+var validateHTML = flag.Bool("validate-html", false, "Report unclosed tags, duplicate identifiers and images without alt text in rendered articles")
+
+// optionalEndElements may be left open, ended by the next sibling or their parent's end tag
+var optionalEndElements = map[string]bool{
+	"html": true, "head": true, "body": true, "p": true, "li": true, "dt": true, "dd": true, "option": true,
+	"optgroup": true, "thead": true, "tbody": true, "tfoot": true, "tr": true, "td": true, "th": true,
+	"colgroup": true, "caption": true, "rt": true, "rp": true,
+}
+
+// HTMLIssue is a problem found in HTML, located by line
+type HTMLIssue struct {
+	File    string
+	Source  string
+	Line    int
+	Problem string
+}
+
+func (h HTMLIssue) String() string {
+	if h.Source != "" {
+		return fmt.Sprintf("%s (%s:%d): %s", h.Source, h.File, h.Line, h.Problem)
+	}
+
+	return fmt.Sprintf("%s:%d: %s", h.File, h.Line, h.Problem)
+}
+
+// openElement is an element whose end tag hasn't been seen yet
+type openElement struct {
+	name string
+	line int
+}
+
+// validateMarkup finds unclosed and stray tags, duplicate identifiers and images without alt text
+func validateMarkup(fileName string, contents []byte) []HTMLIssue {
+	var issues []HTMLIssue
+	var open []openElement
+
+	ids := map[string]int{}
+	foreign := 0
+	line := 1
+	tokenizer := html.NewTokenizer(bytes.NewReader(contents))
+
+	report := func(line int, format string, args ...interface{}) {
+		issues = append(issues, HTMLIssue{File: fileName, Line: line, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	unclosed := func(elements []openElement) {
+		for index := len(elements) - 1; index >= 0; index-- {
+			if !optionalEndElements[elements[index].name] {
+				report(elements[index].line, "<%s> is never closed", elements[index].name)
+			}
+		}
+	}
+
+	for {
+		tokenType := tokenizer.Next()
+		tokenLine := line
+		line += bytes.Count(tokenizer.Raw(), []byte("\n"))
+
+		if tokenType == html.ErrorToken {
+			if tokenizer.Err() != io.EOF {
+				report(tokenLine, "unreadable markup: %v", tokenizer.Err())
+			}
+			break
+		}
+
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken && tokenType != html.EndTagToken {
+			continue
+		}
+
+		token := tokenizer.Token()
+		name := token.Data
+
+		if tokenType == html.EndTagToken {
+			if voidElements[name] {
+				continue
+			}
+
+			match := -1
+
+			for index := len(open) - 1; index >= 0; index-- {
+				if open[index].name == name {
+					match = index
+					break
+				}
+			}
+
+			if match < 0 {
+				report(tokenLine, "</%s> closes no open element", name)
+				continue
+			}
+
+			unclosed(open[match+1:])
+			open = open[:match]
+
+			if name == "svg" || name == "math" {
+				foreign--
+			}
+
+			continue
+		}
+
+		for _, attribute := range token.Attr {
+			if attribute.Key != "id" || attribute.Val == "" {
+				continue
+			}
+
+			if firstLine, found := ids[attribute.Val]; found {
+				report(tokenLine, "duplicate id %q, first used on line %d", attribute.Val, firstLine)
+			} else {
+				ids[attribute.Val] = tokenLine
+			}
+		}
+
+		if _, hasAlt := attributeValue(token, "alt"); name == "img" && !hasAlt {
+			report(tokenLine, "<img> without alt text")
+		}
+
+		if voidElements[name] || (tokenType == html.SelfClosingTagToken && foreign > 0) {
+			continue
+		}
+
+		if tokenType == html.SelfClosingTagToken {
+			report(tokenLine, "<%s/> is not closed by its slash in HTML", name)
+		}
+
+		if name == "svg" || name == "math" {
+			foreign++
+		}
+
+		open = append(open, openElement{name: name, line: tokenLine})
+	}
+
+	unclosed(open)
+
+	return issues
+}
+
+// validateArticle reports markup problems of a rendered article against its source file
+func validateArticle(article *post.Article) {
+	if !*validateHTML {
+		return
+	}
+
+	for _, issue := range validateMarkup(article.Source, []byte(article.Content)) {
+		log.Printf("%s: %s, on line %d of the rendered content", article.Source, issue.Problem, issue.Line)
+	}
+}
+
+// outputSources maps outputs to the source files they were generated from, using the manifest
+// of the destination
+func outputSources(destinationDir string) map[string]string {
+	sources := map[string]string{}
+
+	if *manifestFile == "" {
+		return sources
+	}
+
+	contents, err := ioutil.ReadFile(path.Join(destinationDir, *manifestFile))
+
+	if err != nil {
+		return sources
+	}
+
+	manifest := buildManifest{}
+
+	if err := json.Unmarshal(contents, &manifest); err != nil {
+		log.Printf("Ignoring unreadable manifest: %v", err)
+		return sources
+	}
+
+	for source, sourceOutputs := range manifest {
+		for output := range sourceOutputs {
+			sources[output] = source
+		}
+	}
+
+	return sources
+}
+
+// checkMarkup validates all HTML files of the destination directory, attributing problems to
+// the source files of the pages
+func checkMarkup(destinationDir string) []HTMLIssue {
+	sources := outputSources(destinationDir)
+	var issues []HTMLIssue
+
+	filepath.Walk(destinationDir, func(fileName string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		ext := path.Ext(fileName)
+
+		if ext != ".html" && ext != ".htm" && ext != *destinationExt {
+			return nil
+		}
+
+		contents, readErr := ioutil.ReadFile(fileName)
+
+		if readErr != nil {
+			log.Printf("Skipping %v due to error: %v", fileName, readErr)
+			return nil
+		}
+
+		relative, _ := filepath.Rel(destinationDir, fileName)
+
+		for _, issue := range validateMarkup(fileName, contents) {
+			issue.Source = sources[filepath.ToSlash(relative)]
+			issues = append(issues, issue)
+		}
+
+		return nil
+	})
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Source != issues[j].Source {
+			return issues[i].Source < issues[j].Source
+		}
+		return issues[i].File < issues[j].File
+	})
+
+	return issues
+}
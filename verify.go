@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"macbirdie.net/blogger/golden"
+)
+
+// buildFailure stops a snapshot build where a normal build would exit
+type buildFailure struct {
+	err error
+}
+
+// generateSnapshot generates the site as configured into a destination, as if it was generated
+// at the given time. Failures that end a normal build, and errors reported during it, are returned.
+func generateSnapshot(at time.Time) golden.Generator {
+	return func(destination string) (err error) {
+		previousBuildTime := buildTime
+		buildTime = func() time.Time { return at }
+		defer func() { buildTime = previousBuildTime }()
+
+		previousAbort := abortBuild
+		abortBuild = func(err error) { panic(buildFailure{err}) }
+		defer func() { abortBuild = previousAbort }()
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if failure, isFailure := recovered.(buildFailure); isFailure {
+					err = failure.err
+				} else {
+					err = fmt.Errorf("build failed: %v", recovered)
+				}
+			}
+		}()
+
+		generate(destination)
+
+		buildErrors.Lock()
+		defer buildErrors.Unlock()
+
+		if count := len(buildErrors.errors); count > 0 {
+			return fmt.Errorf("build finished with %d errors, the first being %v", count, buildErrors.errors[0])
+		}
+
+		return nil
+	}
+}
+
+// verify generates the site and compares it with golden files, so template and renderer changes
+// can be checked in continuous integration
+func verify(args []string) {
+	verifyFlags := flag.NewFlagSet("verify", flag.ExitOnError)
+	goldenDir := verifyFlags.String("golden", "golden", "Directory with the expected output")
+	update := verifyFlags.Bool("update", false, "Replace golden files with the generated output")
+	at := verifyFlags.String("time", "2000-01-01T00:00:00Z", "Time the site is generated at, in RFC 3339 format")
+	verifyFlags.Parse(args)
+
+	generatedAt, err := time.Parse(time.RFC3339, *at)
+
+	if err != nil {
+		log.Fatalf("Invalid -time: %v", err)
+	}
+
+	snapshot, err := golden.BuildSnapshot(generateSnapshot(generatedAt))
+
+	if err != nil {
+		log.Fatalf("Could not generate the site: %v", err)
+	}
+
+	if *update {
+		if err := golden.Update(snapshot, *goldenDir); err != nil {
+			log.Fatalf("Could not update golden files: %v", err)
+		}
+
+		log.Printf("Updated %d golden files in %v", len(snapshot), *goldenDir)
+		return
+	}
+
+	differences, err := golden.Compare(snapshot, *goldenDir)
+
+	if err != nil {
+		log.Fatalf("Could not read golden files: %v", err)
+	}
+
+	for _, difference := range differences {
+		fmt.Println(difference)
+	}
+
+	if len(differences) > 0 {
+		log.Fatalf("Found %d files differing from golden files", len(differences))
+	}
+
+	log.Printf("All %d files match golden files", len(snapshot))
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// version is set when building releases with -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+const releasesURL = "https://api.github.com/repos/onfoot/blogger/releases/latest"
+
+// buildInfo describes the binary with its version, source revision and toolchain
+func buildInfo() []string {
+	info := []string{"blogger " + version}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		settings := map[string]string{}
+
+		for _, setting := range build.Settings {
+			settings[setting.Key] = setting.Value
+		}
+
+		if revision := settings["vcs.revision"]; revision != "" {
+			if settings["vcs.modified"] == "true" {
+				revision += " (modified)"
+			}
+
+			info = append(info, "revision: "+revision)
+		}
+
+		if built := settings["vcs.time"]; built != "" {
+			info = append(info, "commit time: "+built)
+		}
+
+		if build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info = append(info, "module: "+build.Main.Path+" "+build.Main.Version)
+		}
+	}
+
+	return append(info, "go: "+runtime.Version()+" "+runtime.GOOS+"/"+runtime.GOARCH)
+}
+
+// versionNumbers splits a version like v1.2.3 into numbers, nil when it isn't one
+func versionNumbers(version string) []int {
+	var numbers []int
+
+	for _, part := range strings.Split(strings.TrimPrefix(version, "v"), ".") {
+		number, err := strconv.Atoi(strings.SplitN(part, "-", 2)[0])
+
+		if err != nil {
+			return nil
+		}
+
+		numbers = append(numbers, number)
+	}
+
+	return numbers
+}
+
+// newerVersion tells whether a released version is newer than the running one
+func newerVersion(released, running string) bool {
+	releasedNumbers, runningNumbers := versionNumbers(released), versionNumbers(running)
+
+	if releasedNumbers == nil {
+		return false
+	}
+
+	if runningNumbers == nil {
+		return true
+	}
+
+	for index := 0; index < len(releasedNumbers); index++ {
+		if index >= len(runningNumbers) {
+			return releasedNumbers[index] > 0
+		}
+
+		if releasedNumbers[index] != runningNumbers[index] {
+			return releasedNumbers[index] > runningNumbers[index]
+		}
+	}
+
+	return false
+}
+
+// latestRelease fetches the tag and address of the latest release
+func latestRelease(address string) (string, string, error) {
+	client := http.Client{Timeout: 10 * time.Second}
+	response, err := client.Get(address)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("%s", response.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(&release); err != nil {
+		return "", "", err
+	}
+
+	return release.TagName, release.HTMLURL, nil
+}
+
+// versionCommand prints build information, optionally checking whether a newer release exists
+func versionCommand(args []string) {
+	versionFlags := flag.NewFlagSet("version", flag.ExitOnError)
+	checkUpdate := versionFlags.Bool("check", false, "Check whether a newer release is available")
+	updateURL := versionFlags.String("releases", releasesURL, "Address of the latest release in the GitHub API format")
+	versionFlags.Parse(args)
+
+	for _, line := range buildInfo() {
+		fmt.Println(line)
+	}
+
+	if !*checkUpdate {
+		return
+	}
+
+	latest, address, err := latestRelease(*updateURL)
+
+	if err != nil {
+		log.Fatalf("Could not check for updates: %v", err)
+	}
+
+	if newerVersion(latest, version) {
+		fmt.Printf("Update available: %s %s\n", latest, address)
+		return
+	}
+
+	fmt.Println("Up to date")
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// xhtmlNamespaces are the namespaces of foreign elements embedded in HTML, like inline SVG
+var xhtmlNamespaces = map[string]string{
+	"svg":  "http://www.w3.org/2000/svg",
+	"math": "http://www.w3.org/1998/Math/MathML",
+}
+
+var xhtmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+var xhtmlAttributeEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;")
+
+// xhtmlContent parses rendered HTML, which shortcodes, diagrams and embeds may leave with void
+// tags, bare ampersands and named entities, and writes it again as well-formed XHTML
+func xhtmlContent(content string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+
+	if err != nil {
+		return xhtmlTextEscaper.Replace(content)
+	}
+
+	var builder strings.Builder
+
+	for _, node := range nodes {
+		writeXHTML(&builder, node)
+	}
+
+	return builder.String()
+}
+
+// isXMLName tells whether an attribute name can be written in XML
+func isXMLName(name string) bool {
+	for index, character := range name {
+		letter := character == '_' || character == ':' || (character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z')
+
+		if !letter && (index == 0 || (character != '-' && character != '.' && (character < '0' || character > '9'))) {
+			return false
+		}
+	}
+
+	return name != ""
+}
+
+// writeXHTML writes a parsed node and its children as XHTML, leaving out comments and doctypes
+func writeXHTML(builder *strings.Builder, node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		builder.WriteString(xhtmlTextEscaper.Replace(node.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	builder.WriteString("<" + node.Data)
+
+	// foreign elements declare their namespace where they start
+	if namespace, found := xhtmlNamespaces[node.Namespace]; found && (node.Parent == nil || node.Parent.Namespace != node.Namespace) {
+		builder.WriteString(` xmlns="` + namespace + `"`)
+
+		if node.Namespace == "svg" {
+			builder.WriteString(` xmlns:xlink="http://www.w3.org/1999/xlink"`)
+		}
+	}
+
+	for _, attribute := range node.Attr {
+		name := attribute.Key
+
+		if attribute.Namespace != "" {
+			name = attribute.Namespace + ":" + name
+		}
+
+		if !isXMLName(name) || strings.HasPrefix(name, "xmlns") {
+			continue
+		}
+
+		builder.WriteString(" " + name + `="` + xhtmlAttributeEscaper.Replace(attribute.Val) + `"`)
+	}
+
+	if node.FirstChild == nil && (voidElements[node.Data] || node.Namespace != "") {
+		builder.WriteString("/>")
+		return
+	}
+
+	builder.WriteString(">")
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		writeXHTML(builder, child)
+	}
+
+	builder.WriteString("</" + node.Data + ">")
+}